@@ -19,10 +19,12 @@ package cli
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/chzyer/readline"
 	"github.com/mysteriumnetwork/node/cmd"
@@ -66,6 +68,9 @@ const redColor = "\033[31m%s\033[0m"
 const identityDefaultPassphrase = ""
 const statusConnected = "Connected"
 
+// mystUnits is amount of smallest MYST units in a single MYST
+const mystUnits = 100000000
+
 var versionSummary = metadata.VersionAsSummary(metadata.LicenseCopyright(
 	"type 'license warranty'",
 	"type 'license conditions'",
@@ -133,11 +138,15 @@ func (c *cliApp) handleActions(line string) {
 	}{
 		{command: "connect", handler: c.connect},
 		{command: "unlock", handler: c.unlock},
+		{command: "lock", handler: c.lock},
 		{command: "identities", handler: c.identities},
 		{command: "version", handler: c.version},
 		{command: "license", handler: c.license},
 		{command: "registration", handler: c.registration},
+		{command: "balance", handler: c.balance},
 		{command: "proposals", handler: c.proposals},
+		{command: "favorites", handler: c.providerList("favorites", "favorite")},
+		{command: "blocked", handler: c.providerList("blocked", "blocked")},
 	}
 
 	for _, cmd := range staticCmds {
@@ -205,7 +214,7 @@ func (c *cliApp) connect(argsString string) {
 }
 
 func (c *cliApp) unlock(argsString string) {
-	unlockSignature := "Unlock <identity> [passphrase]"
+	unlockSignature := "Unlock <identity> [passphrase] [timeout]"
 	if len(argsString) == 0 {
 		info("Press tab to select identity.", unlockSignature)
 		return
@@ -213,18 +222,26 @@ func (c *cliApp) unlock(argsString string) {
 
 	args := strings.Fields(argsString)
 	var identity, passphrase string
+	var timeout time.Duration
 
 	if len(args) == 1 {
 		identity, passphrase = args[0], ""
 	} else if len(args) == 2 {
 		identity, passphrase = args[0], args[1]
+	} else if len(args) == 3 {
+		var err error
+		identity, passphrase = args[0], args[1]
+		if timeout, err = time.ParseDuration(args[2]); err != nil {
+			warn("Invalid timeout, expected duration like 30m:", err)
+			return
+		}
 	} else {
-		info("Please type in identity and optional passphrase.", unlockSignature)
+		info("Please type in identity, optional passphrase and timeout.", unlockSignature)
 		return
 	}
 
 	info("Unlocking", identity)
-	err := c.tequilapi.Unlock(identity, passphrase)
+	err := c.tequilapi.UnlockWithTimeout(identity, passphrase, timeout)
 	if err != nil {
 		warn(err)
 		return
@@ -233,6 +250,22 @@ func (c *cliApp) unlock(argsString string) {
 	success(fmt.Sprintf("Identity %s unlocked.", identity))
 }
 
+func (c *cliApp) lock(argsString string) {
+	lockSignature := "Lock <identity>"
+	args := strings.Fields(argsString)
+	if len(args) != 1 {
+		info("Please type in identity.", lockSignature)
+		return
+	}
+
+	if err := c.tequilapi.Lock(args[0]); err != nil {
+		warn(err)
+		return
+	}
+
+	success(fmt.Sprintf("Identity %s locked.", args[0]))
+}
+
 func (c *cliApp) disconnect() {
 	err := c.tequilapi.Disconnect()
 	if err != nil {
@@ -262,6 +295,25 @@ func (c *cliApp) status() {
 			info("Bytes sent:", statistics.BytesSent)
 			info("Bytes received:", statistics.BytesReceived)
 		}
+		c.printLeakCheck(status.LeakCheck)
+	}
+}
+
+func (c *cliApp) printLeakCheck(leakCheck *tequilapi_client.LeakCheckDTO) {
+	switch {
+	case leakCheck == nil:
+		info("Leak check: in progress")
+	case leakCheck.Error != "":
+		warn("Leak check failed:", leakCheck.Error)
+	case leakCheck.IPLeak:
+		warn("Traffic is leaking! Exit IP is the same as original:", leakCheck.ExitIP)
+	case leakCheck.DNSLeak:
+		warn("DNS queries are leaking outside of connection!")
+	default:
+		info("Exit IP:", leakCheck.ExitIP, leakCheck.ExitCountry)
+	}
+	if leakCheck != nil && leakCheck.CountryMismatch {
+		warn("Exit country", leakCheck.ExitCountry, "differs from advertised", leakCheck.ExpectedCountry)
 	}
 }
 
@@ -277,17 +329,40 @@ func (c *cliApp) healthcheck() {
 	info(fmt.Sprintf("Version: %v", healthcheck.Version))
 	buildString := metadata.FormatString(healthcheck.BuildInfo.Commit, healthcheck.BuildInfo.Branch, healthcheck.BuildInfo.BuildNumber)
 	info(buildString)
+	for _, broker := range healthcheck.Brokers {
+		info(fmt.Sprintf("Broker: %v %v %v", broker.Topic, broker.State, broker.ConnectedServer))
+	}
 }
 
-func (c *cliApp) proposals(filter string) {
-	proposals := c.fetchProposals()
-	c.fetchedProposals = proposals
+const proposalsUsage = "proposals [filter] [country=<code>] [city=<name>] [asn=<asn>] [service=<type>] " +
+	"[payment=<type>] [max-price=<amount>] [min-quality=<0..1>] [sort=price|quality|localScore|country] [page=<n>] [limit=<n>]"
+
+func (c *cliApp) proposals(argsString string) {
+	query, filter, err := parseProposalsArgs(argsString)
+	if err != nil {
+		warn(err)
+		info(proposalsUsage)
+		return
+	}
+
+	list, err := c.tequilapi.ProposalsByQuery(query)
+	if err != nil {
+		warn(err)
+		return
+	}
+	proposals := list.Proposals
+	if query == (tequilapi_client.ProposalsQuery{}) {
+		c.fetchedProposals = proposals
+	}
 
 	filterMsg := ""
 	if filter != "" {
 		filterMsg = fmt.Sprintf("(filter: '%s')", filter)
 	}
 	info(fmt.Sprintf("Found %v proposals %s", len(proposals), filterMsg))
+	if list.Limit > 0 {
+		info(fmt.Sprintf("Page %v, %v per page, %v proposals in total", list.Page, list.Limit, list.Total))
+	}
 
 	for _, proposal := range proposals {
 		country := proposal.ServiceDefinition.LocationOriginate.Country
@@ -295,7 +370,13 @@ func (c *cliApp) proposals(filter string) {
 			country = "Unknown"
 		}
 
-		msg := fmt.Sprintf("- provider id: %v, proposal id: %v, country: %v", proposal.ProviderID, proposal.ID, country)
+		msg := fmt.Sprintf("- provider id: %v, proposal id: %v, service: %v, country: %v", proposal.ProviderID, proposal.ID, proposal.ServiceType, country)
+		if city := proposal.ServiceDefinition.LocationOriginate.City; city != "" {
+			msg += fmt.Sprintf(", city: %v", city)
+		}
+		if proposal.PaymentMethod != nil {
+			msg += fmt.Sprintf(", price: %v%v", proposal.PaymentMethod.Price.Amount, proposal.PaymentMethod.Price.Currency)
+		}
 
 		if filter == "" ||
 			strings.Contains(proposal.ProviderID, filter) ||
@@ -306,6 +387,58 @@ func (c *cliApp) proposals(filter string) {
 	}
 }
 
+// parseProposalsArgs splits proposals command arguments to server side query options given as key=value
+// and a free text filter applied locally
+func parseProposalsArgs(argsString string) (query tequilapi_client.ProposalsQuery, filter string, err error) {
+	var filters []string
+	for _, arg := range strings.Fields(argsString) {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			filters = append(filters, arg)
+			continue
+		}
+
+		key, value := parts[0], parts[1]
+		switch key {
+		case "country":
+			query.Country = value
+		case "city":
+			query.City = value
+		case "asn":
+			query.ASN = value
+		case "service":
+			query.ServiceType = value
+		case "payment":
+			query.PaymentMethod = value
+		case "sort":
+			query.Sort = value
+		case "max-price":
+			maxPrice, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return query, "", fmt.Errorf("invalid max-price: %v", value)
+			}
+			query.MaxPrice = &maxPrice
+		case "min-quality":
+			minQuality, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return query, "", fmt.Errorf("invalid min-quality: %v", value)
+			}
+			query.MinQuality = &minQuality
+		case "page":
+			if query.Page, err = strconv.Atoi(value); err != nil {
+				return query, "", fmt.Errorf("invalid page: %v", value)
+			}
+		case "limit":
+			if query.Limit, err = strconv.Atoi(value); err != nil {
+				return query, "", fmt.Errorf("invalid limit: %v", value)
+			}
+		default:
+			return query, "", fmt.Errorf("unknown proposals option: %v", key)
+		}
+	}
+	return query, strings.Join(filters, " "), nil
+}
+
 func (c *cliApp) fetchProposals() []tequilapi_client.ProposalDTO {
 	proposals, err := c.tequilapi.Proposals()
 	if err != nil {
@@ -337,28 +470,17 @@ func (c *cliApp) quit() {
 }
 
 func (c *cliApp) identities(argsString string) {
-	const usage = "identities command:\n    list\n    new [passphrase]"
-	if len(argsString) == 0 {
-		info(usage)
-		return
-	}
-
-	switch argsString {
-	case "new", "list": // Known sub-commands.
-	default:
-		warnf("Unknown sub-command '%s'\n", argsString)
-		fmt.Println(usage)
-		return
-	}
-
+	const usage = "identities command:\n    list\n    new [passphrase]\n" +
+		"    export <identity> <passphrase> <new-passphrase> <file>\n    import <file> <passphrase> [new-passphrase]\n" +
+		"    passphrase <identity> <old-passphrase> <new-passphrase>\n    delete <identity> <passphrase>"
 	args := strings.Fields(argsString)
 	if len(args) < 1 {
 		info(usage)
 		return
 	}
 
-	action := args[0]
-	if action == "list" {
+	switch action := args[0]; action {
+	case "list":
 		if len(args) > 1 {
 			info(usage)
 			return
@@ -372,10 +494,7 @@ func (c *cliApp) identities(argsString string) {
 		for _, id := range ids {
 			status("+", id.Address)
 		}
-		return
-	}
-
-	if action == "new" {
+	case "new":
 		var passphrase string
 		if len(args) == 1 {
 			passphrase = identityDefaultPassphrase
@@ -392,15 +511,129 @@ func (c *cliApp) identities(argsString string) {
 			return
 		}
 		success("New identity created:", id.Address)
+	case "export":
+		if len(args) != 5 {
+			info(usage)
+			return
+		}
+		keystore, err := c.tequilapi.ExportIdentity(args[1], args[2], args[3])
+		if err != nil {
+			warn(err)
+			return
+		}
+		if err := ioutil.WriteFile(args[4], keystore, 0600); err != nil {
+			warn(err)
+			return
+		}
+		success("Identity exported to:", args[4])
+	case "import":
+		if len(args) < 3 || len(args) > 4 {
+			info(usage)
+			return
+		}
+		keystore, err := ioutil.ReadFile(args[1])
+		if err != nil {
+			warn(err)
+			return
+		}
+		newPassphrase := args[2]
+		if len(args) == 4 {
+			newPassphrase = args[3]
+		}
+		id, err := c.tequilapi.ImportIdentity(keystore, args[2], newPassphrase)
+		if err != nil {
+			warn(err)
+			return
+		}
+		success("Identity imported:", id.Address)
+	case "passphrase":
+		if len(args) != 4 {
+			info(usage)
+			return
+		}
+		if err := c.tequilapi.ChangePassphrase(args[1], args[2], args[3]); err != nil {
+			warn(err)
+			return
+		}
+		success("Passphrase changed:", args[1])
+	case "delete":
+		if len(args) != 3 {
+			info(usage)
+			return
+		}
+		if err := c.tequilapi.DeleteIdentity(args[1], args[2]); err != nil {
+			warn(err)
+			return
+		}
+		success("Identity deleted:", args[1])
+	default:
+		warnf("Unknown sub-command '%s'\n", action)
+		fmt.Println(usage)
+	}
+}
+
+// providerList returns handler of command managing favorite or blocked providers
+func (c *cliApp) providerList(command, list string) func(argsString string) {
+	usage := command + " command:\n    list\n    add <provider-identity>\n    remove <provider-identity>"
+
+	return func(argsString string) {
+		args := strings.Fields(argsString)
+		if len(args) == 0 {
+			info(usage)
+			return
+		}
+
+		switch {
+		case args[0] == "list" && len(args) == 1:
+			providers, err := c.tequilapi.ProviderPreferences(list)
+			if err != nil {
+				warn(err)
+				return
+			}
+			for _, provider := range providers {
+				status("+", provider.ProviderID, "added at", provider.AddedAt)
+			}
+		case args[0] == "add" && len(args) == 2:
+			if err := c.tequilapi.AddProviderPreference(list, args[1]); err != nil {
+				warn(err)
+				return
+			}
+			success("Provider added to", command, args[1])
+		case args[0] == "remove" && len(args) == 2:
+			if err := c.tequilapi.RemoveProviderPreference(list, args[1]); err != nil {
+				warn(err)
+				return
+			}
+			success("Provider removed from", command, args[1])
+		default:
+			warnf("Unknown sub-command '%s'\n", argsString)
+			info(usage)
+		}
 	}
 }
 
 func (c *cliApp) registration(argsString string) {
-	if argsString == "" {
-		warn("Please supply identity")
+	const usage = "registration <identity> [submit]"
+	args := strings.Fields(argsString)
+	if len(args) < 1 || len(args) > 2 {
+		info(usage)
+		return
+	}
+	if len(args) == 2 {
+		if args[1] != "submit" {
+			info(usage)
+			return
+		}
+		progress, err := c.tequilapi.RegisterIdentity(args[0])
+		if err != nil {
+			warn("Failed to submit registration: ", err)
+			return
+		}
+		success("Registration submitted in transaction", progress.TxHash, "paid by", progress.Payer)
 		return
 	}
-	status, err := c.tequilapi.IdentityRegistrationStatus(argsString)
+
+	status, err := c.tequilapi.IdentityRegistrationStatus(args[0])
 	if err != nil {
 		warn("Something went wrong: ", err)
 		return
@@ -409,6 +642,15 @@ func (c *cliApp) registration(argsString string) {
 		info("Already registered")
 		return
 	}
+	if status.Progress != nil {
+		info("Registration transaction", status.Progress.TxHash, "is", status.Progress.Status)
+		if status.Progress.Error != "" {
+			warn("Registration failed: ", status.Progress.Error)
+		}
+		if status.Progress.Status != "failed" {
+			return
+		}
+	}
 	info("Identity is not registered yet. In order to do that - please call payments contract with the following data")
 	info("Public key: part1 ->", status.PublicKey.Part1)
 	info("            part2 ->", status.PublicKey.Part2)
@@ -424,6 +666,22 @@ func (c *cliApp) registration(argsString string) {
 		status.Signature.V)
 }
 
+func (c *cliApp) balance(argsString string) {
+	const usage = "balance <identity>"
+	args := strings.Fields(argsString)
+	if len(args) != 1 {
+		info(usage)
+		return
+	}
+
+	balance, err := c.tequilapi.IdentityBalance(args[0])
+	if err != nil {
+		warn("Failed to get balance: ", err)
+		return
+	}
+	infof("Balance: %d.%08d %s (updated at %s)\n", balance.Amount/mystUnits, balance.Amount%mystUnits, balance.Currency, balance.UpdatedAt)
+}
+
 func (c *cliApp) stopClient() {
 	err := c.tequilapi.Stop()
 	if err != nil {
@@ -487,10 +745,26 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []tequilapi_
 			"identities",
 			readline.PcItem("new"),
 			readline.PcItem("list"),
+			readline.PcItem("export", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
+			readline.PcItem("import"),
+			readline.PcItem("passphrase", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
+			readline.PcItem("delete", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
 		),
 		readline.PcItem("status"),
 		readline.PcItem("healthcheck"),
 		readline.PcItem("proposals"),
+		readline.PcItem(
+			"favorites",
+			readline.PcItem("list"),
+			readline.PcItem("add", readline.PcItemDynamic(getProposalOptionList(proposals))),
+			readline.PcItem("remove"),
+		),
+		readline.PcItem(
+			"blocked",
+			readline.PcItem("list"),
+			readline.PcItem("add", readline.PcItemDynamic(getProposalOptionList(proposals))),
+			readline.PcItem("remove"),
+		),
 		readline.PcItem("ip"),
 		readline.PcItem("disconnect"),
 		readline.PcItem("help"),
@@ -502,15 +776,28 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []tequilapi_
 				getIdentityOptionList(tequilapi),
 			),
 		),
+		readline.PcItem(
+			"lock",
+			readline.PcItemDynamic(
+				getIdentityOptionList(tequilapi),
+			),
+		),
 		readline.PcItem(
 			"license",
 			readline.PcItem("warranty"),
 			readline.PcItem("conditions"),
 		),
+		readline.PcItem(
+			"balance",
+			readline.PcItemDynamic(
+				getIdentityOptionList(tequilapi),
+			),
+		),
 		readline.PcItem(
 			"registration",
 			readline.PcItemDynamic(
 				getIdentityOptionList(tequilapi),
+				readline.PcItem("submit"),
 			),
 		),
 	)
@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/cmd"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/urfave/cli"
+)
+
+var errCheckFailed = errors.New("database check failed")
+
+// NewCommand function creates storage command
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "storage",
+		Usage: "Backup, restore and check node database",
+		Subcommands: []cli.Command{
+			{
+				Name:      "backup",
+				Usage:     "Writes a consistent snapshot of node database to the given file, taken by the node if it's running",
+				ArgsUsage: "<file>",
+				Action:    backupAction,
+			},
+			{
+				Name:      "restore",
+				Usage:     "Replaces node database with the given backup, node has to be stopped",
+				ArgsUsage: "<file>",
+				Action:    restoreAction,
+			},
+			{
+				Name:      "check",
+				Usage:     "Verifies contents of node database or the given backup",
+				ArgsUsage: "[file]",
+				Action:    checkAction,
+			},
+		},
+	}
+}
+
+func backupAction(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("backup file is required")
+	}
+	path := ctx.Args().First()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	err = backup(cmd.ParseFlagsNode(ctx), file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	_, err = fmt.Fprintln(ctx.App.Writer, "Database backed up to", path)
+	return err
+}
+
+func restoreAction(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("backup file is required")
+	}
+
+	options := cmd.ParseFlagsNode(ctx)
+	if err := os.MkdirAll(options.Directories.Storage, 0700); err != nil {
+		return err
+	}
+
+	report, err := boltdb.Restore(boltdb.DatabaseFile(options.Directories.Storage), ctx.Args().First(), history.Schemas, history.Sequence)
+	if err == boltdb.ErrDatabaseInUse {
+		return errors.New("database is in use, stop the node before restoring it")
+	}
+	printReport(ctx.App.Writer, report)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(ctx.App.Writer, "Database restored from", ctx.Args().First())
+	return err
+}
+
+func checkAction(ctx *cli.Context) error {
+	options := cmd.ParseFlagsNode(ctx)
+	path := ctx.Args().First()
+	if path == "" {
+		path = boltdb.DatabaseFile(options.Directories.Storage)
+	}
+
+	report, err := boltdb.CheckFile(path, history.Schemas, history.Sequence)
+	if err == boltdb.ErrDatabaseInUse {
+		report, err = checkSnapshot(options)
+	}
+	if err != nil {
+		return err
+	}
+	printReport(ctx.App.Writer, report)
+	if !report.Healthy() {
+		return errCheckFailed
+	}
+	return nil
+}
+
+// backup writes database snapshot, which is requested from the running node when database is in use
+func backup(options node.Options, w io.Writer) error {
+	_, err := boltdb.BackupFile(boltdb.DatabaseFile(options.Directories.Storage), w)
+	if err == boltdb.ErrDatabaseInUse {
+		return tequilapi_client.NewClient(options.TequilapiAddress, options.TequilapiPort).StorageBackup(w)
+	}
+	return err
+}
+
+// checkSnapshot checks snapshot of the database used by the running node
+func checkSnapshot(options node.Options) (boltdb.CheckReport, error) {
+	file, err := ioutil.TempFile("", "myst-check-")
+	if err != nil {
+		return boltdb.CheckReport{}, err
+	}
+	defer os.Remove(file.Name())
+
+	err = backup(options, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return boltdb.CheckReport{}, err
+	}
+	return boltdb.CheckFile(file.Name(), history.Schemas, history.Sequence)
+}
+
+func printReport(w io.Writer, report boltdb.CheckReport) {
+	for _, bucket := range report.Buckets {
+		fmt.Fprintf(w, "%s: %d records\n", bucket.Name, bucket.Records)
+		for _, problem := range bucket.Problems {
+			fmt.Fprintln(w, "  problem:", problem)
+		}
+	}
+	if len(report.UnknownBuckets) > 0 {
+		fmt.Fprintln(w, "Unknown buckets:", strings.Join(report.UnknownBuckets, ", "))
+	}
+	if len(report.UnknownMigrations) > 0 {
+		fmt.Fprintln(w, "Migrations unknown to this version:", strings.Join(report.UnknownMigrations, ", "))
+	}
+}
@@ -18,18 +18,29 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/asaskevich/EventBus"
 	log "github.com/cihub/seelog"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/blockchain"
 	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/communication/nats"
 	nats_dialog "github.com/mysteriumnetwork/node/communication/nats/dialog"
 	nats_discovery "github.com/mysteriumnetwork/node/communication/nats/discovery"
+	"github.com/mysteriumnetwork/node/consumer/leak"
+	"github.com/mysteriumnetwork/node/consumer/preferences"
+	"github.com/mysteriumnetwork/node/consumer/quality"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/consumer/statistics"
 	"github.com/mysteriumnetwork/node/core/connection"
@@ -37,15 +48,20 @@ import (
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
 	"github.com/mysteriumnetwork/node/identity"
+	identity_balance "github.com/mysteriumnetwork/node/identity/balance"
 	identity_registry "github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/logconfig"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/market/metrics"
 	"github.com/mysteriumnetwork/node/market/metrics/oracle"
 	"github.com/mysteriumnetwork/node/market/mysterium"
+	proposals_broker "github.com/mysteriumnetwork/node/market/proposals/broker"
+	proposals_registry "github.com/mysteriumnetwork/node/market/proposals/registry"
+	proposals_repository "github.com/mysteriumnetwork/node/market/proposals/repository"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/nat"
@@ -63,23 +79,44 @@ import (
 	"github.com/mysteriumnetwork/node/tequilapi"
 	tequilapi_endpoints "github.com/mysteriumnetwork/node/tequilapi/endpoints"
 	"github.com/mysteriumnetwork/node/utils"
+	"github.com/mysteriumnetwork/payments/cli/helpers"
+)
+
+const (
+	// locationDatabaseCheckInterval defines how often external location databases are checked for updates
+	locationDatabaseCheckInterval = time.Minute
+	// ipSourceTimeout limits how long a single public IP source is waited for
+	ipSourceTimeout = 30 * time.Second
+	// publicIPCacheTTL defines how long resolved public IP is reused
+	publicIPCacheTTL = 5 * time.Minute
+	// balanceCacheTTL defines how long identity balance read from payments contract is reused
+	balanceCacheTTL = time.Minute
 )
 
 // Storage stores persistent objects for future usage
 type Storage interface {
-	Store(issuer string, data interface{}) error
-	Delete(issuer string, data interface{}) error
-	Update(bucket string, object interface{}) error
-	GetAllFrom(bucket string, data interface{}) error
+	storage.Tx
+	storage.Transactor
+	Backup(w io.Writer) (int64, error)
 	Close() error
 }
 
+// identityLocker locks unlocked identities in background until stopped
+type identityLocker interface {
+	Stop()
+}
+
 // Dependencies is DI container for top level components which is reusedin several places
 type Dependencies struct {
 	Node *node.Node
 
 	NetworkDefinition    metadata.NetworkDefinition
+	BrokerAddressTracker *nats_discovery.AddressTracker
 	MysteriumAPI         *mysterium.MysteriumAPI
+	ProposalRegistry     proposals_registry.ProposalRegistry
+	ProposalFinder       proposals_repository.Finder
+	ProposalRepository   *proposals_repository.Repository
+	DiscoveryBroker      *nats_discovery.AddressNATS
 	MysteriumMorqaClient metrics.QualityOracle
 	EtherClient          *ethclient.Client
 
@@ -87,18 +124,24 @@ type Dependencies struct {
 	Storage              Storage
 	Keystore             *keystore.KeyStore
 	IdentityManager      identity.Manager
+	IdentityCache        identity.IdentityCacheInterface
 	SignerFactory        identity.SignerFactory
 	IdentityRegistry     identity_registry.IdentityRegistry
 	IdentityRegistration identity_registry.RegistrationDataProvider
+	IdentityRegistrator  identity_registry.IdentityRegistrator
+	IdentityBalances     *identity_balance.Service
 
 	IPResolver       ip.Resolver
 	LocationResolver location.Resolver
 	LocationDetector location.Detector
 	LocationOriginal location.Cache
 
-	StatisticsTracker  *statistics.SessionStatisticsTracker
-	StatisticsReporter *statistics.SessionStatisticsReporter
-	SessionStorage     *consumer_session.Storage
+	StatisticsTracker   *statistics.SessionStatisticsTracker
+	StatisticsReporter  *statistics.SessionStatisticsReporter
+	SessionStorage      *consumer_session.Storage
+	ProviderQuality     *quality.Tracker
+	LeakChecker         *leak.Checker
+	ProviderPreferences *preferences.Store
 
 	EventBus EventBus.Bus
 
@@ -125,19 +168,29 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
+	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
+		return err
+	}
+
 	if err := di.bootstrapNetworkComponents(nodeOptions.OptionsNetwork); err != nil {
 		return err
 	}
 
-	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
+	if err := di.bootstrapDiscoveryComponents(nodeOptions.OptionsNetwork); err != nil {
 		return err
 	}
 
 	di.bootstrapIdentityComponents(nodeOptions)
-	di.bootstrapLocationComponents(nodeOptions.Location, nodeOptions.Directories.Config)
+	if err := di.bootstrapIdentityRegistrator(nodeOptions.OptionsNetwork); err != nil {
+		return err
+	}
+	if err := di.bootstrapLocationComponents(nodeOptions.Location, nodeOptions.Directories.Config); err != nil {
+		return err
+	}
 	di.bootstrapNodeComponents(nodeOptions)
 
 	di.registerConnections(nodeOptions)
+	di.ProposalRepository.Start()
 
 	err := di.subscribeEventConsumers()
 	if err != nil {
@@ -196,6 +249,18 @@ func (di *Dependencies) Shutdown() (err error) {
 			errs = append(errs, err)
 		}
 	}
+	if di.ProposalRepository != nil {
+		di.ProposalRepository.Stop()
+	}
+	if di.DiscoveryBroker != nil {
+		di.DiscoveryBroker.Disconnect()
+	}
+	if resolver, ok := di.LocationResolver.(*location.ReloadingResolver); ok {
+		resolver.Stop()
+	}
+	if locker, ok := di.IdentityManager.(identityLocker); ok {
+		locker.Stop()
+	}
 	if di.Storage != nil {
 		if err := di.Storage.Close(); err != nil {
 			errs = append(errs, err)
@@ -237,21 +302,53 @@ func (di *Dependencies) subscribeEventConsumers() error {
 		return err
 	}
 
+	// public IP changes when connection state changes
+	if resolver, ok := di.IPResolver.(*ip.ConsensusResolver); ok {
+		err = di.EventBus.Subscribe(connection.StateEventTopic, func(connection.StateEvent) {
+			resolver.Invalidate()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// leak check of established connection
+	err = di.EventBus.Subscribe(connection.SessionEventTopic, di.LeakChecker.ConsumeSessionEvent)
+	if err != nil {
+		return err
+	}
+	err = di.EventBus.Subscribe(connection.StateEventTopic, di.LeakChecker.ConsumeStateEvent)
+	if err != nil {
+		return err
+	}
+
 	// statistics events
 	err = di.EventBus.Subscribe(connection.StatisticsEventTopic, di.StatisticsTracker.ConsumeStatisticsEvent)
 	if err != nil {
 		return err
 	}
 
+	// provider quality events
+	err = di.EventBus.Subscribe(connection.AttemptEventTopic, di.ProviderQuality.ConsumeAttemptEvent)
+	if err != nil {
+		return err
+	}
+	err = di.EventBus.Subscribe(connection.DisconnectEventTopic, di.ProviderQuality.ConsumeDisconnectEvent)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options) {
 	dialogFactory := func(consumerID, providerID identity.Identity, contact market.Contact) (communication.Dialog, error) {
-		dialogEstablisher := nats_dialog.NewDialogEstablisher(consumerID, di.SignerFactory(consumerID))
+		dialogEstablisher := nats_dialog.NewDialogEstablisher(consumerID, di.SignerFactory(consumerID), newProtocolInfo(nodeOptions))
 		return dialogEstablisher.EstablishDialog(providerID, contact)
 	}
 
+	di.ProposalRepository = proposals_repository.NewRepository(di.ProposalFinder, di.Storage, time.Minute)
+
 	di.StatisticsTracker = statistics.NewSessionStatisticsTracker(time.Now)
 	di.StatisticsReporter = statistics.NewSessionStatisticsReporter(
 		di.StatisticsTracker,
@@ -261,9 +358,17 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options) {
 		time.Minute,
 	)
 	di.SessionStorage = consumer_session.NewSessionStorage(di.Storage, di.StatisticsTracker)
+	di.ProviderQuality = quality.NewTracker(di.Storage, di.SessionStorage)
+	di.ProviderPreferences = preferences.NewStore(di.Storage)
 
 	di.EventBus = EventBus.New()
 
+	var dnsDetector leak.DNSDetector
+	if nodeOptions.Location.LeakCheckDNS {
+		dnsDetector = leak.NewDNSDetector(ipSourceTimeout)
+	}
+	di.LeakChecker = leak.NewChecker(di.LocationDetector, di.LocationOriginal, dnsDetector, di.EventBus)
+
 	di.ConnectionRegistry = connection.NewRegistry()
 	di.ConnectionManager = connection.NewManager(
 		dialogFactory,
@@ -272,29 +377,112 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options) {
 		di.EventBus,
 	)
 
-	router := tequilapi.NewAPIRouter()
+	httpAPIServer := tequilapi.NewServer(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort, di.tequilapiRouter())
+
+	di.Node = node.NewNode(di.ConnectionManager, httpAPIServer, di.LocationOriginal)
+}
+
+// tequilapiRouter creates router with all tequilapi endpoints of the node
+func (di *Dependencies) tequilapiRouter() *httprouter.Router {
+	router := tequilapi.NewAPIRouter(di.BrokerAddressTracker)
 	tequilapi_endpoints.AddRouteForStop(router, utils.SoftKiller(di.Shutdown))
-	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.SignerFactory)
-	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.IPResolver, di.StatisticsTracker, di.MysteriumAPI)
+	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.SignerFactory, di.IdentityCache)
+	tequilapi_endpoints.AddRoutesForSignatures(router, di.IdentityManager, di.SignerFactory, identity.NewExtractor())
+	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.IPResolver, di.StatisticsTracker, di.ProposalRepository, di.ProviderPreferences, di.LeakChecker)
 	tequilapi_endpoints.AddRoutesForLocation(router, di.ConnectionManager, di.LocationDetector, di.LocationOriginal)
-	tequilapi_endpoints.AddRoutesForProposals(router, di.MysteriumAPI, di.MysteriumMorqaClient)
+	tequilapi_endpoints.AddRoutesForLocationDatabases(router, di.LocationResolver)
+	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.MysteriumMorqaClient, di.ProviderQuality, di.ProviderPreferences)
+	tequilapi_endpoints.AddRoutesForProviderPreferences(router, di.ProviderPreferences)
 	tequilapi_endpoints.AddRoutesForSession(router, di.SessionStorage)
-	identity_registry.AddIdentityRegistrationEndpoint(router, di.IdentityRegistration, di.IdentityRegistry)
+	tequilapi_endpoints.AddRoutesForDiscovery(router, di.discoveryStates)
+	tequilapi_endpoints.AddRoutesForDialogs(router, di.dialogStats)
+	tequilapi_endpoints.AddRoutesForServiceProposals(router, &serviceProposals{di})
+	tequilapi_endpoints.AddRoutesForBalance(router, di.IdentityBalances)
+	tequilapi_endpoints.AddRoutesForStorage(router, di.Storage)
+	identity_registry.AddIdentityRegistrationEndpoint(router, di.IdentityRegistration, di.IdentityRegistry, di.IdentityRegistrator)
+	return router
+}
 
-	httpAPIServer := tequilapi.NewServer(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort, router)
+// discoveryStates is resolved lazily, because service runner is created after tequilapi routes
+func (di *Dependencies) discoveryStates() map[string][]proposals_registry.State {
+	if di.ServiceRunner == nil {
+		return nil
+	}
+	return di.ServiceRunner.DiscoveryStates()
+}
 
-	di.Node = node.NewNode(di.ConnectionManager, httpAPIServer, di.LocationOriginal)
+// dialogStats is resolved lazily, because service runner is created after tequilapi routes
+func (di *Dependencies) dialogStats() map[string]communication.DialogWaiterStats {
+	if di.ServiceRunner == nil {
+		return nil
+	}
+	return di.ServiceRunner.DialogStats()
+}
+
+// serviceProposals resolves service runner lazily, because it is created after tequilapi routes
+type serviceProposals struct {
+	di *Dependencies
+}
+
+func (sp *serviceProposals) runner() (*service.Runner, error) {
+	if sp.di.ServiceRunner == nil {
+		return nil, service.ErrUnsupportedServiceType
+	}
+	return sp.di.ServiceRunner, nil
+}
+
+func (sp *serviceProposals) Proposals(serviceType string) ([]market.ServiceProposal, error) {
+	runner, err := sp.runner()
+	if err != nil {
+		return nil, err
+	}
+	return runner.Proposals(serviceType)
+}
+
+func (sp *serviceProposals) PublishProposal(serviceType string, proposal market.ServiceProposal) (int, error) {
+	runner, err := sp.runner()
+	if err != nil {
+		return 0, err
+	}
+	return runner.PublishProposal(serviceType, proposal)
+}
+
+func (sp *serviceProposals) UpdateProposal(serviceType string, proposal market.ServiceProposal) error {
+	runner, err := sp.runner()
+	if err != nil {
+		return err
+	}
+	return runner.UpdateProposal(serviceType, proposal)
+}
+
+func (sp *serviceProposals) UnpublishProposal(serviceType string, proposalID int) error {
+	runner, err := sp.runner()
+	if err != nil {
+		return err
+	}
+	return runner.UnpublishProposal(serviceType, proposalID)
+}
+
+// identityInUse tells if identity is used by connection or running service
+func (di *Dependencies) identityInUse(id identity.Identity) bool {
+	if di.ConnectionManager != nil {
+		status := di.ConnectionManager.Status()
+		if status.State != connection.NotConnected && status.ConsumerID == id {
+			return true
+		}
+	}
+	return di.ServiceRunner != nil && di.ServiceRunner.UsesIdentity(id)
 }
 
 func newSessionManagerFactory(
-	proposal market.ServiceProposal,
+	proposalLookup session.ProposalLookup,
 	sessionStorage *session.StorageMemory,
 	nodeOptions node.Options,
 ) session.ManagerFactory {
 	return func(dialog communication.Dialog) *session.Manager {
 		providerBalanceTrackerFactory := func(consumer, provider, issuer identity.Identity) (session.BalanceTracker, error) {
-			// if the flag ain't set, just return a noop balance tracker
-			if !nodeOptions.ExperimentPayments {
+			// if the flag ain't set or consumer can't pay, just return a noop balance tracker
+			if !nodeOptions.ExperimentPayments || !dialog.Protocol().Supports(communication.CapabilityPayments) {
 				return payments_noop.NewSessionBalance(), nil
 			}
 
@@ -322,7 +510,7 @@ func newSessionManagerFactory(
 			return session_payment.NewSessionBalance(sender, tracker, promiseChan, time.Second*5, time.Second*1, validator), nil
 		}
 		return session.NewManager(
-			proposal,
+			proposalLookup,
 			session.GenerateUUID,
 			sessionStorage,
 			providerBalanceTrackerFactory,
@@ -330,6 +518,16 @@ func newSessionManagerFactory(
 	}
 }
 
+// newProtocolInfo announces session protocol capabilities supported by this node
+func newProtocolInfo(nodeOptions node.Options) communication.ProtocolInfo {
+	capabilities := []communication.Capability{communication.CapabilityCodecJSON}
+	if nodeOptions.ExperimentPayments {
+		capabilities = append(capabilities, communication.CapabilityPayments)
+	}
+
+	return communication.NewProtocolInfo(capabilities...)
+}
+
 // function decides on network definition combined from testnet/localnet flags and possible overrides
 func (di *Dependencies) bootstrapNetworkComponents(options node.OptionsNetwork) (err error) {
 	network := metadata.DefaultNetwork
@@ -346,8 +544,8 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.OptionsNetwork)
 		network.DiscoveryAPIAddress = options.DiscoveryAPIAddress
 	}
 
-	if options.BrokerAddress != metadata.DefaultNetwork.BrokerAddress {
-		network.BrokerAddress = options.BrokerAddress
+	if options.BrokerAddress != strings.Join(metadata.DefaultNetwork.BrokerAddresses, ",") {
+		network.BrokerAddresses = splitAddresses(options.BrokerAddress)
 	}
 
 	normalizedAddress := common.HexToAddress(options.EtherPaymentsAddress)
@@ -360,6 +558,7 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.OptionsNetwork)
 	}
 
 	di.NetworkDefinition = network
+	di.BrokerAddressTracker = nats_discovery.NewAddressTracker()
 	di.MysteriumAPI = mysterium.NewClient(network.DiscoveryAPIAddress)
 	di.MysteriumMorqaClient = oracle.NewMorqaClient(network.QualityOracle)
 
@@ -369,8 +568,11 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.OptionsNetwork)
 	}
 
 	log.Info("Using Eth contract at address: ", network.PaymentsContractAddress.String())
+	if di.IdentityBalances, err = identity_balance.NewService(di.EtherClient, network.PaymentsContractAddress, balanceCacheTTL); err != nil {
+		return err
+	}
 	if options.ExperimentIdentityCheck {
-		if di.IdentityRegistry, err = identity_registry.NewIdentityRegistryContract(di.EtherClient, network.PaymentsContractAddress); err != nil {
+		if di.IdentityRegistry, err = identity_registry.NewIdentityRegistryContract(di.EtherClient, network.PaymentsContractAddress, di.Storage); err != nil {
 			return err
 		}
 	} else {
@@ -380,27 +582,120 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.OptionsNetwork)
 	return nil
 }
 
+func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsNetwork) error {
+	switch options.DiscoveryType {
+	case node.DiscoveryTypeAPI, "":
+		di.ProposalRegistry = di.MysteriumAPI
+		di.ProposalFinder = di.MysteriumAPI
+	case node.DiscoveryTypeBroker:
+		address, err := nats_discovery.NewAddressForBrokers(di.NetworkDefinition.BrokerAddresses, proposals_broker.Topic)
+		if err != nil {
+			return err
+		}
+		if err := address.Connect(); err != nil {
+			return err
+		}
+		di.DiscoveryBroker = address
+		di.BrokerAddressTracker.Track(address)
+
+		codec := communication.NewCodecJSON()
+		finder := proposals_broker.NewFinder(nats.NewReceiver(address.GetConnection(), codec, address.GetTopic()), 3*time.Minute)
+		if err := finder.Start(); err != nil {
+			return err
+		}
+		di.ProposalRegistry = proposals_broker.NewRegistry(nats.NewSender(address.GetConnection(), codec, address.GetTopic()))
+		di.ProposalFinder = finder
+	default:
+		return fmt.Errorf("unknown discovery type: %s", options.DiscoveryType)
+	}
+
+	log.Info("Using proposal discovery: ", options.DiscoveryType)
+	return nil
+}
+
 func (di *Dependencies) bootstrapIdentityComponents(options node.Options) {
 	di.Keystore = identity.NewKeystoreFilesystem(options.Directories.Keystore, options.Keystore.UseLightweight)
-	di.IdentityManager = identity.NewIdentityManager(di.Keystore)
-	di.SignerFactory = func(id identity.Identity) identity.Signer {
-		return identity.NewSigner(di.Keystore, id)
-	}
+	identityManager := identity.NewIdentityManagerWithPolicy(di.Keystore, identity.UnlockPolicy{
+		IdleTimeout: options.Keystore.LockIdle,
+		InUse:       di.identityInUse,
+	})
+	identityManager.Start()
+	di.IdentityManager = identityManager
+	di.IdentityCache = identity.NewIdentityCache(options.Directories.Keystore, "remember.json")
+	di.SignerFactory = identityManager.NewSigner
 	di.IdentityRegistration = identity_registry.NewRegistrationDataProvider(di.Keystore)
 }
 
-func (di *Dependencies) bootstrapLocationComponents(options node.OptionsLocation, configDirectory string) {
-	di.IPResolver = ip.NewResolver(options.IpifyUrl)
+func (di *Dependencies) bootstrapIdentityRegistrator(options node.OptionsNetwork) error {
+	if !options.EtherRegistrationSubmit {
+		return nil
+	}
+	if !options.ExperimentIdentityCheck {
+		return errors.New("submitting identity registration requires identity check to be enabled")
+	}
+
+	var payer common.Address
+	if options.EtherRegistrationPayer != "" {
+		if !common.IsHexAddress(options.EtherRegistrationPayer) {
+			return fmt.Errorf("invalid registration payer address: %s", options.EtherRegistrationPayer)
+		}
+		payer = common.HexToAddress(options.EtherRegistrationPayer)
+		if err := di.Keystore.Unlock(accounts.Account{Address: payer}, options.EtherRegistrationPayerPassphrase); err != nil {
+			return fmt.Errorf("failed to unlock registration payer: %s", err)
+		}
+		log.Info("Identity registration is paid by: ", payer.Hex())
+	}
+
+	transactorFactory := func(payer common.Address) *bind.TransactOpts {
+		return helpers.CreateNewKeystoreTransactor(di.Keystore, &accounts.Account{Address: payer})
+	}
+
+	var err error
+	di.IdentityRegistrator, err = identity_registry.NewRegistrationTransactor(
+		di.EtherClient,
+		di.NetworkDefinition.PaymentsContractAddress,
+		di.IdentityRegistration,
+		transactorFactory,
+		payer,
+	)
+	return err
+}
+
+func (di *Dependencies) bootstrapLocationComponents(options node.OptionsLocation, configDirectory string) error {
+	sourceAddresses := splitAddresses(options.IpifyUrl + "," + options.IPSources)
+	sources, err := ip.NewSources(sourceAddresses, ipSourceTimeout)
+	if err != nil {
+		return err
+	}
+	di.IPResolver = ip.NewConsensusResolver(sources, publicIPCacheTTL)
 
 	switch {
 	case options.Country != "":
 		di.LocationResolver = location.NewStaticResolver(options.Country)
 	case options.ExternalDb != "":
-		di.LocationResolver = location.NewExternalDbResolver(filepath.Join(configDirectory, options.ExternalDb))
+		var databases []string
+		for _, database := range strings.Split(options.ExternalDb, ",") {
+			databases = append(databases, filepath.Join(configDirectory, strings.TrimSpace(database)))
+		}
+		resolver := location.NewReloadingResolver(locationDatabaseCheckInterval, databases...)
+		resolver.Start()
+		di.LocationResolver = resolver
 	default:
 		di.LocationResolver = location.NewBuiltInResolver()
 	}
 
 	di.LocationDetector = location.NewDetector(di.IPResolver, di.LocationResolver)
 	di.LocationOriginal = location.NewLocationCache(di.LocationDetector)
+	return nil
+}
+
+// splitAddresses parses comma separated list of addresses, skipping blank entries
+func splitAddresses(value string) []string {
+	var addresses []string
+	for _, address := range strings.Split(value, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
 }
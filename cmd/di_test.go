@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTequilapiRoutesDoNotConflict(t *testing.T) {
+	di := &Dependencies{}
+
+	var router *httprouter.Router
+	assert.NotPanics(t, func() {
+		router = di.tequilapiRouter()
+	})
+	if router == nil {
+		return
+	}
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/identities"},
+		{http.MethodPost, "/identities-import"},
+		{http.MethodPost, "/identities/0x1/export"},
+		{http.MethodGet, "/identities/0x1/registration"},
+		{http.MethodPost, "/identities/0x1/registration"},
+		{http.MethodPut, "/identities/0x1/unlock"},
+		{http.MethodDelete, "/identities/0x1"},
+	}
+	for _, route := range routes {
+		handle, _, _ := router.Lookup(route.method, route.path)
+		assert.NotNil(t, handle, "route not found: %s %s", route.method, route.path)
+	}
+}
+
+func TestSplitAddresses(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{"nats://broker1:4222", "broker2"},
+		splitAddresses(" nats://broker1:4222 ,,broker2, "),
+	)
+	assert.Nil(t, splitAddresses(" , "))
+}
@@ -28,11 +28,23 @@ var (
 		Usage: "Address (URL form) of ipify service",
 		Value: "https://api.ipify.org/",
 	}
+	ipSourcesFlag = cli.StringFlag{
+		Name: "ip-sources",
+		Usage: "Additional public IP detection sources, comma separated. HTTP(S) sources may answer in plain text or JSON, " +
+			"STUN sources are given as stun:host:port. Public IP is the answer of the majority of sources",
+		Value: "https://checkip.amazonaws.com/,https://ifconfig.co/ip,stun:stun.l.google.com:19302",
+	}
 	locationDatabaseFlag = cli.StringFlag{
-		Name:  "location.database",
-		Usage: "Service location autodetect database of GeoLite2 format e.g. http://dev.maxmind.com/geoip/geoip2/geolite2/",
+		Name: "location.database",
+		Usage: "Service location autodetect databases of GeoLite2 format e.g. http://dev.maxmind.com/geoip/geoip2/geolite2/. " +
+			"Country or City database is required, ASN database is optional, e.g. GeoLite2-City.mmdb,GeoLite2-ASN.mmdb. " +
+			"When not given, built-in Country database is used, so city and ASN are not detected",
 		Value: "",
 	}
+	leakCheckDNSFlag = cli.BoolFlag{
+		Name:  "leak-check.dns",
+		Usage: "Check whether DNS queries are resolved through connection after connecting to provider",
+	}
 	// LocationCountryFlag allows to configure service country manually
 	LocationCountryFlag = cli.StringFlag{
 		Name:  "location.country",
@@ -43,14 +55,17 @@ var (
 
 // RegisterFlagsLocation function register location flags to flag list
 func RegisterFlagsLocation(flags *[]cli.Flag) {
-	*flags = append(*flags, ipifyUrlFlag, locationDatabaseFlag, LocationCountryFlag)
+	*flags = append(*flags, ipifyUrlFlag, ipSourcesFlag, locationDatabaseFlag, LocationCountryFlag, leakCheckDNSFlag)
 }
 
 // ParseFlagsLocation function fills in location options from CLI context
 func ParseFlagsLocation(ctx *cli.Context) node.OptionsLocation {
 	return node.OptionsLocation{
 		IpifyUrl:   ctx.GlobalString(ipifyUrlFlag.Name),
+		IPSources:  ctx.GlobalString(ipSourcesFlag.Name),
 		ExternalDb: ctx.GlobalString(locationDatabaseFlag.Name),
 		Country:    ctx.GlobalString(LocationCountryFlag.Name),
+
+		LeakCheckDNS: ctx.GlobalBool(leakCheckDNSFlag.Name),
 	}
 }
@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/urfave/cli"
@@ -43,6 +45,11 @@ var (
 		Usage: "Enables experimental payments check",
 	}
 
+	discoveryTypeFlag = cli.StringFlag{
+		Name:  "discovery-type",
+		Usage: "Proposal discovery backend: 'api' uses discovery service, 'broker' listens to provider announcements on message broker",
+		Value: node.DiscoveryTypeAPI,
+	}
 	discoveryAddressFlag = cli.StringFlag{
 		Name:  "discovery-address",
 		Usage: "`URL` of discovery service",
@@ -50,8 +57,8 @@ var (
 	}
 	brokerAddressFlag = cli.StringFlag{
 		Name:  "broker-address",
-		Usage: "`URI` of message broker",
-		Value: metadata.DefaultNetwork.BrokerAddress,
+		Usage: "Comma separated list of message broker `URI`s, node fails over between them",
+		Value: strings.Join(metadata.DefaultNetwork.BrokerAddresses, ","),
 	}
 
 	etherRpcFlag = cli.StringFlag{
@@ -65,6 +72,19 @@ var (
 		Value: metadata.DefaultNetwork.PaymentsContractAddress.String(),
 	}
 
+	etherRegistrationSubmitFlag = cli.BoolFlag{
+		Name:  "ether.registration.submit",
+		Usage: "Sends registration transaction of unregistered identity to payments contract (requires identity check)",
+	}
+	etherRegistrationPayerFlag = cli.StringFlag{
+		Name:  "ether.registration.payer",
+		Usage: "Keystore account paying ether and tokens for identity registration, identity pays itself when empty",
+	}
+	etherRegistrationPayerPassphraseFlag = cli.StringFlag{
+		Name:  "ether.registration.payer-passphrase",
+		Usage: "Passphrase to unlock keystore account paying for identity registration",
+	}
+
 	qualityOracleFlag = cli.StringFlag{
 		Name:  "quality-oracle.address",
 		Usage: "Address of the quality oracle service",
@@ -79,8 +99,9 @@ func RegisterFlagsNetwork(flags *[]cli.Flag) {
 		testFlag, localnetFlag,
 		identityCheckFlag,
 		paymentCheckFlag,
-		discoveryAddressFlag, brokerAddressFlag,
+		discoveryTypeFlag, discoveryAddressFlag, brokerAddressFlag,
 		etherRpcFlag, etherContractPaymentsFlag,
+		etherRegistrationSubmitFlag, etherRegistrationPayerFlag, etherRegistrationPayerPassphraseFlag,
 		qualityOracleFlag,
 	)
 }
@@ -94,12 +115,17 @@ func ParseFlagsNetwork(ctx *cli.Context) node.OptionsNetwork {
 		ctx.GlobalBool(identityCheckFlag.Name),
 		ctx.GlobalBool(paymentCheckFlag.Name),
 
+		ctx.GlobalString(discoveryTypeFlag.Name),
 		ctx.GlobalString(discoveryAddressFlag.Name),
 		ctx.GlobalString(brokerAddressFlag.Name),
 
 		ctx.GlobalString(etherRpcFlag.Name),
 		ctx.GlobalString(etherContractPaymentsFlag.Name),
 
+		ctx.GlobalBool(etherRegistrationSubmitFlag.Name),
+		ctx.GlobalString(etherRegistrationPayerFlag.Name),
+		ctx.GlobalString(etherRegistrationPayerPassphraseFlag.Name),
+
 		ctx.GlobalString(qualityOracleFlag.Name),
 	}
 }
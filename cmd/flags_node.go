@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/core/node"
 	openvpn_core "github.com/mysteriumnetwork/node/services/openvpn/core"
 	"github.com/urfave/cli"
@@ -38,12 +40,18 @@ var (
 		Name:  "keystore.lightweight",
 		Usage: "Determines the scrypt memory complexity. If set to true, will use 4MB blocks instead of the standard 256MB ones",
 	}
+	keystoreLockIdleFlag = cli.DurationFlag{
+		Name:  "keystore.lock-idle",
+		Usage: "Locks unlocked identities which are not used by connection or service for given period (e.g. 30m). Zero disables idle locking",
+		Value: 0 * time.Second,
+	}
 )
 
 // ParseKeystoreFlags parses the keystore options for node
 func ParseKeystoreFlags(ctx *cli.Context) node.OptionsKeystore {
 	return node.OptionsKeystore{
 		UseLightweight: ctx.GlobalBool(keystoreLightweightFlag.Name),
+		LockIdle:       ctx.GlobalDuration(keystoreLockIdleFlag.Name),
 	}
 }
 
@@ -53,7 +61,7 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		return err
 	}
 
-	*flags = append(*flags, tequilapiAddressFlag, tequilapiPortFlag, keystoreLightweightFlag)
+	*flags = append(*flags, tequilapiAddressFlag, tequilapiPortFlag, keystoreLightweightFlag, keystoreLockIdleFlag)
 
 	RegisterFlagsNetwork(flags)
 	openvpn_core.RegisterFlags(flags)
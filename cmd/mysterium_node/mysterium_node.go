@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/cmd/commands/daemon"
 	"github.com/mysteriumnetwork/node/cmd/commands/license"
 	"github.com/mysteriumnetwork/node/cmd/commands/service"
+	"github.com/mysteriumnetwork/node/cmd/commands/storage"
 	"github.com/mysteriumnetwork/node/cmd/commands/version"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/urfave/cli"
@@ -42,6 +43,7 @@ var (
 	licenseCommand = license.NewCommand(licenseCopyright)
 	serviceCommand = service.NewCommand(licenseCommand.Name)
 	cliCommand     = command_cli.NewCommand()
+	storageCommand = storage.NewCommand()
 )
 
 func main() {
@@ -80,6 +82,7 @@ func NewCommand() (*cli.App, error) {
 		*serviceCommand,
 		*daemonCommand,
 		*cliCommand,
+		*storageCommand,
 	}
 
 	return app, nil
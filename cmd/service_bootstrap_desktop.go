@@ -27,6 +27,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/identity"
+	identity_registry "github.com/mysteriumnetwork/node/identity/registry"
 	identity_selector "github.com/mysteriumnetwork/node/identity/selector"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/market/proposals/registry"
@@ -41,9 +42,10 @@ import (
 const logPrefix = "[service bootstrap] "
 
 type locationInfo struct {
-	OutIP   string
-	PubIP   string
-	Country string
+	OutIP    string
+	PubIP    string
+	Country  string
+	Location market.Location
 }
 
 func (di *Dependencies) resolveIPsAndLocation() (loc locationInfo, err error) {
@@ -59,15 +61,20 @@ func (di *Dependencies) resolveIPsAndLocation() (loc locationInfo, err error) {
 	}
 	loc.OutIP = outboundIP
 
-	currentCountry, err := di.LocationResolver.ResolveCountry(pubIP)
+	currentLocation, err := di.LocationResolver.ResolveLocation(pubIP)
 	if err != nil {
 		log.Warn(logPrefix, "Failed to detect service country. ", err)
 		err = service.ErrorLocation
 		return
 	}
-	loc.Country = currentCountry
+	loc.Country = currentLocation.Country
+	loc.Location = market.Location{
+		Country: currentLocation.Country,
+		City:    currentLocation.City,
+		ASN:     currentLocation.ASN,
+	}
 
-	log.Info(logPrefix, "Detected service country: ", loc.Country)
+	log.Info(logPrefix, "Detected service location: ", loc.Location)
 	return
 }
 
@@ -78,10 +85,9 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			return nil, market.ServiceProposal{}, err
 		}
 
-		currentLocation := market.Location{Country: location.Country}
 		transportOptions := serviceOptions.Options.(openvpn_service.Options)
 
-		proposal := openvpn_discovery.NewServiceProposalWithLocation(currentLocation, transportOptions.OpenvpnProtocol)
+		proposal := openvpn_discovery.NewServiceProposalWithLocation(location.Location, transportOptions.OpenvpnProtocol)
 		return openvpn_service.NewManager(nodeOptions, transportOptions, location.PubIP, location.OutIP, location.Country, di.ServiceSessionStorage, di.NATService), proposal, nil
 	}
 
@@ -96,7 +102,7 @@ func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
 			return nil, market.ServiceProposal{}, err
 		}
 
-		return service_noop.NewManager(), service_noop.GetProposal(location.Country), nil
+		return service_noop.NewManager(), service_noop.GetProposal(location.Location), nil
 	})
 
 	di.ServiceRunner.Register(service_noop.ServiceType)
@@ -107,7 +113,7 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) {
 	identityHandler := identity_selector.NewHandler(
 		di.IdentityManager,
 		di.MysteriumAPI,
-		identity.NewIdentityCache(nodeOptions.Directories.Keystore, "remember.json"),
+		di.IdentityCache,
 		di.SignerFactory,
 	)
 
@@ -119,19 +125,36 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) {
 	di.ServiceSessionStorage = session.NewStorageMemory()
 
 	newDialogWaiter := func(providerID identity.Identity, serviceType string) (communication.DialogWaiter, error) {
-		address, err := nats_discovery.NewAddressFromHostAndID(di.NetworkDefinition.BrokerAddress, providerID, serviceType)
+		address, err := nats_discovery.NewAddressFromHostAndID(di.NetworkDefinition.BrokerAddresses, providerID, serviceType)
 		if err != nil {
 			return nil, err
 		}
+		// registration proof of consumers is checked only when identity check is enabled
+		var identityRegistry identity_registry.IdentityRegistry
+		if nodeOptions.ExperimentIdentityCheck {
+			identityRegistry = di.IdentityRegistry
+		}
+
+		// consumers which are not able to pay are rejected when payments are enabled
+		protocol := newProtocolInfo(nodeOptions)
+		if nodeOptions.ExperimentPayments {
+			protocol = protocol.Require(communication.CapabilityPayments)
+		}
 
-		return nats_dialog.NewDialogWaiter(
-			address,
-			di.SignerFactory(providerID),
-			di.IdentityRegistry,
-		), nil
+		return &trackedDialogWaiter{
+			statsDialogWaiter: nats_dialog.NewDialogWaiter(
+				address,
+				di.SignerFactory(providerID),
+				identityRegistry,
+				protocol,
+				nats_dialog.DefaultWaiterLimits(),
+			),
+			address: address,
+			tracker: di.BrokerAddressTracker,
+		}, nil
 	}
-	newDialogHandler := func(proposal market.ServiceProposal, configProvider session.ConfigNegotiator) communication.DialogHandler {
-		sessionManagerFactory := newSessionManagerFactory(proposal, di.ServiceSessionStorage, nodeOptions)
+	newDialogHandler := func(proposalLookup session.ProposalLookup, configProvider session.ConfigNegotiator) communication.DialogHandler {
+		sessionManagerFactory := newSessionManagerFactory(proposalLookup, di.ServiceSessionStorage, nodeOptions)
 		return session.NewDialogHandler(sessionManagerFactory, configProvider.ProvideConfig)
 	}
 
@@ -141,9 +164,42 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) {
 			di.ServiceRegistry.Create,
 			newDialogWaiter,
 			newDialogHandler,
-			registry.NewService(di.IdentityRegistry, di.IdentityRegistration, di.MysteriumAPI, di.SignerFactory),
+			func() *registry.Discovery {
+				return registry.NewService(
+					di.IdentityRegistry,
+					di.IdentityRegistration,
+					di.IdentityRegistrator,
+					di.ProposalRegistry,
+					di.SignerFactory,
+				)
+			},
 		)
 	}
 
 	di.ServiceRunner = service.NewRunner(runnableServiceFactory)
 }
+
+// statsDialogWaiter is a dialog waiter which counts rejected dialog requests
+type statsDialogWaiter interface {
+	communication.DialogWaiter
+	Stats() communication.DialogWaiterStats
+}
+
+// trackedDialogWaiter reports broker connection state of the dialog waiter only after it has connected,
+// so that addresses of services which failed to start are not tracked
+type trackedDialogWaiter struct {
+	statsDialogWaiter
+	address *nats_discovery.AddressNATS
+	tracker *nats_discovery.AddressTracker
+}
+
+// Start connects the dialog waiter and starts tracking its broker address
+func (waiter *trackedDialogWaiter) Start() (market.Contact, error) {
+	contact, err := waiter.statsDialogWaiter.Start()
+	if err != nil {
+		return contact, err
+	}
+
+	waiter.tracker.Track(waiter.address)
+	return contact, nil
+}
@@ -45,7 +45,7 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 			return nil, market.ServiceProposal{}, err
 		}
 
-		return wireguard_service.NewManager(location.PubIP, location.OutIP, location.Country, di.NATService), wireguard_service.GetProposal(location.Country), nil
+		return wireguard_service.NewManager(location.PubIP, location.OutIP, location.Country, di.NATService), wireguard_service.GetProposal(location.Location), nil
 	})
 
 	di.ServiceRunner.Register(wireguard.ServiceType)
@@ -32,6 +32,15 @@ type DialogWaiter interface {
 	ServeDialogs(DialogHandler) error
 }
 
+// DialogWaiterStats holds counters of dialog requests rejected by DialogWaiter
+type DialogWaiterStats struct {
+	RejectedInvalidIdentity uint64
+	RejectedRateLimited     uint64
+	RejectedCapacity        uint64
+	RejectedIncompatible    uint64
+	RejectedInternalError   uint64
+}
+
 // DialogHandler defines how to handle incoming Dialog
 type DialogHandler interface {
 	Handle(Dialog) error
@@ -48,6 +57,8 @@ type DialogEstablisher interface {
 // Enables bidirectional communication with another peer.
 type Dialog interface {
 	PeerID() identity.Identity
+	// Protocol returns protocol version and capabilities negotiated by both peers
+	Protocol() ProtocolInfo
 	Sender
 	Receiver
 	Close() error
@@ -18,6 +18,8 @@
 package dialog
 
 import (
+	"sync"
+
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/identity"
 )
@@ -25,13 +27,36 @@ import (
 type dialog struct {
 	communication.Sender
 	communication.Receiver
-	peerID identity.Identity
+	peerID      identity.Identity
+	protocol    communication.ProtocolInfo
+	releaseFunc func()
+
+	unsubscribeOnce sync.Once
+}
+
+// Unsubscribe stops receiving messages and releases dialog from its owner, repeated calls are ignored
+func (dialog *dialog) Unsubscribe() {
+	dialog.unsubscribeOnce.Do(func() {
+		dialog.Receiver.Unsubscribe()
+		dialog.release()
+	})
 }
 
 func (dialog *dialog) Close() error {
+	dialog.release()
 	return nil
 }
 
 func (dialog *dialog) PeerID() identity.Identity {
 	return dialog.peerID
 }
+
+func (dialog *dialog) Protocol() communication.ProtocolInfo {
+	return dialog.protocol
+}
+
+func (dialog *dialog) release() {
+	if dialog.releaseFunc != nil {
+		dialog.releaseFunc()
+	}
+}
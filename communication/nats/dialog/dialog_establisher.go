@@ -29,11 +29,12 @@ import (
 )
 
 // NewDialogEstablisher constructs new DialogEstablisher which works thru NATS connection.
-func NewDialogEstablisher(ID identity.Identity, signer identity.Signer) *dialogEstablisher {
+func NewDialogEstablisher(ID identity.Identity, signer identity.Signer, protocol communication.ProtocolInfo) *dialogEstablisher {
 
 	return &dialogEstablisher{
-		ID:     ID,
-		Signer: signer,
+		ID:       ID,
+		Signer:   signer,
+		Protocol: protocol,
 		peerAddressFactory: func(contact market.Contact) (*discovery.AddressNATS, error) {
 			address, err := discovery.NewAddressForContact(contact)
 			if err == nil {
@@ -50,6 +51,7 @@ const establisherLogPrefix = "[NATS.DialogEstablisher] "
 type dialogEstablisher struct {
 	ID                 identity.Identity
 	Signer             identity.Signer
+	Protocol           communication.ProtocolInfo
 	peerAddressFactory func(contact market.Contact) (*discovery.AddressNATS, error)
 }
 
@@ -67,31 +69,42 @@ func (establisher *dialogEstablisher) EstablishDialog(
 	peerCodec := establisher.newCodecForPeer(peerID)
 
 	peerSender := establisher.newSenderToPeer(peerAddress, peerCodec)
-	err = establisher.negotiateDialog(peerSender)
+	protocol, err := establisher.negotiateDialog(peerSender)
 	if err != nil {
 		return nil, err
 	}
 
-	dialog := establisher.newDialogToPeer(peerID, peerAddress, peerCodec)
+	dialog := establisher.newDialogToPeer(peerID, peerAddress, peerCodec, protocol)
 	log.Info(establisherLogPrefix, fmt.Sprintf("Dialog established with: %#v", peerContact))
 
 	return dialog, nil
 }
 
-func (establisher *dialogEstablisher) negotiateDialog(sender communication.Sender) error {
-	response, err := sender.Request(&dialogCreateProducer{
+func (establisher *dialogEstablisher) negotiateDialog(sender communication.Sender) (communication.ProtocolInfo, error) {
+	responsePtr, err := sender.Request(&dialogCreateProducer{
 		&dialogCreateRequest{
-			PeerID: establisher.ID.Address,
+			PeerID:   establisher.ID.Address,
+			Protocol: &establisher.Protocol,
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("dialog creation error. %s", err)
+		return communication.ProtocolInfo{}, fmt.Errorf("dialog creation error. %s", err)
 	}
-	if response.(*dialogCreateResponse).Reason != 200 {
-		return fmt.Errorf("dialog creation rejected. %#v", response)
+
+	response := responsePtr.(*dialogCreateResponse)
+	if response.Reason == reasonIncompatibleProtocol {
+		return communication.ProtocolInfo{}, fmt.Errorf("dialog creation rejected. %s", response.ReasonMessage)
+	}
+	if response.Reason != 200 {
+		return communication.ProtocolInfo{}, fmt.Errorf("dialog creation rejected. %#v", response)
+	}
+
+	protocol, err := establisher.Protocol.Negotiate(communication.ProtocolInfoOrLegacy(response.Protocol))
+	if err != nil {
+		return communication.ProtocolInfo{}, fmt.Errorf("dialog creation rejected. %s", err)
 	}
 
-	return nil
+	return protocol, nil
 }
 
 func (establisher *dialogEstablisher) newCodecForPeer(peerID identity.Identity) *codecSecured {
@@ -119,11 +132,13 @@ func (establisher *dialogEstablisher) newDialogToPeer(
 	peerID identity.Identity,
 	peerAddress *discovery.AddressNATS,
 	peerCodec *codecSecured,
+	protocol communication.ProtocolInfo,
 ) *dialog {
 
 	subTopic := peerAddress.GetTopic() + "." + establisher.ID.Address
 	return &dialog{
 		peerID:   peerID,
+		protocol: protocol,
 		Sender:   nats.NewSender(peerAddress.GetConnection(), peerCodec, subTopic),
 		Receiver: nats.NewReceiver(peerAddress.GetConnection(), peerCodec, subTopic),
 	}
@@ -34,10 +34,13 @@ func TestDialogEstablisher_Factory(t *testing.T) {
 	id := identity.FromAddress("123456")
 	signer := &identity.SignerFake{}
 
-	establisher := NewDialogEstablisher(id, signer)
+	protocol := communication.NewProtocolInfo(communication.CapabilityCodecJSON)
+
+	establisher := NewDialogEstablisher(id, signer, protocol)
 	assert.NotNil(t, establisher)
 	assert.Equal(t, id, establisher.ID)
 	assert.Equal(t, signer, establisher.Signer)
+	assert.Equal(t, protocol, establisher.Protocol)
 }
 
 func TestDialogEstablisher_EstablishDialog(t *testing.T) {
@@ -64,6 +67,7 @@ func TestDialogEstablisher_EstablishDialog(t *testing.T) {
 
 	dialog, ok := dialogInstance.(*dialog)
 	assert.True(t, ok)
+	assert.Equal(t, 1, dialog.Protocol().Version)
 
 	expectedCodec := NewCodecSecured(communication.NewCodecJSON(), signer, identity.NewVerifierIdentity(peerID))
 	assert.Equal(
@@ -100,12 +104,51 @@ func TestDialogEstablisher_CreateDialogWhenResponseHijacked(t *testing.T) {
 	assert.Nil(t, dialogInstance)
 }
 
+func TestDialogEstablisher_RejectedWithIncompatibleProtocol(t *testing.T) {
+	establisher := mockEstablisher(identity.FromAddress("0x1"), nats.NewConnectionFake(), &identity.SignerFake{})
+
+	_, err := establisher.negotiateDialog(&senderFake{
+		response: &dialogCreateResponse{
+			Reason:        426,
+			ReasonMessage: "incompatible protocol: peer does not support payments",
+		},
+	})
+	assert.EqualError(t, err, "dialog creation rejected. incompatible protocol: peer does not support payments")
+}
+
+func TestDialogEstablisher_NegotiatesCommonProtocol(t *testing.T) {
+	establisher := mockEstablisher(identity.FromAddress("0x1"), nats.NewConnectionFake(), &identity.SignerFake{})
+	establisher.Protocol = communication.NewProtocolInfo(communication.CapabilityPayments, communication.CapabilityCodecJSON)
+
+	providerProtocol := communication.NewProtocolInfo(communication.CapabilityCodecJSON)
+	protocol, err := establisher.negotiateDialog(&senderFake{
+		response: &dialogCreateResponse{Reason: 200, ReasonMessage: "OK", Protocol: &providerProtocol},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, communication.ProtocolVersion, protocol.Version)
+	assert.False(t, protocol.Supports(communication.CapabilityPayments))
+	assert.True(t, protocol.Supports(communication.CapabilityCodecJSON))
+}
+
+type senderFake struct {
+	response interface{}
+}
+
+func (sender *senderFake) Send(producer communication.MessageProducer) error {
+	return nil
+}
+
+func (sender *senderFake) Request(producer communication.RequestProducer) (responsePtr interface{}, err error) {
+	return sender.response, nil
+}
+
 func mockEstablisher(ID identity.Identity, connection nats.Connection, signer identity.Signer) *dialogEstablisher {
 	peerTopic := "peer-topic"
 
 	return &dialogEstablisher{
-		ID:     ID,
-		Signer: signer,
+		ID:       ID,
+		Signer:   signer,
+		Protocol: communication.NewProtocolInfo(),
 		peerAddressFactory: func(contact market.Contact) (*discovery.AddressNATS, error) {
 			return discovery.NewAddressWithConnection(connection, peerTopic), nil
 		},
@@ -20,6 +20,7 @@ package dialog
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	log "github.com/cihub/seelog"
 	"github.com/mysteriumnetwork/node/communication"
@@ -30,23 +31,74 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 )
 
+// WaiterLimits defines protection limits which are applied to incoming dialog requests
+type WaiterLimits struct {
+	// PerIdentityRate is amount of dialogs single identity may create during RateInterval
+	PerIdentityRate int
+	// GlobalRate is amount of dialogs all identities together may create during RateInterval
+	GlobalRate   int
+	RateInterval time.Duration
+
+	// MaxDialogs is amount of peers which may hold dialog at the same time
+	MaxDialogs int
+
+	// RegistrationCacheTTL defines how long answers of identity registry are remembered
+	RegistrationCacheTTL time.Duration
+}
+
+// DefaultWaiterLimits returns limits suitable for most of providers
+func DefaultWaiterLimits() WaiterLimits {
+	return WaiterLimits{
+		PerIdentityRate:      5,
+		GlobalRate:           100,
+		RateInterval:         time.Minute,
+		MaxDialogs:           1000,
+		RegistrationCacheTTL: 10 * time.Minute,
+	}
+}
+
 // NewDialogWaiter constructs new DialogWaiter which works through NATS connection.
-func NewDialogWaiter(address *discovery.AddressNATS, signer identity.Signer, identityRegistry registry.IdentityRegistry) *dialogWaiter {
-	return &dialogWaiter{
+// Proof-of-registration check of peers is skipped when identityRegistry is nil.
+func NewDialogWaiter(
+	address *discovery.AddressNATS,
+	signer identity.Signer,
+	identityRegistry registry.IdentityRegistry,
+	protocol communication.ProtocolInfo,
+	limits WaiterLimits,
+) *dialogWaiter {
+	waiter := &dialogWaiter{
 		address:          address,
 		signer:           signer,
-		dialogs:          make([]communication.Dialog, 0),
-		identityRegistry: identityRegistry,
+		protocol:         protocol,
+		dialogs:          make(map[string]communication.Dialog),
+		limits:           limits,
+		peerRateLimiter:  newRateLimiter(limits.PerIdentityRate, limits.RateInterval),
+		totalRateLimiter: newRateLimiter(limits.GlobalRate, limits.RateInterval),
 	}
+	if identityRegistry != nil {
+		waiter.identityRegistry = newRegistrationCache(identityRegistry, limits.RegistrationCacheTTL)
+	}
+
+	return waiter
 }
 
 const waiterLogPrefix = "[NATS.DialogWaiter] "
 
+type registrationChecker interface {
+	IsRegistered(identity.Identity) (bool, error)
+}
+
 type dialogWaiter struct {
 	address          *discovery.AddressNATS
 	signer           identity.Signer
-	dialogs          []communication.Dialog
-	identityRegistry registry.IdentityRegistry
+	dialogs          map[string]communication.Dialog
+	identityRegistry registrationChecker
+	protocol         communication.ProtocolInfo
+
+	limits           WaiterLimits
+	peerRateLimiter  *rateLimiter
+	totalRateLimiter *rateLimiter
+	stats            communication.DialogWaiterStats
 
 	sync.RWMutex
 }
@@ -66,49 +118,147 @@ func (waiter *dialogWaiter) Start() (market.Contact, error) {
 // Stop disconnects dialogWaiter from broker (NATS) service
 func (waiter *dialogWaiter) Stop() error {
 	waiter.RLock()
-	defer waiter.RUnlock()
-
+	dialogs := make([]communication.Dialog, 0, len(waiter.dialogs))
 	for _, dialog := range waiter.dialogs {
+		dialogs = append(dialogs, dialog)
+	}
+	waiter.RUnlock()
+
+	for _, dialog := range dialogs {
 		dialog.Close()
 	}
 	waiter.address.Disconnect()
 	return nil
 }
 
+// Stats returns counters of rejected dialog requests
+func (waiter *dialogWaiter) Stats() communication.DialogWaiterStats {
+	waiter.RLock()
+	defer waiter.RUnlock()
+
+	return waiter.stats
+}
+
 // ServeDialogs starts accepting dialogs initiated by peers
 func (waiter *dialogWaiter) ServeDialogs(dialogHandler communication.DialogHandler) error {
 	createDialog := func(request *dialogCreateRequest) (*dialogCreateResponse, error) {
+		return waiter.createDialog(dialogHandler, request), nil
+	}
 
-		valid, err := waiter.validateDialogRequest(request)
-		if err != nil {
-			log.Error(waiterLogPrefix, "Validation check failed: ", err.Error())
-			return &responseInternalError, nil
-		}
-		if !valid {
-			log.Error(waiterLogPrefix, "Rejecting invalid peerID: ", request.PeerID)
-			return &responseInvalidIdentity, nil
-		}
+	codec := NewCodecSecured(communication.NewCodecJSON(), waiter.signer, identity.NewVerifierSigned())
+	receiver := nats.NewReceiver(waiter.address.GetConnection(), codec, waiter.address.GetTopic())
 
-		peerID := identity.FromAddress(request.PeerID)
-		dialog := waiter.newDialogToPeer(peerID, waiter.newCodecForPeer(peerID))
-		err = dialogHandler.Handle(dialog)
-		if err != nil {
-			log.Error(waiterLogPrefix, fmt.Sprintf("Failed dialog from: '%s'. %s", request.PeerID, err))
-			return &responseInternalError, nil
-		}
+	return receiver.Respond(&dialogCreateConsumer{createDialog})
+}
 
-		waiter.Lock()
-		waiter.dialogs = append(waiter.dialogs, dialog)
-		waiter.Unlock()
+func (waiter *dialogWaiter) createDialog(dialogHandler communication.DialogHandler, request *dialogCreateRequest) *dialogCreateResponse {
+	if response := waiter.admitDialogRequest(request); response != nil {
+		return response
+	}
 
-		log.Info(waiterLogPrefix, fmt.Sprintf("Accepted dialog from: '%s'", request.PeerID))
-		return &responseOK, nil
+	protocol, err := waiter.protocol.Negotiate(communication.ProtocolInfoOrLegacy(request.Protocol))
+	if err != nil {
+		log.Warn(waiterLogPrefix, fmt.Sprintf("Rejecting peerID '%s'. %s", request.PeerID, err))
+		return waiter.reject(&dialogCreateResponse{
+			Reason:        reasonIncompatibleProtocol,
+			ReasonMessage: err.Error(),
+			Protocol:      &waiter.protocol,
+		})
 	}
 
-	codec := NewCodecSecured(communication.NewCodecJSON(), waiter.signer, identity.NewVerifierSigned())
-	receiver := nats.NewReceiver(waiter.address.GetConnection(), codec, waiter.address.GetTopic())
+	peerID := identity.FromAddress(request.PeerID)
+	dialog := waiter.newDialogToPeer(peerID, waiter.newCodecForPeer(peerID), protocol)
+	err = dialogHandler.Handle(dialog)
+	if err != nil {
+		log.Error(waiterLogPrefix, fmt.Sprintf("Failed dialog from: '%s'. %s", request.PeerID, err))
+		return waiter.reject(&responseInternalError)
+	}
 
-	return receiver.Respond(&dialogCreateConsumer{createDialog})
+	waiter.Lock()
+	waiter.dialogs[peerID.Address] = dialog
+	waiter.Unlock()
+
+	log.Info(waiterLogPrefix, fmt.Sprintf("Accepted dialog from: '%s' (protocol v%d)", request.PeerID, protocol.Version))
+	response := responseOK
+	response.Protocol = &protocol
+	return &response
+}
+
+// admitDialogRequest applies protection limits to request and returns rejection response when request is not admitted
+func (waiter *dialogWaiter) admitDialogRequest(request *dialogCreateRequest) *dialogCreateResponse {
+	if request.PeerID == "" {
+		log.Error(waiterLogPrefix, "Rejecting empty peerID")
+		return waiter.reject(&responseInvalidIdentity)
+	}
+
+	if !waiter.totalRateLimiter.Allow("") {
+		log.Warn(waiterLogPrefix, "Rejecting peerID, global dialog rate exceeded: ", request.PeerID)
+		return waiter.reject(&responseTooManyRequests)
+	}
+	if !waiter.peerRateLimiter.Allow(request.PeerID) {
+		log.Warn(waiterLogPrefix, "Rejecting peerID, dialog rate exceeded: ", request.PeerID)
+		return waiter.reject(&responseTooManyRequests)
+	}
+
+	if !waiter.hasCapacityFor(request.PeerID) {
+		log.Warn(waiterLogPrefix, "Rejecting peerID, too many concurrent dialogs: ", request.PeerID)
+		return waiter.reject(&responseUnavailable)
+	}
+
+	valid, err := waiter.validateDialogRequest(request)
+	if err != nil {
+		log.Error(waiterLogPrefix, "Validation check failed: ", err.Error())
+		return waiter.reject(&responseInternalError)
+	}
+	if !valid {
+		log.Error(waiterLogPrefix, "Rejecting invalid peerID: ", request.PeerID)
+		return waiter.reject(&responseInvalidIdentity)
+	}
+
+	return nil
+}
+
+func (waiter *dialogWaiter) hasCapacityFor(peerAddress string) bool {
+	if waiter.limits.MaxDialogs <= 0 {
+		return true
+	}
+
+	waiter.RLock()
+	defer waiter.RUnlock()
+
+	if _, exists := waiter.dialogs[peerAddress]; exists {
+		return true
+	}
+	return len(waiter.dialogs) < waiter.limits.MaxDialogs
+}
+
+func (waiter *dialogWaiter) reject(response *dialogCreateResponse) *dialogCreateResponse {
+	waiter.Lock()
+	defer waiter.Unlock()
+
+	switch response.Reason {
+	case responseInvalidIdentity.Reason:
+		waiter.stats.RejectedInvalidIdentity++
+	case responseTooManyRequests.Reason:
+		waiter.stats.RejectedRateLimited++
+	case responseUnavailable.Reason:
+		waiter.stats.RejectedCapacity++
+	case reasonIncompatibleProtocol:
+		waiter.stats.RejectedIncompatible++
+	default:
+		waiter.stats.RejectedInternalError++
+	}
+
+	return response
+}
+
+func (waiter *dialogWaiter) releaseDialog(peerAddress string, dialog communication.Dialog) {
+	waiter.Lock()
+	defer waiter.Unlock()
+
+	if waiter.dialogs[peerAddress] == dialog {
+		delete(waiter.dialogs, peerAddress)
+	}
 }
 
 func (waiter *dialogWaiter) newCodecForPeer(peerID identity.Identity) *codecSecured {
@@ -120,19 +270,25 @@ func (waiter *dialogWaiter) newCodecForPeer(peerID identity.Identity) *codecSecu
 	)
 }
 
-func (waiter *dialogWaiter) newDialogToPeer(peerID identity.Identity, peerCodec *codecSecured) *dialog {
+func (waiter *dialogWaiter) newDialogToPeer(peerID identity.Identity, peerCodec *codecSecured, protocol communication.ProtocolInfo) *dialog {
 	subTopic := waiter.address.GetTopic() + "." + peerID.Address
 
-	return &dialog{
+	peerDialog := &dialog{
 		peerID:   peerID,
+		protocol: protocol,
 		Sender:   nats.NewSender(waiter.address.GetConnection(), peerCodec, subTopic),
 		Receiver: nats.NewReceiver(waiter.address.GetConnection(), peerCodec, subTopic),
 	}
+	peerDialog.releaseFunc = func() {
+		waiter.releaseDialog(peerID.Address, peerDialog)
+	}
+
+	return peerDialog
 }
 
 func (waiter *dialogWaiter) validateDialogRequest(request *dialogCreateRequest) (bool, error) {
-	if request.PeerID == "" {
-		return false, nil
+	if waiter.identityRegistry == nil {
+		return true, nil
 	}
 
 	registered, err := waiter.identityRegistry.IsRegistered(identity.FromAddress(request.PeerID))
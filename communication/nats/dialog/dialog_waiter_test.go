@@ -36,10 +36,18 @@ func TestDialogWaiter_Factory(t *testing.T) {
 	address := discovery.NewAddress("custom", "nats://far-server:4222")
 	signer := &identity.SignerFake{}
 
-	waiter := NewDialogWaiter(address, signer, &mockedIdentityRegistry{})
+	waiter := NewDialogWaiter(address, signer, &mockedIdentityRegistry{}, communication.NewProtocolInfo(), DefaultWaiterLimits())
 	assert.NotNil(t, waiter)
 	assert.Equal(t, address, waiter.address)
 	assert.Equal(t, signer, waiter.signer)
+	assert.NotNil(t, waiter.identityRegistry)
+}
+
+func TestDialogWaiter_FactoryWithoutRegistry(t *testing.T) {
+	address := discovery.NewAddress("custom", "nats://far-server:4222")
+
+	waiter := NewDialogWaiter(address, &identity.SignerFake{}, nil, communication.NewProtocolInfo(), DefaultWaiterLimits())
+	assert.Nil(t, waiter.identityRegistry)
 }
 
 func TestDialogWaiter_ServeDialogs(t *testing.T) {
@@ -63,6 +71,7 @@ func TestDialogWaiter_ServeDialogs(t *testing.T) {
 
 	dialog, ok := dialogInstance.(*dialog)
 	assert.True(t, ok)
+	assert.Equal(t, 1, dialog.Protocol().Version)
 
 	expectedCodec := NewCodecSecured(communication.NewCodecJSON(), signer, identity.NewVerifierIdentity(peerID))
 	assert.Equal(
@@ -108,7 +117,7 @@ func TestDialogWaiter_ServeDialogsRejectUnregisteredConsumers(t *testing.T) {
 		dialogReceived: make(chan communication.Dialog),
 	}
 
-	waiter := NewDialogWaiter(discovery.NewAddressWithConnection(connection, "test-topic"), signer, mockedRegistry)
+	waiter := NewDialogWaiter(discovery.NewAddressWithConnection(connection, "test-topic"), signer, mockedRegistry, communication.NewProtocolInfo(), DefaultWaiterLimits())
 
 	err := waiter.ServeDialogs(mockeDialogHandler)
 	assert.NoError(t, err)
@@ -130,17 +139,161 @@ func TestDialogWaiter_ServeDialogsRejectUnregisteredConsumers(t *testing.T) {
 		}`,
 		string(msg.Data),
 	)
+	assert.Equal(t, communication.DialogWaiterStats{RejectedInvalidIdentity: 1}, waiter.Stats())
+}
+
+func TestDialogWaiter_ServeDialogsRejectRateLimitedConsumers(t *testing.T) {
+	connection := nats.StartConnectionFake()
+	defer connection.Close()
+
+	limits := DefaultWaiterLimits()
+	limits.PerIdentityRate = 1
+	waiter, handler := dialogServeWithLimits(connection, &identity.SignerFake{}, limits)
+	defer waiter.Stop()
+
+	dialogAsk(connection, `{
+		"payload": {"peer_id":"0x28bf83df144ab7a566bc8509d1fff5d5470bd4ea"},
+		"signature": "tl+WbYkJdXD5foaIP3bqVGFHfr6kdd5FzmJAmu1GdpINEnNR3bTto6wgEoke/Fpy4zsWOjrulDVfrc32f5ArTgA="
+	}`)
+	_, err := dialogWait(handler)
+	assert.NoError(t, err)
+
+	msg, err := connection.Request("my-topic.dialog-create", []byte(`{
+		"payload": {"peer_id":"0x28bf83df144ab7a566bc8509d1fff5d5470bd4ea"},
+		"signature": "tl+WbYkJdXD5foaIP3bqVGFHfr6kdd5FzmJAmu1GdpINEnNR3bTto6wgEoke/Fpy4zsWOjrulDVfrc32f5ArTgA="
+	}`), 100*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Contains(t, string(msg.Data), `"reason":429`)
+	assert.Equal(t, communication.DialogWaiterStats{RejectedRateLimited: 1}, waiter.Stats())
+}
+
+func TestDialogWaiter_CreateDialogRejectWhenCapacityExceeded(t *testing.T) {
+	limits := DefaultWaiterLimits()
+	limits.MaxDialogs = 1
+	waiter := NewDialogWaiter(
+		discovery.NewAddressWithConnection(nats.NewConnectionFake(), "my-topic"),
+		&identity.SignerFake{},
+		nil,
+		communication.NewProtocolInfo(),
+		limits,
+	)
+	handler := &dialogHandler{dialogReceived: make(chan communication.Dialog, 3)}
+
+	response := waiter.createDialog(handler, &dialogCreateRequest{PeerID: "0x1"})
+	assert.Equal(t, responseOK.Reason, response.Reason)
+
+	response = waiter.createDialog(handler, &dialogCreateRequest{PeerID: "0x2"})
+	assert.Equal(t, responseUnavailable.Reason, response.Reason)
+	assert.Equal(t, communication.DialogWaiterStats{RejectedCapacity: 1}, waiter.Stats())
+
+	// peer which already holds dialog is not limited
+	response = waiter.createDialog(handler, &dialogCreateRequest{PeerID: "0x1"})
+	assert.Equal(t, responseOK.Reason, response.Reason)
+
+	// slot is freed once dialog is torn down
+	firstDialog := <-handler.dialogReceived
+	secondDialog := <-handler.dialogReceived
+	firstDialog.Unsubscribe()
+	secondDialog.Unsubscribe()
+	response = waiter.createDialog(handler, &dialogCreateRequest{PeerID: "0x2"})
+	assert.Equal(t, responseOK.Reason, response.Reason)
+	assert.Equal(t, communication.DialogWaiterStats{RejectedCapacity: 1}, waiter.Stats())
+}
+
+func TestDialogWaiter_CreateDialogRejectIncompatibleProtocol(t *testing.T) {
+	providerProtocol := communication.NewProtocolInfo(communication.CapabilityPayments).Require(communication.CapabilityPayments)
+	waiter := NewDialogWaiter(
+		discovery.NewAddressWithConnection(nats.NewConnectionFake(), "my-topic"),
+		&identity.SignerFake{},
+		nil,
+		providerProtocol,
+		DefaultWaiterLimits(),
+	)
+
+	consumerProtocol := communication.NewProtocolInfo(communication.CapabilityCodecJSON)
+	response := waiter.createDialog(
+		&dialogHandler{dialogReceived: make(chan communication.Dialog)},
+		&dialogCreateRequest{PeerID: "0x28bf83df144ab7a566bc8509d1fff5d5470bd4ea", Protocol: &consumerProtocol},
+	)
+	assert.Equal(
+		t,
+		&dialogCreateResponse{
+			Reason:        426,
+			ReasonMessage: "incompatible protocol: peer does not support payments",
+			Protocol:      &providerProtocol,
+		},
+		response,
+	)
+	assert.Equal(t, communication.DialogWaiterStats{RejectedIncompatible: 1}, waiter.Stats())
+}
+
+func TestDialogWaiter_CreateDialogNegotiatesProtocol(t *testing.T) {
+	waiter := NewDialogWaiter(
+		discovery.NewAddressWithConnection(nats.NewConnectionFake(), "my-topic"),
+		&identity.SignerFake{},
+		nil,
+		communication.NewProtocolInfo(communication.CapabilityPayments, communication.CapabilityCodecJSON),
+		DefaultWaiterLimits(),
+	)
+	handler := &dialogHandler{dialogReceived: make(chan communication.Dialog, 1)}
+
+	consumerProtocol := communication.NewProtocolInfo(communication.CapabilityCodecJSON)
+	response := waiter.createDialog(
+		handler,
+		&dialogCreateRequest{PeerID: "0x28bf83df144ab7a566bc8509d1fff5d5470bd4ea", Protocol: &consumerProtocol},
+	)
+	expectedProtocol := communication.ProtocolInfo{
+		Version:      communication.ProtocolVersion,
+		MinVersion:   communication.ProtocolVersion,
+		Capabilities: []communication.Capability{communication.CapabilityCodecJSON},
+	}
+	assert.Equal(t, &dialogCreateResponse{Reason: 200, ReasonMessage: "OK", Protocol: &expectedProtocol}, response)
+
+	dialogInstance := <-handler.dialogReceived
+	assert.Equal(t, expectedProtocol, dialogInstance.Protocol())
+}
+
+func TestDialogWaiter_ReleasesDialogOnUnsubscribe(t *testing.T) {
+	connection := nats.StartConnectionFake()
+	defer connection.Close()
+
+	waiter, handler := dialogServe(connection, &identity.SignerFake{})
+	defer waiter.Stop()
+
+	dialogAsk(connection, `{
+		"payload": {"peer_id":"0x28bf83df144ab7a566bc8509d1fff5d5470bd4ea"},
+		"signature": "tl+WbYkJdXD5foaIP3bqVGFHfr6kdd5FzmJAmu1GdpINEnNR3bTto6wgEoke/Fpy4zsWOjrulDVfrc32f5ArTgA="
+	}`)
+	dialogInstance, err := dialogWait(handler)
+	assert.NoError(t, err)
+
+	// dialog is stored right after it is handled
+	time.Sleep(10 * time.Millisecond)
+	waiter.RLock()
+	assert.Len(t, waiter.dialogs, 1)
+	waiter.RUnlock()
+
+	dialogInstance.Unsubscribe()
+	waiter.RLock()
+	assert.Len(t, waiter.dialogs, 0)
+	waiter.RUnlock()
 }
 
 func dialogServe(connection nats.Connection, signer identity.Signer) (waiter *dialogWaiter, handler *dialogHandler) {
+	return dialogServeWithLimits(connection, signer, DefaultWaiterLimits())
+}
+
+func dialogServeWithLimits(connection nats.Connection, signer identity.Signer, limits WaiterLimits) (waiter *dialogWaiter, handler *dialogHandler) {
 	topic := "my-topic"
-	waiter = &dialogWaiter{
-		address: discovery.NewAddressWithConnection(connection, topic),
-		signer:  signer,
-		identityRegistry: &mockedIdentityRegistry{
+	waiter = NewDialogWaiter(
+		discovery.NewAddressWithConnection(connection, topic),
+		signer,
+		&mockedIdentityRegistry{
 			anyIdentityRegistered: true,
 		},
-	}
+		communication.NewProtocolInfo(communication.CapabilityPayments, communication.CapabilityCodecJSON),
+		limits,
+	)
 	handler = &dialogHandler{
 		dialogReceived: make(chan communication.Dialog),
 	}
@@ -25,16 +25,23 @@ import (
 const endpointDialogCreate = communication.RequestEndpoint("dialog-create")
 
 var (
-	responseOK              = dialogCreateResponse{200, "OK"}
-	responseInvalidIdentity = dialogCreateResponse{400, "Invalid Identity"}
-	responseInternalError   = dialogCreateResponse{500, "Internal Error"}
+	responseOK              = dialogCreateResponse{Reason: 200, ReasonMessage: "OK"}
+	responseInvalidIdentity = dialogCreateResponse{Reason: 400, ReasonMessage: "Invalid Identity"}
+	responseTooManyRequests = dialogCreateResponse{Reason: 429, ReasonMessage: "Too Many Requests"}
+	responseInternalError   = dialogCreateResponse{Reason: 500, ReasonMessage: "Internal Error"}
+	responseUnavailable     = dialogCreateResponse{Reason: 503, ReasonMessage: "Service Unavailable"}
 )
 
+// reasonIncompatibleProtocol is returned together with negotiation error when consumer protocol is not supported
+const reasonIncompatibleProtocol = 426
+
 type dialogCreateRequest struct {
-	PeerID string `json:"peer_id"`
+	PeerID   string                      `json:"peer_id"`
+	Protocol *communication.ProtocolInfo `json:"protocol,omitempty"`
 }
 
 type dialogCreateResponse struct {
-	Reason        uint   `json:"reason"`
-	ReasonMessage string `json:"reasonMessage"`
+	Reason        uint                        `json:"reason"`
+	ReasonMessage string                      `json:"reasonMessage"`
+	Protocol      *communication.ProtocolInfo `json:"protocol,omitempty"`
 }
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dialog
+
+import (
+	"sync"
+	"time"
+)
+
+// newRateLimiter creates limiter which allows up to limit events per key in each interval.
+// Zero or negative limit disables limiting at all.
+func newRateLimiter(limit int, interval time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:    limit,
+		interval: interval,
+		timeNow:  time.Now,
+		counts:   make(map[string]int),
+	}
+}
+
+// rateLimiter counts events in fixed time windows. All counters are dropped when window passes,
+// so memory usage is bounded by amount of distinct keys seen during single window.
+type rateLimiter struct {
+	limit    int
+	interval time.Duration
+	timeNow  func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// Allow registers event for given key and reports if it fits into the limit
+func (limiter *rateLimiter) Allow(key string) bool {
+	if limiter == nil || limiter.limit <= 0 {
+		return true
+	}
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := limiter.timeNow()
+	if now.Sub(limiter.windowStart) >= limiter.interval {
+		limiter.windowStart = now
+		limiter.counts = make(map[string]int)
+	}
+
+	if limiter.counts[key] >= limiter.limit {
+		return false
+	}
+	limiter.counts[key]++
+	return true
+}
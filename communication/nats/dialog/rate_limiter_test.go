@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dialog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_AllowsUpToLimitPerKey(t *testing.T) {
+	limiter := newRateLimiter(2, time.Minute)
+	limiter.timeNow = func() time.Time { return time.Unix(100, 0) }
+
+	assert.True(t, limiter.Allow("peer1"))
+	assert.True(t, limiter.Allow("peer1"))
+	assert.False(t, limiter.Allow("peer1"))
+
+	assert.True(t, limiter.Allow("peer2"))
+}
+
+func TestRateLimiter_ResetsCountersAfterInterval(t *testing.T) {
+	now := time.Unix(100, 0)
+	limiter := newRateLimiter(1, time.Minute)
+	limiter.timeNow = func() time.Time { return now }
+
+	assert.True(t, limiter.Allow("peer1"))
+	assert.False(t, limiter.Allow("peer1"))
+
+	now = now.Add(time.Minute)
+	assert.True(t, limiter.Allow("peer1"))
+	assert.Len(t, limiter.counts, 1)
+}
+
+func TestRateLimiter_DisabledWhenLimitNotSet(t *testing.T) {
+	limiter := newRateLimiter(0, time.Minute)
+	for i := 0; i < 10; i++ {
+		assert.True(t, limiter.Allow("peer1"))
+	}
+
+	var nilLimiter *rateLimiter
+	assert.True(t, nilLimiter.Allow("peer1"))
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dialog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+// newRegistrationCache wraps identity registry and remembers its answers for a given time
+func newRegistrationCache(identityRegistry registry.IdentityRegistry, ttl time.Duration) *registrationCache {
+	return &registrationCache{
+		identityRegistry: identityRegistry,
+		ttl:              ttl,
+		timeNow:          time.Now,
+		entries:          make(map[string]registrationCacheEntry),
+	}
+}
+
+type registrationCacheEntry struct {
+	registered bool
+	expiresAt  time.Time
+}
+
+type registrationCache struct {
+	identityRegistry registry.IdentityRegistry
+	ttl              time.Duration
+	timeNow          func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]registrationCacheEntry
+}
+
+// IsRegistered returns cached registration status of identity or asks registry when there is none
+func (cache *registrationCache) IsRegistered(id identity.Identity) (bool, error) {
+	now := cache.timeNow()
+
+	cache.mu.Lock()
+	entry, found := cache.entries[id.Address]
+	cache.mu.Unlock()
+	if found && now.Before(entry.expiresAt) {
+		return entry.registered, nil
+	}
+
+	registered, err := cache.identityRegistry.IsRegistered(id)
+	if err != nil {
+		return false, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for address, entry := range cache.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(cache.entries, address)
+		}
+	}
+	cache.entries[id.Address] = registrationCacheEntry{
+		registered: registered,
+		expiresAt:  now.Add(cache.ttl),
+	}
+
+	return registered, nil
+}
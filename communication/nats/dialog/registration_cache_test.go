@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dialog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrationCache_RemembersAnswerUntilExpired(t *testing.T) {
+	now := time.Unix(100, 0)
+	identityRegistry := &countingIdentityRegistry{registered: true}
+	cache := newRegistrationCache(identityRegistry, time.Minute)
+	cache.timeNow = func() time.Time { return now }
+
+	id := identity.FromAddress("0x1")
+	for i := 0; i < 3; i++ {
+		registered, err := cache.IsRegistered(id)
+		assert.NoError(t, err)
+		assert.True(t, registered)
+	}
+	assert.Equal(t, 1, identityRegistry.calls)
+
+	now = now.Add(time.Minute)
+	registered, err := cache.IsRegistered(id)
+	assert.NoError(t, err)
+	assert.True(t, registered)
+	assert.Equal(t, 2, identityRegistry.calls)
+}
+
+func TestRegistrationCache_DoesNotRememberErrors(t *testing.T) {
+	identityRegistry := &countingIdentityRegistry{err: errors.New("rpc failed")}
+	cache := newRegistrationCache(identityRegistry, time.Minute)
+
+	_, err := cache.IsRegistered(identity.FromAddress("0x1"))
+	assert.EqualError(t, err, "rpc failed")
+	_, err = cache.IsRegistered(identity.FromAddress("0x1"))
+	assert.EqualError(t, err, "rpc failed")
+	assert.Equal(t, 2, identityRegistry.calls)
+	assert.Len(t, cache.entries, 0)
+}
+
+type countingIdentityRegistry struct {
+	registered bool
+	err        error
+	calls      int
+}
+
+func (cir *countingIdentityRegistry) IsRegistered(id identity.Identity) (bool, error) {
+	cir.calls++
+	return cir.registered, cir.err
+}
+
+func (cir *countingIdentityRegistry) SubscribeToRegistrationEvent(id identity.Identity) (
+	registeredEvent chan registry.RegistrationEvent,
+	unsubscribe func(),
+) {
+	return nil, nil
+}
@@ -18,16 +18,21 @@
 package discovery
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 
+	log "github.com/cihub/seelog"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	nats_lib "github.com/nats-io/go-nats"
 )
 
+const addressLogPrefix = "[NATS.Address] "
+
 // NewAddress creates NATS address to known host or cluster of hosts
 func NewAddress(topic string, addresses ...string) *AddressNATS {
 	return &AddressNATS{
@@ -36,8 +41,33 @@ func NewAddress(topic string, addresses ...string) *AddressNATS {
 	}
 }
 
-// NewAddressFromHostAndID generates NATS address for current node
-func NewAddressFromHostAndID(uri string, myID identity.Identity, serviceType string) (*AddressNATS, error) {
+// NewAddressFromHostAndID generates NATS address for current node, reachable via any of given brokers
+func NewAddressFromHostAndID(uris []string, myID identity.Identity, serviceType string) (*AddressNATS, error) {
+	topic := fmt.Sprintf("%v.%v", myID.Address, serviceType)
+	return NewAddressForBrokers(uris, topic)
+}
+
+// NewAddressForBrokers creates NATS address for given topic, reachable via any of given brokers
+func NewAddressForBrokers(uris []string, topic string) (*AddressNATS, error) {
+	if len(uris) == 0 {
+		return nil, errors.New("no broker addresses given")
+	}
+
+	servers := make([]string, 0, len(uris))
+	for _, uri := range uris {
+		server, err := normalizeBrokerURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, server)
+	}
+
+	return NewAddress(topic, servers...), nil
+}
+
+func normalizeBrokerURI(uri string) (string, error) {
+	uri = strings.TrimSpace(uri)
+
 	// Add scheme first otherwise url.Parse() fails.
 	var rawurl string
 	if strings.HasPrefix(uri, "nats:") {
@@ -48,15 +78,14 @@ func NewAddressFromHostAndID(uri string, myID identity.Identity, serviceType str
 
 	url, err := url.Parse(rawurl)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	if url.Port() == "" {
 		url.Host = fmt.Sprintf("%s:%d", url.Host, BrokerPort)
 	}
 
-	topic := fmt.Sprintf("%v.%v", myID.Address, serviceType)
-	return NewAddress(topic, url.String()), nil
+	return url.String(), nil
 }
 
 // NewAddressForContact extracts NATS address from given contact structure
@@ -90,22 +119,44 @@ type AddressNATS struct {
 	topic   string
 
 	connection nats.Connection
+
+	stateLock       sync.RWMutex
+	state           BrokerState
+	connectedServer string
+	closed          bool
 }
 
-// Connect establishes connection to broker
+// Connect establishes connection to one of the brokers.
+// Connection fails over to other brokers, when currently connected one goes down.
 func (address *AddressNATS) Connect() (err error) {
 	options := nats_lib.GetDefaultOptions()
 	options.Servers = address.servers
 	options.MaxReconnect = BrokerMaxReconnect
 	options.ReconnectWait = BrokerReconnectWait
 	options.Timeout = BrokerTimeout
+	options.DisconnectedCB = func(conn *nats_lib.Conn) {
+		log.Warn(addressLogPrefix, "Disconnected from broker: ", conn.ConnectedUrl())
+		address.setState(BrokerStateReconnecting, "")
+	}
+	options.ReconnectedCB = func(conn *nats_lib.Conn) {
+		log.Info(addressLogPrefix, "Reconnected to broker: ", conn.ConnectedUrl())
+		address.setState(BrokerStateConnected, conn.ConnectedUrl())
+	}
+	options.ClosedCB = func(conn *nats_lib.Conn) {
+		address.setState(BrokerStateDisconnected, "")
+	}
 
-	address.connection, err = options.Connect()
+	connection, err := options.Connect()
 	if err != nil {
 		address.connection = nil
+		address.setState(BrokerStateDisconnected, "")
+		return err
 	}
 
-	return
+	address.connection = connection
+	address.setState(BrokerStateConnected, connection.ConnectedUrl())
+	log.Info(addressLogPrefix, "Connected to broker: ", connection.ConnectedUrl())
+	return nil
 }
 
 // Disconnect stops currently established connection
@@ -113,6 +164,44 @@ func (address *AddressNATS) Disconnect() {
 	if address.connection != nil {
 		address.connection.Close()
 	}
+	address.setState(BrokerStateDisconnected, "")
+
+	address.stateLock.Lock()
+	address.closed = true
+	address.stateLock.Unlock()
+}
+
+// GetStatus returns current state of connection to brokers
+func (address *AddressNATS) GetStatus() BrokerStatus {
+	address.stateLock.RLock()
+	defer address.stateLock.RUnlock()
+
+	state := address.state
+	if state == "" {
+		state = BrokerStateDisconnected
+	}
+
+	return BrokerStatus{
+		Topic:           address.topic,
+		Servers:         address.servers,
+		State:           state,
+		ConnectedServer: address.connectedServer,
+	}
+}
+
+func (address *AddressNATS) isClosed() bool {
+	address.stateLock.RLock()
+	defer address.stateLock.RUnlock()
+
+	return address.closed
+}
+
+func (address *AddressNATS) setState(state BrokerState, connectedServer string) {
+	address.stateLock.Lock()
+	defer address.stateLock.Unlock()
+
+	address.state = state
+	address.connectedServer = connectedServer
 }
 
 // GetConnection returns currently established connection
@@ -55,7 +55,7 @@ func TestNewAddressFromHostAndID(t *testing.T) {
 
 	myID := identity.FromAddress("provider1")
 	for _, tc := range tests {
-		address, err := NewAddressFromHostAndID(tc.uri, myID, "noop")
+		address, err := NewAddressFromHostAndID([]string{tc.uri}, myID, "noop")
 		assert.NoError(t, err)
 		assert.Equal(
 			t,
@@ -69,6 +69,46 @@ func TestNewAddressFromHostAndID(t *testing.T) {
 	}
 }
 
+func TestNewAddressFromHostAndID_MultipleBrokers(t *testing.T) {
+	address, err := NewAddressFromHostAndID([]string{"127.0.0.1", "nats://example.com:4333"}, identity.FromAddress("provider1"), "noop")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&AddressNATS{
+			servers: []string{"nats://127.0.0.1:4222", "nats://example.com:4333"},
+			topic:   "provider1.noop",
+		},
+		address,
+	)
+	assert.Equal(
+		t,
+		ContactNATSV1{
+			Topic:           "provider1.noop",
+			BrokerAddresses: []string{"nats://127.0.0.1:4222", "nats://example.com:4333"},
+		},
+		address.GetContact().Definition,
+	)
+}
+
+func TestNewAddressFromHostAndID_NoBrokers(t *testing.T) {
+	address, err := NewAddressFromHostAndID([]string{}, identity.FromAddress("provider1"), "noop")
+	assert.EqualError(t, err, "no broker addresses given")
+	assert.Nil(t, address)
+}
+
+func TestNewAddressForBrokers(t *testing.T) {
+	address, err := NewAddressForBrokers([]string{"127.0.0.1", "nats://127.0.0.2:4333"}, "proposals")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		&AddressNATS{
+			servers: []string{"nats://127.0.0.1:4222", "nats://127.0.0.2:4333"},
+			topic:   "proposals",
+		},
+		address,
+	)
+}
+
 func TestNewAddressForContact(t *testing.T) {
 	address, err := NewAddressForContact(market.Contact{
 		Type: "nats/v1",
@@ -121,9 +161,27 @@ func TestAddress_Disconnect_AfterFailedConnect(t *testing.T) {
 	}
 
 	assert.EqualError(t, address.Connect(), "nats: no servers available for connection")
+	assert.Equal(t, BrokerStateDisconnected, address.GetStatus().State)
 	address.Disconnect()
 }
 
+func TestAddress_GetStatus(t *testing.T) {
+	address := NewAddress("123456", "nats://far-server:4222", "nats://other-server:4222")
+	assert.Equal(
+		t,
+		BrokerStatus{
+			Topic:   "123456",
+			Servers: []string{"nats://far-server:4222", "nats://other-server:4222"},
+			State:   BrokerStateDisconnected,
+		},
+		address.GetStatus(),
+	)
+
+	address.setState(BrokerStateConnected, "nats://other-server:4222")
+	assert.Equal(t, BrokerStateConnected, address.GetStatus().State)
+	assert.Equal(t, "nats://other-server:4222", address.GetStatus().ConnectedServer)
+}
+
 func TestAddress_GetConnection(t *testing.T) {
 	expectedConnectin := &nats.Conn{}
 	address := &AddressNATS{connection: expectedConnectin}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import "sync"
+
+// BrokerState represents state of connection to brokers
+type BrokerState string
+
+const (
+	// BrokerStateConnected means that connection to one of the brokers is established
+	BrokerStateConnected = BrokerState("Connected")
+	// BrokerStateReconnecting means that connection was lost and other brokers are being tried
+	BrokerStateReconnecting = BrokerState("Reconnecting")
+	// BrokerStateDisconnected means that there is no connection and it won't be retried
+	BrokerStateDisconnected = BrokerState("Disconnected")
+)
+
+// BrokerStatus describes connection state of single NATS address
+type BrokerStatus struct {
+	Topic           string
+	Servers         []string
+	State           BrokerState
+	ConnectedServer string
+}
+
+// NewAddressTracker constructs tracker of NATS addresses used by node
+func NewAddressTracker() *AddressTracker {
+	return &AddressTracker{}
+}
+
+// AddressTracker keeps NATS addresses in use, so that their connection states could be reported
+type AddressTracker struct {
+	lock      sync.Mutex
+	addresses []*AddressNATS
+}
+
+// Track starts reporting state of given address until it is disconnected on purpose
+func (tracker *AddressTracker) Track(address *AddressNATS) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	tracker.addresses = append(tracker.addresses, address)
+}
+
+// Status returns connection states of tracked addresses.
+// Addresses which were disconnected are forgotten.
+func (tracker *AddressTracker) Status() []BrokerStatus {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	statuses := make([]BrokerStatus, 0, len(tracker.addresses))
+	active := tracker.addresses[:0]
+	for _, address := range tracker.addresses {
+		if address.isClosed() {
+			continue
+		}
+
+		active = append(active, address)
+		statuses = append(statuses, address.GetStatus())
+	}
+	tracker.addresses = active
+
+	return statuses
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressTracker_Status(t *testing.T) {
+	connected := NewAddress("topic1", "nats://far-server:4222")
+	connected.setState(BrokerStateConnected, "nats://far-server:4222")
+	notConnected := NewAddress("topic2", "nats://far-server:4222")
+
+	tracker := NewAddressTracker()
+	tracker.Track(connected)
+	tracker.Track(notConnected)
+
+	assert.Equal(
+		t,
+		[]BrokerStatus{
+			{
+				Topic:           "topic1",
+				Servers:         []string{"nats://far-server:4222"},
+				State:           BrokerStateConnected,
+				ConnectedServer: "nats://far-server:4222",
+			},
+			{
+				Topic:   "topic2",
+				Servers: []string{"nats://far-server:4222"},
+				State:   BrokerStateDisconnected,
+			},
+		},
+		tracker.Status(),
+	)
+}
+
+func TestAddressTracker_ForgetsDisconnectedAddresses(t *testing.T) {
+	address := NewAddress("topic1", "nats://far-server:4222")
+
+	tracker := NewAddressTracker()
+	tracker.Track(address)
+	assert.Len(t, tracker.Status(), 1)
+
+	address.Disconnect()
+	assert.Len(t, tracker.Status(), 0)
+	assert.Len(t, tracker.addresses, 0)
+}
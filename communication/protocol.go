@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package communication
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProtocolVersion is the version of session protocol spoken by this node
+const ProtocolVersion = 2
+
+// MinProtocolVersion is the oldest version of session protocol this node is still compatible with
+const MinProtocolVersion = 1
+
+// ErrIncompatibleProtocol indicates that peers have no common protocol version or lack required capabilities
+var ErrIncompatibleProtocol = errors.New("incompatible protocol")
+
+// Capability represents optional feature of the session protocol
+type Capability string
+
+const (
+	// CapabilityPayments is support of promise based payments for the session
+	CapabilityPayments = Capability("payments")
+	// CapabilityCodecJSON is support of JSON codec for dialog messages
+	CapabilityCodecJSON = Capability("codec-json")
+)
+
+// ProtocolInfo describes protocol versions and capabilities which peer supports
+type ProtocolInfo struct {
+	Version      int          `json:"version"`
+	MinVersion   int          `json:"min_version"`
+	Capabilities []Capability `json:"capabilities,omitempty"`
+	// Required lists capabilities which other peer must support to communicate
+	Required []Capability `json:"required,omitempty"`
+}
+
+// NewProtocolInfo returns protocol info of current node with given capabilities
+func NewProtocolInfo(capabilities ...Capability) ProtocolInfo {
+	return ProtocolInfo{
+		Version:      ProtocolVersion,
+		MinVersion:   MinProtocolVersion,
+		Capabilities: capabilities,
+	}
+}
+
+// LegacyProtocolInfo returns protocol info assumed for peers which do not announce it
+func LegacyProtocolInfo() ProtocolInfo {
+	return ProtocolInfo{
+		Version:      1,
+		MinVersion:   1,
+		Capabilities: []Capability{CapabilityPayments, CapabilityCodecJSON},
+	}
+}
+
+// ProtocolInfoOrLegacy returns given protocol info or legacy one if peer did not announce any
+func ProtocolInfoOrLegacy(info *ProtocolInfo) ProtocolInfo {
+	if info == nil {
+		return LegacyProtocolInfo()
+	}
+	return *info
+}
+
+// Require marks given capabilities as mandatory for other peer
+func (info ProtocolInfo) Require(capabilities ...Capability) ProtocolInfo {
+	info.Required = append(append([]Capability{}, info.Required...), capabilities...)
+	return info
+}
+
+// Supports checks if given capability is supported
+func (info ProtocolInfo) Supports(capability Capability) bool {
+	for _, supported := range info.Capabilities {
+		if supported == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate finds highest common protocol version and common subset of capabilities with a peer
+func (info ProtocolInfo) Negotiate(peer ProtocolInfo) (ProtocolInfo, error) {
+	version := info.Version
+	if peer.Version < version {
+		version = peer.Version
+	}
+	if version < info.MinVersion || version < peer.MinVersion {
+		return ProtocolInfo{}, fmt.Errorf(
+			"%v: supported versions %d-%d, peer versions %d-%d",
+			ErrIncompatibleProtocol, info.MinVersion, info.Version, peer.MinVersion, peer.Version,
+		)
+	}
+
+	for _, required := range info.Required {
+		if !peer.Supports(required) {
+			return ProtocolInfo{}, fmt.Errorf("%v: peer does not support %s", ErrIncompatibleProtocol, required)
+		}
+	}
+	for _, required := range peer.Required {
+		if !info.Supports(required) {
+			return ProtocolInfo{}, fmt.Errorf("%v: %s is not supported, but required by peer", ErrIncompatibleProtocol, required)
+		}
+	}
+
+	common := ProtocolInfo{
+		Version:    version,
+		MinVersion: version,
+	}
+	common.Required = append(common.Required, info.Required...)
+	common.Required = append(common.Required, peer.Required...)
+	for _, capability := range info.Capabilities {
+		if peer.Supports(capability) {
+			common.Capabilities = append(common.Capabilities, capability)
+		}
+	}
+
+	return common, nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package communication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtocolInfo_NegotiateCommonSubset(t *testing.T) {
+	local := NewProtocolInfo(CapabilityPayments, CapabilityCodecJSON)
+	peer := ProtocolInfo{
+		Version:      3,
+		MinVersion:   2,
+		Capabilities: []Capability{CapabilityCodecJSON, Capability("unknown")},
+	}
+
+	common, err := local.Negotiate(peer)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		ProtocolInfo{
+			Version:      ProtocolVersion,
+			MinVersion:   ProtocolVersion,
+			Capabilities: []Capability{CapabilityCodecJSON},
+		},
+		common,
+	)
+	assert.False(t, common.Supports(CapabilityPayments))
+}
+
+func TestProtocolInfo_NegotiateFailsWithoutCommonVersion(t *testing.T) {
+	local := NewProtocolInfo()
+	peer := ProtocolInfo{Version: 5, MinVersion: 4}
+
+	_, err := local.Negotiate(peer)
+	assert.EqualError(t, err, "incompatible protocol: supported versions 1-2, peer versions 4-5")
+}
+
+func TestProtocolInfo_NegotiateFailsWithoutRequiredCapability(t *testing.T) {
+	local := NewProtocolInfo(CapabilityPayments).Require(CapabilityPayments)
+	peer := NewProtocolInfo(CapabilityCodecJSON)
+
+	_, err := local.Negotiate(peer)
+	assert.EqualError(t, err, "incompatible protocol: peer does not support payments")
+
+	_, err = peer.Negotiate(local)
+	assert.EqualError(t, err, "incompatible protocol: payments is not supported, but required by peer")
+}
+
+func TestProtocolInfo_LegacyPeer(t *testing.T) {
+	local := NewProtocolInfo(CapabilityPayments, CapabilityCodecJSON).Require(CapabilityPayments)
+
+	common, err := local.Negotiate(ProtocolInfoOrLegacy(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, common.Version)
+	assert.True(t, common.Supports(CapabilityPayments))
+}
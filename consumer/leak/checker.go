@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leak
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+const logPrefix = "[leak-check] "
+
+// CheckEventTopic represents the topic of finished leak checks
+const CheckEventTopic = "LeakCheck"
+
+// Publisher is responsible for publishing given events
+type Publisher interface {
+	Publish(topic string, args ...interface{})
+}
+
+// Result describes outcome of the leak check of a single connection
+type Result struct {
+	SessionID session.ID
+	CheckedAt time.Time
+
+	OriginalIP      string
+	ExitIP          string
+	ExitCountry     string
+	ExpectedCountry string
+
+	// IPLeak is set when traffic exits with the same IP as without connection
+	IPLeak bool
+	// CountryMismatch is set when traffic exits in a different country than provider advertises
+	CountryMismatch bool
+	// DNSChecked is set when DNS resolvers were checked
+	DNSChecked bool
+	// DNSLeak is set when DNS queries are resolved by the same resolvers as without connection
+	DNSLeak bool
+
+	// Error is set when exit location could not be detected
+	Error string
+}
+
+// Leaking tells if traffic or DNS queries were detected outside of the tunnel
+func (result Result) Leaking() bool {
+	return result.IPLeak || result.DNSLeak
+}
+
+// Checker verifies that traffic of established connection exits through the provider
+type Checker struct {
+	locationDetector location.Detector
+	originalLocation location.Cache
+	dnsDetector      DNSDetector
+	publisher        Publisher
+	timeNow          func() time.Time
+
+	lock          sync.Mutex
+	activeSession session.ID
+	baselineDNS   []string
+	result        *Result
+}
+
+// NewChecker creates leak checker, DNS resolvers are not checked when dnsDetector is nil
+func NewChecker(
+	locationDetector location.Detector,
+	originalLocation location.Cache,
+	dnsDetector DNSDetector,
+	publisher Publisher,
+) *Checker {
+	return &Checker{
+		locationDetector: locationDetector,
+		originalLocation: originalLocation,
+		dnsDetector:      dnsDetector,
+		publisher:        publisher,
+		timeNow:          time.Now,
+	}
+}
+
+// ConsumeSessionEvent detects DNS resolvers used without connection.
+// Session is created before the tunnel is started, so detection is done synchronously to finish before it.
+func (checker *Checker) ConsumeSessionEvent(event connection.SessionEvent) {
+	if event.Status == connection.SessionCreatedStatus {
+		checker.detectBaselineDNS()
+	}
+}
+
+// ConsumeStateEvent starts leak check when connection gets established
+func (checker *Checker) ConsumeStateEvent(event connection.StateEvent) {
+	switch event.State {
+	case connection.Connected:
+		checker.lock.Lock()
+		checker.activeSession = event.SessionInfo.SessionID
+		checker.result = nil
+		checker.lock.Unlock()
+		go checker.check(event.SessionInfo)
+	case connection.NotConnected:
+		checker.lock.Lock()
+		checker.activeSession = ""
+		checker.result = nil
+		checker.lock.Unlock()
+	}
+}
+
+// Result returns result of the leak check of given session, if the check has finished
+func (checker *Checker) Result(sessionID session.ID) (Result, bool) {
+	checker.lock.Lock()
+	defer checker.lock.Unlock()
+
+	if checker.result == nil || checker.result.SessionID != sessionID {
+		return Result{}, false
+	}
+	return *checker.result, true
+}
+
+// Check verifies exit IP and location of given connection, publishes and returns the result
+func (checker *Checker) Check(sessionInfo connection.SessionInfo) Result {
+	result := Result{
+		SessionID:       sessionInfo.SessionID,
+		CheckedAt:       checker.timeNow(),
+		OriginalIP:      checker.originalLocation.Get().IP,
+		ExpectedCountry: expectedCountry(sessionInfo),
+	}
+
+	exitLocation, err := checker.locationDetector.DetectLocation()
+	if err != nil {
+		log.Warn(logPrefix, "failed to detect exit location: ", err)
+		result.Error = err.Error()
+	} else {
+		result.ExitIP = exitLocation.IP
+		result.ExitCountry = exitLocation.Country
+		result.IPLeak = result.OriginalIP != "" && result.ExitIP == result.OriginalIP
+		result.CountryMismatch = result.ExpectedCountry != "" && result.ExitCountry != "" &&
+			!strings.EqualFold(result.ExpectedCountry, result.ExitCountry)
+	}
+
+	if checker.dnsDetector != nil {
+		resolvers, err := checker.dnsDetector.ResolverIPs()
+		if err != nil {
+			log.Warn(logPrefix, "failed to detect DNS resolvers: ", err)
+		} else {
+			checker.lock.Lock()
+			baseline := checker.baselineDNS
+			checker.lock.Unlock()
+
+			result.DNSChecked = true
+			result.DNSLeak = containsAny(resolvers, baseline) || containsAny(resolvers, []string{result.OriginalIP})
+		}
+	}
+
+	if result.Leaking() {
+		log.Warn(logPrefix, "traffic of session ", result.SessionID, " is leaking: ", result)
+	} else {
+		log.Info(logPrefix, "session ", result.SessionID, " exits via ", result.ExitIP, " (", result.ExitCountry, ")")
+	}
+	checker.publisher.Publish(CheckEventTopic, result)
+	return result
+}
+
+func (checker *Checker) check(sessionInfo connection.SessionInfo) {
+	result := checker.Check(sessionInfo)
+
+	checker.lock.Lock()
+	defer checker.lock.Unlock()
+	if checker.activeSession == result.SessionID {
+		checker.result = &result
+	}
+}
+
+func (checker *Checker) detectBaselineDNS() {
+	if checker.dnsDetector == nil {
+		return
+	}
+
+	resolvers, err := checker.dnsDetector.ResolverIPs()
+	if err != nil {
+		log.Warn(logPrefix, "failed to detect DNS resolvers before connection: ", err)
+		return
+	}
+
+	checker.lock.Lock()
+	defer checker.lock.Unlock()
+	checker.baselineDNS = resolvers
+}
+
+func expectedCountry(sessionInfo connection.SessionInfo) string {
+	if sessionInfo.Proposal.ServiceDefinition == nil {
+		return ""
+	}
+	return sessionInfo.Proposal.ServiceDefinition.GetLocation().Country
+}
+
+func containsAny(values, candidates []string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if candidate != "" && value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
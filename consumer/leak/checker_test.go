@@ -0,0 +1,193 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leak
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDetector struct {
+	location location.Location
+	err      error
+}
+
+func (detector *fakeDetector) DetectLocation() (location.Location, error) {
+	return detector.location, detector.err
+}
+
+type fakeCache struct {
+	location location.Location
+}
+
+func (cache *fakeCache) Get() location.Location {
+	return cache.location
+}
+
+func (cache *fakeCache) RefreshAndGet() (location.Location, error) {
+	return cache.location, nil
+}
+
+type fakeDNSDetector struct {
+	resolvers []string
+}
+
+func (detector *fakeDNSDetector) ResolverIPs() ([]string, error) {
+	return detector.resolvers, nil
+}
+
+type fakePublisher struct {
+	topic string
+	event interface{}
+}
+
+func (publisher *fakePublisher) Publish(topic string, args ...interface{}) {
+	publisher.topic = topic
+	publisher.event = args[0]
+}
+
+type fakeServiceDefinition struct {
+	country string
+}
+
+func (definition fakeServiceDefinition) GetLocation() market.Location {
+	return market.Location{Country: definition.country}
+}
+
+var (
+	originalLocation = &fakeCache{location: location.Location{IP: "1.1.1.1", Country: "LT"}}
+	sessionInfo      = connection.SessionInfo{
+		SessionID: "session1",
+		Proposal: market.ServiceProposal{
+			ProviderID:        "0x1",
+			ServiceDefinition: fakeServiceDefinition{country: "DE"},
+		},
+	}
+)
+
+func TestCheckPassesWhenTrafficExitsViaProvider(t *testing.T) {
+	publisher := &fakePublisher{}
+	checker := NewChecker(
+		&fakeDetector{location: location.Location{IP: "2.2.2.2", Country: "DE"}},
+		originalLocation,
+		nil,
+		publisher,
+	)
+
+	result := checker.Check(sessionInfo)
+
+	assert.False(t, result.Leaking())
+	assert.False(t, result.CountryMismatch)
+	assert.False(t, result.DNSChecked)
+	assert.Equal(t, "1.1.1.1", result.OriginalIP)
+	assert.Equal(t, "2.2.2.2", result.ExitIP)
+	assert.Equal(t, "DE", result.ExpectedCountry)
+	assert.Equal(t, CheckEventTopic, publisher.topic)
+	assert.Equal(t, result, publisher.event)
+}
+
+func TestCheckDetectsIPLeak(t *testing.T) {
+	checker := NewChecker(
+		&fakeDetector{location: location.Location{IP: "1.1.1.1", Country: "LT"}},
+		originalLocation,
+		nil,
+		&fakePublisher{},
+	)
+
+	result := checker.Check(sessionInfo)
+
+	assert.True(t, result.IPLeak)
+	assert.True(t, result.CountryMismatch)
+	assert.True(t, result.Leaking())
+}
+
+func TestCheckDetectsCountryMismatch(t *testing.T) {
+	checker := NewChecker(
+		&fakeDetector{location: location.Location{IP: "2.2.2.2", Country: "FR"}},
+		originalLocation,
+		nil,
+		&fakePublisher{},
+	)
+
+	result := checker.Check(sessionInfo)
+
+	assert.False(t, result.IPLeak)
+	assert.True(t, result.CountryMismatch)
+	assert.False(t, result.Leaking())
+}
+
+func TestCheckReportsDetectionError(t *testing.T) {
+	checker := NewChecker(&fakeDetector{err: errors.New("timeout")}, originalLocation, nil, &fakePublisher{})
+
+	result := checker.Check(sessionInfo)
+
+	assert.Equal(t, "timeout", result.Error)
+	assert.False(t, result.Leaking())
+}
+
+func TestCheckDetectsDNSLeak(t *testing.T) {
+	dnsDetector := &fakeDNSDetector{resolvers: []string{"8.8.4.4"}}
+	checker := NewChecker(
+		&fakeDetector{location: location.Location{IP: "2.2.2.2", Country: "DE"}},
+		originalLocation,
+		dnsDetector,
+		&fakePublisher{},
+	)
+	checker.ConsumeSessionEvent(connection.SessionEvent{Status: connection.SessionCreatedStatus, SessionInfo: sessionInfo})
+
+	result := checker.Check(sessionInfo)
+	assert.True(t, result.DNSChecked)
+	assert.True(t, result.DNSLeak)
+
+	dnsDetector.resolvers = []string{"9.9.9.9"}
+	result = checker.Check(sessionInfo)
+	assert.True(t, result.DNSChecked)
+	assert.False(t, result.DNSLeak)
+}
+
+func TestResultIsKeptOnlyForActiveSession(t *testing.T) {
+	checker := NewChecker(
+		&fakeDetector{location: location.Location{IP: "2.2.2.2", Country: "DE"}},
+		originalLocation,
+		nil,
+		&fakePublisher{},
+	)
+
+	checker.activeSession = sessionInfo.SessionID
+	checker.check(sessionInfo)
+
+	result, ok := checker.Result(sessionInfo.SessionID)
+	assert.True(t, ok)
+	assert.Equal(t, "2.2.2.2", result.ExitIP)
+
+	_, ok = checker.Result("other")
+	assert.False(t, ok)
+
+	checker.ConsumeStateEvent(connection.StateEvent{State: connection.NotConnected})
+	_, ok = checker.Result(sessionInfo.SessionID)
+	assert.False(t, ok)
+
+	checker.check(sessionInfo)
+	_, ok = checker.Result(sessionInfo.SessionID)
+	assert.False(t, ok)
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leak
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// DNSDetector detects public IPs of DNS resolvers which resolve queries of this host
+type DNSDetector interface {
+	ResolverIPs() ([]string, error)
+}
+
+// whoamiHostname is answered with IP address of the resolver which asked for it
+const whoamiHostname = "whoami.akamai.net"
+
+type whoamiDNSDetector struct {
+	hostname string
+	timeout  time.Duration
+}
+
+// NewDNSDetector creates DNS resolver detector which asks the special hostname
+// answered with IP address of the resolver that made the query
+func NewDNSDetector(timeout time.Duration) DNSDetector {
+	return &whoamiDNSDetector{
+		hostname: whoamiHostname,
+		timeout:  timeout,
+	}
+}
+
+func (detector *whoamiDNSDetector) ResolverIPs() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), detector.timeout)
+	defer cancel()
+
+	return net.DefaultResolver.LookupHost(ctx, detector.hostname)
+}
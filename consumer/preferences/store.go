@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preferences
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const bucketName = "provider-preferences"
+
+// List is a kind of provider list kept by consumer
+type List string
+
+const (
+	// Favorite list holds providers pinned by consumer
+	Favorite = List("favorite")
+	// Blocked list holds providers consumer never wants to see again
+	Blocked = List("blocked")
+)
+
+// ErrNotInList is returned when removing provider which is not in the list
+var ErrNotInList = errors.New("provider is not in the list")
+
+// ErrUnknownList is returned when given list is neither favorite nor blocked
+var ErrUnknownList = errors.New("unknown provider list")
+
+// Storer allows to save, remove and load provider entries
+type Storer interface {
+	Store(bucket string, object interface{}) error
+	Delete(bucket string, object interface{}) error
+	GetAllFrom(bucket string, array interface{}) error
+}
+
+// Entry describes provider added to one of the lists.
+// Provider can belong to a single list only, so blocking favorite provider removes it from favorites.
+type Entry struct {
+	ProviderID string `storm:"id"`
+	List       List
+	Added      time.Time
+}
+
+// Store keeps favorite and blocked providers of consumer
+type Store struct {
+	storage Storer
+
+	lock    sync.Mutex
+	entries map[string]Entry
+}
+
+// NewStore creates provider preferences store
+func NewStore(storage Storer) *Store {
+	return &Store{storage: storage}
+}
+
+// Add puts provider to the given list, moving it from the other list if needed
+func (store *Store) Add(list List, providerID string) error {
+	if list != Favorite && list != Blocked {
+		return ErrUnknownList
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := store.load(); err != nil {
+		return err
+	}
+
+	providerID = normalize(providerID)
+	if entry, ok := store.entries[providerID]; ok && entry.List == list {
+		return nil
+	}
+
+	entry := Entry{ProviderID: providerID, List: list, Added: time.Now().UTC()}
+	if err := store.storage.Store(bucketName, &entry); err != nil {
+		return err
+	}
+	store.entries[providerID] = entry
+	return nil
+}
+
+// Remove takes provider out of the given list
+func (store *Store) Remove(list List, providerID string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := store.load(); err != nil {
+		return err
+	}
+
+	providerID = normalize(providerID)
+	entry, ok := store.entries[providerID]
+	if !ok || entry.List != list {
+		return ErrNotInList
+	}
+
+	if err := store.storage.Delete(bucketName, &entry); err != nil {
+		return err
+	}
+	delete(store.entries, providerID)
+	return nil
+}
+
+// Entries returns providers of the given list ordered by time they were added
+func (store *Store) Entries(list List) ([]Entry, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0)
+	for _, entry := range store.entries {
+		if entry.List == list {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Added.Before(entries[j].Added)
+	})
+	return entries, nil
+}
+
+// IsFavorite tells whether provider is pinned by consumer
+func (store *Store) IsFavorite(providerID string) (bool, error) {
+	return store.inList(Favorite, providerID)
+}
+
+// IsBlocked tells whether provider is blocked by consumer
+func (store *Store) IsBlocked(providerID string) (bool, error) {
+	return store.inList(Blocked, providerID)
+}
+
+func (store *Store) inList(list List, providerID string) (bool, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if err := store.load(); err != nil {
+		return false, err
+	}
+
+	entry, ok := store.entries[normalize(providerID)]
+	return ok && entry.List == list, nil
+}
+
+// load reads stored entries on first use, must be called with lock held
+func (store *Store) load() error {
+	if store.entries != nil {
+		return nil
+	}
+
+	var entries []Entry
+	if err := store.storage.GetAllFrom(bucketName, &entries); err != nil {
+		return err
+	}
+
+	store.entries = make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		store.entries[entry.ProviderID] = entry
+	}
+	return nil
+}
+
+// normalize makes provider IDs comparable regardless of address letter case
+func normalize(providerID string) string {
+	return strings.ToLower(providerID)
+}
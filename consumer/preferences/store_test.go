@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preferences
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreAddsAndRemovesProviders(t *testing.T) {
+	storage := newStorerFake()
+	store := NewStore(storage)
+
+	assert.NoError(t, store.Add(Favorite, "0x1"))
+	assert.NoError(t, store.Add(Blocked, "0x2"))
+
+	assertInList(t, store.IsFavorite, "0x1")
+	assertNotInList(t, store.IsBlocked, "0x1")
+	assertInList(t, store.IsBlocked, "0x2")
+	assert.Len(t, storage.entries, 2)
+
+	assert.NoError(t, store.Remove(Favorite, "0x1"))
+	assertNotInList(t, store.IsFavorite, "0x1")
+	assert.Len(t, storage.entries, 1)
+}
+
+func TestStoreMovesProviderBetweenLists(t *testing.T) {
+	store := NewStore(newStorerFake())
+
+	assert.NoError(t, store.Add(Favorite, "0x1"))
+	assert.NoError(t, store.Add(Blocked, "0x1"))
+
+	assertNotInList(t, store.IsFavorite, "0x1")
+	assertInList(t, store.IsBlocked, "0x1")
+
+	favorites, err := store.Entries(Favorite)
+	assert.NoError(t, err)
+	assert.Len(t, favorites, 0)
+}
+
+func TestStoreIgnoresAddressCase(t *testing.T) {
+	store := NewStore(newStorerFake())
+
+	assert.NoError(t, store.Add(Blocked, "0xAbC"))
+	assertInList(t, store.IsBlocked, "0xabc")
+	assert.NoError(t, store.Remove(Blocked, "0xABC"))
+}
+
+func TestStoreRemoveReturnsErrorWhenNotInList(t *testing.T) {
+	store := NewStore(newStorerFake())
+	assert.NoError(t, store.Add(Favorite, "0x1"))
+
+	assert.Equal(t, ErrNotInList, store.Remove(Blocked, "0x1"))
+	assert.Equal(t, ErrNotInList, store.Remove(Favorite, "0x2"))
+}
+
+func TestStoreRejectsUnknownList(t *testing.T) {
+	store := NewStore(newStorerFake())
+	assert.Equal(t, ErrUnknownList, store.Add(List("other"), "0x1"))
+}
+
+func TestStoreLoadsStoredEntries(t *testing.T) {
+	storage := newStorerFake()
+	storage.entries["0x1"] = Entry{ProviderID: "0x1", List: Blocked}
+
+	store := NewStore(storage)
+	assertInList(t, store.IsBlocked, "0x1")
+
+	blocked, err := store.Entries(Blocked)
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{ProviderID: "0x1", List: Blocked}}, blocked)
+}
+
+func TestStoreReturnsStorageError(t *testing.T) {
+	storage := newStorerFake()
+	storage.err = errors.New("db closed")
+	store := NewStore(storage)
+
+	assert.EqualError(t, store.Add(Favorite, "0x1"), "db closed")
+	_, err := store.IsBlocked("0x1")
+	assert.EqualError(t, err, "db closed")
+}
+
+func assertInList(t *testing.T, inList func(providerID string) (bool, error), providerID string) {
+	ok, err := inList(providerID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func assertNotInList(t *testing.T, inList func(providerID string) (bool, error), providerID string) {
+	ok, err := inList(providerID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+type storerFake struct {
+	entries map[string]Entry
+	err     error
+}
+
+func newStorerFake() *storerFake {
+	return &storerFake{entries: make(map[string]Entry)}
+}
+
+func (storer *storerFake) Store(bucket string, object interface{}) error {
+	entry := object.(*Entry)
+	storer.entries[entry.ProviderID] = *entry
+	return nil
+}
+
+func (storer *storerFake) Delete(bucket string, object interface{}) error {
+	delete(storer.entries, object.(*Entry).ProviderID)
+	return nil
+}
+
+func (storer *storerFake) GetAllFrom(bucket string, array interface{}) error {
+	if storer.err != nil {
+		return storer.err
+	}
+	entries := array.(*[]Entry)
+	for _, entry := range storer.entries {
+		*entries = append(*entries, entry)
+	}
+	return nil
+}
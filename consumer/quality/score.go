@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"time"
+
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+)
+
+// referenceConnectTime is the time to connect which is scored as average
+const referenceConnectTime = 10 * time.Second
+
+// referenceThroughput is the throughput in bytes per second which is scored as average
+const referenceThroughput = 128 * 1024
+
+// Score describes provider quality as experienced by this consumer
+type Score struct {
+	// Value is the overall score from 0 (worst) to 1 (best)
+	Value          float64
+	Attempts       int
+	Failures       int
+	Drops          int
+	Sessions       int
+	AvgConnectTime time.Duration
+	// AvgThroughput is the average traffic of completed sessions in bytes per second
+	AvgThroughput uint64
+}
+
+// calculateScore combines connection success, connect time, stability and throughput into a single score.
+// Each factor is smoothed, so that providers with little history stay close to average.
+func calculateScore(record Record, sessions []consumer_session.History) Score {
+	score := Score{
+		Attempts: record.Attempts,
+		Failures: record.Failures,
+		Drops:    record.Drops,
+	}
+
+	successes := record.Attempts - record.Failures
+	successRate := (float64(successes) + 1) / (float64(record.Attempts) + 2)
+
+	speed := 0.5
+	if successes > 0 {
+		score.AvgConnectTime = record.ConnectTime / time.Duration(successes)
+		speed = 1 / (1 + score.AvgConnectTime.Seconds()/referenceConnectTime.Seconds())
+	}
+
+	stability := (float64(record.Disconnects-record.Drops) + 1) / (float64(record.Disconnects) + 2)
+
+	var bytes, seconds uint64
+	for _, session := range sessions {
+		duration := session.GetDuration()
+		if duration == 0 {
+			continue
+		}
+		score.Sessions++
+		bytes += session.DataStats.BytesReceived + session.DataStats.BytesSent
+		seconds += duration
+	}
+	throughput := 0.5
+	if seconds > 0 {
+		score.AvgThroughput = bytes / seconds
+		throughput = float64(score.AvgThroughput) / float64(score.AvgThroughput+referenceThroughput)
+	}
+
+	score.Value = 0.4*successRate + 0.2*speed + 0.2*stability + 0.2*throughput
+	return score
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateScoreWithoutHistoryIsAverage(t *testing.T) {
+	score := calculateScore(Record{}, nil)
+	assert.InDelta(t, 0.5, score.Value, 0.0001)
+}
+
+func TestCalculateScorePrefersReliableProviders(t *testing.T) {
+	reliable := calculateScore(Record{Attempts: 10, ConnectTime: 20 * time.Second, Disconnects: 10}, nil)
+	failing := calculateScore(Record{Attempts: 10, Failures: 8, ConnectTime: 20 * time.Second, Disconnects: 2, Drops: 2}, nil)
+
+	assert.True(t, reliable.Value > 0.5)
+	assert.True(t, failing.Value < 0.5)
+	assert.Equal(t, 2*time.Second, reliable.AvgConnectTime)
+	assert.Equal(t, 10*time.Second, failing.AvgConnectTime)
+}
+
+func TestCalculateScorePrefersFastConnections(t *testing.T) {
+	fast := calculateScore(Record{Attempts: 5, ConnectTime: 5 * time.Second}, nil)
+	slow := calculateScore(Record{Attempts: 5, ConnectTime: 150 * time.Second}, nil)
+
+	assert.True(t, fast.Value > slow.Value)
+}
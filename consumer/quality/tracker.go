@@ -0,0 +1,200 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/connection"
+)
+
+const (
+	logPrefix  = "[provider-quality] "
+	bucketName = "provider-quality"
+)
+
+// Storer allows to save and load provider records
+type Storer interface {
+	Store(bucket string, object interface{}) error
+	GetAllFrom(bucket string, array interface{}) error
+}
+
+// HistoryProvider returns locally stored history of consumer sessions
+type HistoryProvider interface {
+	GetAll() ([]consumer_session.History, error)
+}
+
+// Record holds connection outcomes observed locally for a single provider
+type Record struct {
+	ProviderID string `storm:"id"`
+	Attempts   int
+	Failures   int
+	// ConnectTime is the total time spent in successful connection attempts
+	ConnectTime time.Duration
+	Disconnects int
+	Drops       int
+	Updated     time.Time
+}
+
+// Tracker keeps track of connection outcomes and calculates local provider scores
+type Tracker struct {
+	storage Storer
+	history HistoryProvider
+
+	lock    sync.Mutex
+	loaded  bool
+	records map[string]*Record
+}
+
+// NewTracker creates provider quality tracker with given dependencies
+func NewTracker(storage Storer, history HistoryProvider) *Tracker {
+	return &Tracker{
+		storage: storage,
+		history: history,
+	}
+}
+
+// ConsumeAttemptEvent records outcome of the connection attempt
+func (tracker *Tracker) ConsumeAttemptEvent(event connection.AttemptEvent) {
+	tracker.update(event.Proposal.ProviderID, func(record *Record) {
+		record.Attempts++
+		if event.Error != nil {
+			record.Failures++
+		} else {
+			record.ConnectTime += event.Duration
+		}
+	})
+}
+
+// ConsumeDisconnectEvent records the reason of connection end
+func (tracker *Tracker) ConsumeDisconnectEvent(event connection.DisconnectEvent) {
+	tracker.update(event.SessionInfo.Proposal.ProviderID, func(record *Record) {
+		record.Disconnects++
+		if event.Reason == connection.DisconnectReasonDropped {
+			record.Drops++
+		}
+	})
+}
+
+// Scores returns local scores of all providers consumer has experience with, keyed by provider ID
+func (tracker *Tracker) Scores() (map[string]Score, error) {
+	sessions, err := tracker.history.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	if err := tracker.load(); err != nil {
+		return nil, err
+	}
+
+	sessionsByProvider := make(map[string][]consumer_session.History)
+	for _, session := range sessions {
+		providerID := session.ProviderID.Address
+		sessionsByProvider[providerID] = append(sessionsByProvider[providerID], session)
+	}
+
+	scores := make(map[string]Score, len(tracker.records))
+	for providerID, record := range tracker.records {
+		scores[providerID] = calculateScore(*record, sessionsByProvider[providerID])
+	}
+	return scores, nil
+}
+
+func (tracker *Tracker) update(providerID string, change func(record *Record)) {
+	if providerID == "" {
+		return
+	}
+
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	if err := tracker.load(); err != nil {
+		log.Warn(logPrefix, "failed to load provider records, keeping changes in memory: ", err)
+	}
+
+	record, ok := tracker.records[providerID]
+	if !ok {
+		record = &Record{ProviderID: providerID}
+		tracker.records[providerID] = record
+	}
+	change(record)
+	record.Updated = time.Now().UTC()
+
+	// storing before stored records are loaded would overwrite them
+	if !tracker.loaded {
+		return
+	}
+	tracker.store(record)
+}
+
+// load reads stored records until it succeeds, must be called with lock held.
+// Records changed before a successful load are merged into the stored ones.
+func (tracker *Tracker) load() error {
+	if tracker.loaded {
+		return nil
+	}
+	if tracker.records == nil {
+		tracker.records = make(map[string]*Record)
+	}
+
+	var records []Record
+	if err := tracker.storage.GetAllFrom(bucketName, &records); err != nil {
+		return err
+	}
+
+	pending := tracker.records
+	tracker.records = make(map[string]*Record, len(records)+len(pending))
+	for i := range records {
+		tracker.records[records[i].ProviderID] = &records[i]
+	}
+	tracker.loaded = true
+
+	for providerID, change := range pending {
+		record, ok := tracker.records[providerID]
+		if !ok {
+			record = &Record{ProviderID: providerID}
+			tracker.records[providerID] = record
+		}
+		record.add(*change)
+		tracker.store(record)
+	}
+	return nil
+}
+
+func (tracker *Tracker) store(record *Record) {
+	if err := tracker.storage.Store(bucketName, record); err != nil {
+		log.Error(logPrefix, "failed to store provider record: ", err)
+	}
+}
+
+func (record *Record) add(other Record) {
+	record.Attempts += other.Attempts
+	record.Failures += other.Failures
+	record.ConnectTime += other.ConnectTime
+	record.Disconnects += other.Disconnects
+	record.Drops += other.Drops
+	if other.Updated.After(record.Updated) {
+		record.Updated = other.Updated
+	}
+}
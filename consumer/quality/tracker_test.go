@@ -0,0 +1,190 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer"
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+var providerProposal = market.ServiceProposal{ProviderID: "0x1", ServiceType: "openvpn"}
+
+func TestTrackerRecordsAttempts(t *testing.T) {
+	storage := &storerFake{}
+	tracker := NewTracker(storage, &historyFake{})
+
+	tracker.ConsumeAttemptEvent(connection.AttemptEvent{Proposal: providerProposal, Duration: 2 * time.Second})
+	tracker.ConsumeAttemptEvent(connection.AttemptEvent{Proposal: providerProposal, Duration: 4 * time.Second})
+	tracker.ConsumeAttemptEvent(connection.AttemptEvent{Proposal: providerProposal, Error: errors.New("timeout")})
+
+	scores, err := tracker.Scores()
+	assert.NoError(t, err)
+	score := scores["0x1"]
+	assert.Equal(t, 3, score.Attempts)
+	assert.Equal(t, 1, score.Failures)
+	assert.Equal(t, 3*time.Second, score.AvgConnectTime)
+
+	assert.Len(t, storage.records, 1)
+	assert.Equal(t, 3, storage.records["0x1"].Attempts)
+}
+
+func TestTrackerRecordsDrops(t *testing.T) {
+	tracker := NewTracker(&storerFake{}, &historyFake{})
+	info := connection.SessionInfo{Proposal: providerProposal}
+
+	tracker.ConsumeDisconnectEvent(connection.DisconnectEvent{SessionInfo: info, Reason: connection.DisconnectReasonUser})
+	tracker.ConsumeDisconnectEvent(connection.DisconnectEvent{SessionInfo: info, Reason: connection.DisconnectReasonDropped})
+
+	scores, err := tracker.Scores()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, scores["0x1"].Drops)
+}
+
+func TestTrackerLoadsStoredRecords(t *testing.T) {
+	storage := &storerFake{
+		records: map[string]Record{
+			"0x2": {ProviderID: "0x2", Attempts: 5, Failures: 5},
+		},
+	}
+	tracker := NewTracker(storage, &historyFake{})
+
+	tracker.ConsumeAttemptEvent(connection.AttemptEvent{Proposal: market.ServiceProposal{ProviderID: "0x2"}})
+
+	scores, err := tracker.Scores()
+	assert.NoError(t, err)
+	assert.Equal(t, 6, scores["0x2"].Attempts)
+	assert.Equal(t, 5, scores["0x2"].Failures)
+}
+
+func TestTrackerUsesSessionHistory(t *testing.T) {
+	started := time.Now()
+	history := &historyFake{
+		sessions: []consumer_session.History{
+			{
+				ProviderID: identity.FromAddress("0x1"),
+				Status:     consumer_session.SessionStatusCompleted,
+				Started:    started,
+				Updated:    started.Add(10 * time.Second),
+				DataStats:  consumer.SessionStatistics{BytesReceived: 9000, BytesSent: 1000},
+			},
+			{
+				ProviderID: identity.FromAddress("0x3"),
+				Status:     consumer_session.SessionStatusCompleted,
+				Started:    started,
+				Updated:    started.Add(10 * time.Second),
+				DataStats:  consumer.SessionStatistics{BytesReceived: 1000000},
+			},
+		},
+	}
+	tracker := NewTracker(&storerFake{}, history)
+	tracker.ConsumeAttemptEvent(connection.AttemptEvent{Proposal: providerProposal, Duration: time.Second})
+
+	scores, err := tracker.Scores()
+	assert.NoError(t, err)
+	assert.Len(t, scores, 1)
+	assert.Equal(t, 1, scores["0x1"].Sessions)
+	assert.Equal(t, uint64(1000), scores["0x1"].AvgThroughput)
+}
+
+func TestTrackerReturnsHistoryError(t *testing.T) {
+	tracker := NewTracker(&storerFake{}, &historyFake{err: errors.New("db closed")})
+
+	_, err := tracker.Scores()
+	assert.EqualError(t, err, "db closed")
+}
+
+func TestTrackerKeepsChangesInMemoryWhenLoadFails(t *testing.T) {
+	storage := &storerFake{
+		records: map[string]Record{
+			"0x1": {ProviderID: "0x1", Attempts: 5, Failures: 5},
+		},
+		loadErr: errors.New("db locked"),
+	}
+	tracker := NewTracker(storage, &historyFake{})
+
+	tracker.ConsumeAttemptEvent(connection.AttemptEvent{Proposal: providerProposal, Error: errors.New("timeout")})
+
+	_, err := tracker.Scores()
+	assert.EqualError(t, err, "db locked")
+	assert.Equal(t, 0, storage.stored)
+	assert.Equal(t, 5, storage.records["0x1"].Attempts)
+}
+
+func TestTrackerMergesChangesWhenLoadIsRetried(t *testing.T) {
+	storage := &storerFake{
+		records: map[string]Record{
+			"0x1": {ProviderID: "0x1", Attempts: 5, Failures: 5},
+		},
+		loadErr: errors.New("db locked"),
+	}
+	tracker := NewTracker(storage, &historyFake{})
+
+	tracker.ConsumeAttemptEvent(connection.AttemptEvent{Proposal: providerProposal, Error: errors.New("timeout")})
+	storage.loadErr = nil
+
+	scores, err := tracker.Scores()
+	assert.NoError(t, err)
+	assert.Equal(t, 6, scores["0x1"].Attempts)
+	assert.Equal(t, 6, scores["0x1"].Failures)
+	assert.Equal(t, 6, storage.records["0x1"].Attempts)
+	assert.Equal(t, 6, storage.records["0x1"].Failures)
+}
+
+type storerFake struct {
+	records map[string]Record
+	loadErr error
+	stored  int
+}
+
+func (storer *storerFake) Store(bucket string, object interface{}) error {
+	if storer.records == nil {
+		storer.records = make(map[string]Record)
+	}
+	record := object.(*Record)
+	storer.records[record.ProviderID] = *record
+	storer.stored++
+	return nil
+}
+
+func (storer *storerFake) GetAllFrom(bucket string, array interface{}) error {
+	if storer.loadErr != nil {
+		return storer.loadErr
+	}
+	records := array.(*[]Record)
+	for _, record := range storer.records {
+		*records = append(*records, record)
+	}
+	return nil
+}
+
+type historyFake struct {
+	sessions []consumer_session.History
+	err      error
+}
+
+func (history *historyFake) GetAll() ([]consumer_session.History, error) {
+	return history.sessions, history.err
+}
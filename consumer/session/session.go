@@ -47,12 +47,12 @@ func NewHistory(sessionID node_session.ID, proposal market.ServiceProposal) *His
 
 // History holds structure for saving session history
 type History struct {
-	SessionID       node_session.ID `storm:"id"`
-	ProviderID      identity.Identity
-	ServiceType     string
+	SessionID       node_session.ID   `storm:"id"`
+	ProviderID      identity.Identity `storm:"index"`
+	ServiceType     string            `storm:"index"`
 	ProviderCountry string
-	Started         time.Time
-	Status          string
+	Started         time.Time `storm:"index"`
+	Status          string    `storm:"index"`
 	Updated         time.Time
 	DataStats       consumer.SessionStatistics // is updated on disconnect event
 }
@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// indexed session history fields used to narrow down query candidates
+const (
+	fieldStarted     = "Started"
+	fieldProviderID  = "ProviderID"
+	fieldServiceType = "ServiceType"
+	fieldStatus      = "Status"
+)
+
+// time index keeps encoded timestamps, which are ordered correctly only to the precision of a second,
+// so range lookups are widened by this margin and exact bounds are applied afterwards
+const startedIndexMargin = time.Second
+
+var maxStarted = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// Query holds filtering, sorting and pagination options of session history
+type Query struct {
+	// From and To limit the start date of sessions, zero value means unbounded
+	From time.Time
+	To   time.Time
+
+	ProviderID  string
+	ServiceType string
+	Status      string
+
+	// Ascending orders sessions from the oldest to the newest, newest first otherwise
+	Ascending bool
+
+	// Page is a 1-based page number, it's only used when Limit is set
+	Page  int
+	Limit int
+}
+
+// Stats holds aggregated totals of sessions
+type Stats struct {
+	Count         int
+	BytesSent     uint64
+	BytesReceived uint64
+	// Duration is a sum of completed session durations in seconds
+	Duration      uint64
+	CountryCounts map[string]int
+}
+
+// QueryResult holds a page of sessions matching the query and totals of all matching sessions
+type QueryResult struct {
+	Sessions []History
+	Total    int
+	Stats    Stats
+}
+
+func (query Query) matches(se History) bool {
+	if !query.From.IsZero() && se.Started.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && se.Started.After(query.To) {
+		return false
+	}
+	if query.ProviderID != "" && se.ProviderID != identity.FromAddress(query.ProviderID) {
+		return false
+	}
+	if query.ServiceType != "" && se.ServiceType != query.ServiceType {
+		return false
+	}
+	if query.Status != "" && se.Status != query.Status {
+		return false
+	}
+	return true
+}
+
+// apply filters, sorts and paginates given sessions and aggregates totals of all matching ones
+func (query Query) apply(sessions []History) QueryResult {
+	filtered := make([]History, 0, len(sessions))
+	stats := Stats{CountryCounts: make(map[string]int)}
+	for _, se := range sessions {
+		if !query.matches(se) {
+			continue
+		}
+		filtered = append(filtered, se)
+		stats.add(se)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if query.Ascending {
+			return filtered[i].Started.Before(filtered[j].Started)
+		}
+		return filtered[i].Started.After(filtered[j].Started)
+	})
+
+	result := QueryResult{Sessions: filtered, Total: len(filtered), Stats: stats}
+	if query.Limit == 0 {
+		return result
+	}
+
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	// page is checked against the last one before multiplying, so that huge pages do not overflow
+	if result.Total == 0 || page-1 > (result.Total-1)/query.Limit {
+		result.Sessions = []History{}
+		return result
+	}
+	start := (page - 1) * query.Limit
+	end := result.Total
+	if query.Limit < result.Total-start {
+		end = start + query.Limit
+	}
+	result.Sessions = filtered[start:end]
+	return result
+}
+
+func (stats *Stats) add(se History) {
+	stats.Count++
+	stats.BytesSent += se.DataStats.BytesSent
+	stats.BytesReceived += se.DataStats.BytesReceived
+	stats.Duration += se.GetDuration()
+	stats.CountryCounts[se.ProviderCountry]++
+}
+
+// Query returns sessions matching the given query. The most selective indexed criteria is used to
+// load candidate sessions from the storage, the rest of criteria is applied on them.
+func (repo *Storage) Query(query Query) (QueryResult, error) {
+	var sessions []History
+	if err := repo.storage.Find(sessionStorageBucketName, query.storageQuery(), &sessions); err != nil {
+		return QueryResult{}, err
+	}
+	return query.apply(sessions), nil
+}
+
+func (query Query) storageQuery() storage.Query {
+	switch {
+	case !query.From.IsZero() || !query.To.IsZero():
+		from, to := time.Time{}, maxStarted
+		if !query.From.IsZero() {
+			from = query.From.UTC().Add(-startedIndexMargin)
+		}
+		if !query.To.IsZero() {
+			to = query.To.UTC().Add(startedIndexMargin)
+		}
+		return storage.Query{Field: fieldStarted, Min: from, Max: to}
+	case query.ProviderID != "":
+		return storage.Query{Field: fieldProviderID, Value: identity.FromAddress(query.ProviderID)}
+	case query.ServiceType != "":
+		return storage.Query{Field: fieldServiceType, Value: query.ServiceType}
+	case query.Status != "":
+		return storage.Query{Field: fieldStatus, Value: query.Status}
+	default:
+		return storage.Query{}
+	}
+}
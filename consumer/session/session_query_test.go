@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/identity"
+	node_session "github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	queryStart = time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	historyOld = History{
+		SessionID:       node_session.ID("old"),
+		ProviderID:      identity.FromAddress("0x1"),
+		ServiceType:     "openvpn",
+		ProviderCountry: "NL",
+		Started:         queryStart,
+		Updated:         queryStart.Add(time.Minute),
+		Status:          SessionStatusCompleted,
+		DataStats:       consumer.SessionStatistics{BytesSent: 10, BytesReceived: 20},
+	}
+	historyMiddle = History{
+		SessionID:       node_session.ID("middle"),
+		ProviderID:      identity.FromAddress("0x2"),
+		ServiceType:     "wireguard",
+		ProviderCountry: "LT",
+		Started:         queryStart.Add(time.Hour),
+		Updated:         queryStart.Add(time.Hour + 2*time.Minute),
+		Status:          SessionStatusCompleted,
+		DataStats:       consumer.SessionStatistics{BytesSent: 1, BytesReceived: 2},
+	}
+	historyNew = History{
+		SessionID:       node_session.ID("new"),
+		ProviderID:      identity.FromAddress("0x1"),
+		ServiceType:     "openvpn",
+		ProviderCountry: "NL",
+		Started:         queryStart.Add(2 * time.Hour),
+		Status:          SessionStatusNew,
+	}
+)
+
+func TestQueryReturnsAllSessionsNewestFirst(t *testing.T) {
+	storer := &StubSessionStorer{Sessions: []History{historyMiddle, historyOld, historyNew}}
+	repo := NewSessionStorage(storer, stubRetriever)
+
+	result, err := repo.Query(Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, storage.Query{}, storer.LastQuery)
+	assert.Equal(t, []History{historyNew, historyMiddle, historyOld}, result.Sessions)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t,
+		Stats{
+			Count:         3,
+			BytesSent:     11,
+			BytesReceived: 22,
+			Duration:      180,
+			CountryCounts: map[string]int{"NL": 2, "LT": 1},
+		},
+		result.Stats,
+	)
+}
+
+func TestQuerySortsAscending(t *testing.T) {
+	storer := &StubSessionStorer{Sessions: []History{historyMiddle, historyNew, historyOld}}
+	repo := NewSessionStorage(storer, stubRetriever)
+
+	result, err := repo.Query(Query{Ascending: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []History{historyOld, historyMiddle, historyNew}, result.Sessions)
+}
+
+func TestQueryUsesTimeIndexAndExactBounds(t *testing.T) {
+	storer := &StubSessionStorer{Sessions: []History{historyOld, historyMiddle, historyNew}}
+	repo := NewSessionStorage(storer, stubRetriever)
+
+	result, err := repo.Query(Query{
+		From:        queryStart.Add(time.Second),
+		To:          queryStart.Add(2 * time.Hour),
+		ServiceType: "openvpn",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t,
+		storage.Query{Field: fieldStarted, Min: queryStart, Max: queryStart.Add(2*time.Hour + time.Second)},
+		storer.LastQuery,
+	)
+	assert.Equal(t, []History{historyNew}, result.Sessions)
+	assert.Equal(t, 1, result.Stats.Count)
+}
+
+func TestQueryWithOpenTimeRange(t *testing.T) {
+	storer := &StubSessionStorer{}
+	repo := NewSessionStorage(storer, stubRetriever)
+
+	_, err := repo.Query(Query{From: queryStart})
+	assert.Nil(t, err)
+	assert.Equal(t, maxStarted, storer.LastQuery.Max)
+
+	_, err = repo.Query(Query{To: queryStart})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Time{}, storer.LastQuery.Min)
+}
+
+func TestQueryUsesProviderIndex(t *testing.T) {
+	storer := &StubSessionStorer{Sessions: []History{historyOld, historyNew}}
+	repo := NewSessionStorage(storer, stubRetriever)
+
+	result, err := repo.Query(Query{ProviderID: "0x1", Status: SessionStatusNew})
+	assert.Nil(t, err)
+	assert.Equal(t, storage.Query{Field: fieldProviderID, Value: identity.FromAddress("0x1")}, storer.LastQuery)
+	assert.Equal(t, []History{historyNew}, result.Sessions)
+}
+
+func TestQueryUsesStatusIndex(t *testing.T) {
+	storer := &StubSessionStorer{Sessions: []History{historyNew}}
+	repo := NewSessionStorage(storer, stubRetriever)
+
+	_, err := repo.Query(Query{Status: SessionStatusNew})
+	assert.Nil(t, err)
+	assert.Equal(t, storage.Query{Field: fieldStatus, Value: SessionStatusNew}, storer.LastQuery)
+}
+
+func TestQueryPaginatesButAggregatesAllMatches(t *testing.T) {
+	storer := &StubSessionStorer{Sessions: []History{historyOld, historyMiddle, historyNew}}
+	repo := NewSessionStorage(storer, stubRetriever)
+
+	result, err := repo.Query(Query{Page: 2, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []History{historyOld}, result.Sessions)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 3, result.Stats.Count)
+
+	result, err = repo.Query(Query{Page: 3, Limit: 2})
+	assert.Nil(t, err)
+	assert.Len(t, result.Sessions, 0)
+	assert.Equal(t, 3, result.Total)
+
+	maxInt := int(^uint(0) >> 1)
+	result, err = repo.Query(Query{Page: maxInt, Limit: maxInt})
+	assert.Nil(t, err)
+	assert.Len(t, result.Sessions, 0)
+	assert.Equal(t, 3, result.Total)
+}
+
+func TestQueryReturnsError(t *testing.T) {
+	storer := &StubSessionStorer{FindError: errMock}
+	repo := NewSessionStorage(storer, stubRetriever)
+
+	_, err := repo.Query(Query{})
+	assert.Equal(t, errMock, err)
+}
@@ -24,6 +24,7 @@ import (
 	log "github.com/cihub/seelog"
 	"github.com/mysteriumnetwork/node/consumer"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/session"
 )
 
@@ -35,11 +36,12 @@ type StatsRetriever interface {
 	Retrieve() consumer.SessionStatistics
 }
 
-// Storer allows us to get all sessions, save and update them
+// Storer allows us to query sessions, save and update them
 type Storer interface {
 	Store(bucket string, object interface{}) error
 	Update(bucket string, object interface{}) error
-	GetAllFrom(bucket string, array interface{}) error
+	Find(bucket string, query storage.Query, array interface{}) error
+	storage.Transactor
 }
 
 // Storage contains functions for storing, getting session objects
@@ -59,7 +61,7 @@ func NewSessionStorage(storage Storer, statsRetriever StatsRetriever) *Storage {
 // GetAll returns array of all sessions
 func (repo *Storage) GetAll() ([]History, error) {
 	var sessions []History
-	err := repo.storage.GetAllFrom(sessionStorageBucketName, &sessions)
+	err := repo.storage.Find(sessionStorageBucketName, storage.Query{}, &sessions)
 	if err != nil {
 		return nil, err
 	}
@@ -96,10 +98,36 @@ func (repo *Storage) handleCreatedEvent(sessionInfo connection.SessionInfo) {
 		sessionInfo.SessionID,
 		sessionInfo.Proposal,
 	)
-	err := repo.storage.Store(sessionStorageBucketName, se)
+	err := repo.storage.Transaction(func(tx storage.Tx) error {
+		if err := completeUnfinished(tx); err != nil {
+			return err
+		}
+		return tx.Store(sessionStorageBucketName, se)
+	})
 	if err != nil {
 		log.Error(sessionStorageLogPrefix, err)
 	} else {
 		log.Trace(sessionStorageLogPrefix, fmt.Sprintf("Session %v saved", sessionInfo.SessionID))
 	}
 }
+
+// completeUnfinished completes sessions which never got ended, i.e. when node was stopped during connection.
+// Only a single session is active at a time, so such sessions are left before the new one is created.
+func completeUnfinished(tx storage.Tx) error {
+	var unfinished []History
+	query := storage.Query{Field: "Status", Value: SessionStatusNew}
+	if err := tx.Find(sessionStorageBucketName, query, &unfinished); err != nil {
+		return err
+	}
+
+	for i := range unfinished {
+		se := &unfinished[i]
+		se.Status = SessionStatusCompleted
+		se.Updated = se.Started
+		if err := tx.Update(sessionStorageBucketName, se); err != nil {
+			return err
+		}
+		log.Warn(sessionStorageLogPrefix, fmt.Sprintf("Session %v was not ended, marked as completed", se.SessionID))
+	}
+	return nil
+}
@@ -20,9 +20,11 @@ package session
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/consumer"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	node_session "github.com/mysteriumnetwork/node/session"
@@ -58,18 +60,18 @@ func TestSessionStorageGetAll(t *testing.T) {
 	storage := NewSessionStorage(storer, stubRetriever)
 	sessions, err := storage.GetAll()
 	assert.Nil(t, err)
-	assert.True(t, storer.GetAllCalled)
+	assert.True(t, storer.FindCalled)
 	assert.Len(t, sessions, 0)
 }
 
 func TestSessionStorageGetAllReturnsError(t *testing.T) {
 	storer := &StubSessionStorer{
-		GetAllError: errMock,
+		FindError: errMock,
 	}
 	storage := NewSessionStorage(storer, stubRetriever)
 	sessions, err := storage.GetAll()
 	assert.NotNil(t, err)
-	assert.True(t, storer.GetAllCalled)
+	assert.True(t, storer.FindCalled)
 	assert.Nil(t, sessions)
 }
 
@@ -115,14 +117,52 @@ func TestSessionStorageConsumeEventConnectedError(t *testing.T) {
 	assert.True(t, storer.SaveCalled)
 }
 
+func TestSessionStorageConsumeEventConnectedCompletesUnfinishedSessions(t *testing.T) {
+	started := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	storer := &StubSessionStorer{
+		Sessions: []History{{SessionID: "unfinished", Started: started, Status: SessionStatusNew}},
+	}
+
+	storage := NewSessionStorage(storer, stubRetriever)
+	storage.ConsumeSessionEvent(mockPayload)
+
+	assert.Equal(t, "Status", storer.LastQuery.Field)
+	assert.Equal(t, SessionStatusNew, storer.LastQuery.Value)
+	assert.Equal(
+		t,
+		[]History{{SessionID: "unfinished", Started: started, Updated: started, Status: SessionStatusCompleted}},
+		storer.Updated,
+	)
+	assert.True(t, storer.SaveCalled)
+}
+
+func TestSessionStorageConsumeEventConnectedDoesNotSaveWhenUnfinishedSessionsFail(t *testing.T) {
+	storer := &StubSessionStorer{
+		Sessions:    []History{{SessionID: "unfinished", Status: SessionStatusNew}},
+		UpdateError: errMock,
+	}
+
+	storage := NewSessionStorage(storer, stubRetriever)
+	storage.ConsumeSessionEvent(mockPayload)
+
+	assert.True(t, storer.UpdateCalled)
+	assert.False(t, storer.SaveCalled)
+}
+
 // StubSessionStorer allows us to get all sessions, save and update them
 type StubSessionStorer struct {
 	SaveError    error
 	SaveCalled   bool
 	UpdateError  error
 	UpdateCalled bool
-	GetAllCalled bool
-	GetAllError  error
+	FindCalled   bool
+	FindError    error
+	LastQuery    storage.Query
+
+	// Sessions are returned by every lookup
+	Sessions []History
+	// Updated holds sessions passed to Update
+	Updated []History
 }
 
 func (sss *StubSessionStorer) Store(from string, object interface{}) error {
@@ -132,12 +172,35 @@ func (sss *StubSessionStorer) Store(from string, object interface{}) error {
 
 func (sss *StubSessionStorer) Update(from string, object interface{}) error {
 	sss.UpdateCalled = true
+	if se, ok := object.(*History); ok {
+		sss.Updated = append(sss.Updated, *se)
+	}
 	return sss.UpdateError
 }
 
+func (sss *StubSessionStorer) Transaction(fn func(tx storage.Tx) error) error {
+	return fn(sss)
+}
+
+func (sss *StubSessionStorer) Find(from string, query storage.Query, array interface{}) error {
+	sss.FindCalled = true
+	sss.LastQuery = query
+	if sessions, ok := array.(*[]History); ok {
+		*sessions = append(*sessions, sss.Sessions...)
+	}
+	return sss.FindError
+}
+
+func (sss *StubSessionStorer) Delete(from string, object interface{}) error {
+	return errors.New("not implemented")
+}
+
 func (sss *StubSessionStorer) GetAllFrom(from string, array interface{}) error {
-	sss.GetAllCalled = true
-	return sss.GetAllError
+	return errors.New("not implemented")
+}
+
+func (sss *StubSessionStorer) Count(from string, query storage.Query, object interface{}) (int, error) {
+	return 0, errors.New("not implemented")
 }
 
 type StubRetriever struct {
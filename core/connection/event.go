@@ -17,6 +17,12 @@
 
 package connection
 
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
 // Topic represents the different topics a consumer can subscribe to
 const (
 	// StateEventTopic represents the connection state change topic
@@ -25,6 +31,10 @@ const (
 	StatisticsEventTopic = "Statistics"
 	// SessionEventTopic represents the session event
 	SessionEventTopic = "Session"
+	// AttemptEventTopic represents the connection attempt outcome topic
+	AttemptEventTopic = "Attempt"
+	// DisconnectEventTopic represents the topic of established connection end
+	DisconnectEventTopic = "Disconnect"
 )
 
 // StateEvent is the struct we'll emit on a StateEvent topic event
@@ -45,3 +55,28 @@ type SessionEvent struct {
 	Status      string
 	SessionInfo SessionInfo
 }
+
+// AttemptEvent represents outcome of a connection attempt to the provider
+type AttemptEvent struct {
+	Proposal market.ServiceProposal
+	// Duration is the time it took to connect or to fail
+	Duration time.Duration
+	// Error is nil when connection was established
+	Error error
+}
+
+// DisconnectReason describes why established connection has ended
+type DisconnectReason string
+
+const (
+	// DisconnectReasonUser represents a connection closed by request of consumer
+	DisconnectReasonUser = DisconnectReason("User")
+	// DisconnectReasonDropped represents a connection lost without request of consumer
+	DisconnectReasonDropped = DisconnectReason("Dropped")
+)
+
+// DisconnectEvent represents the end of established connection
+type DisconnectEvent struct {
+	SessionInfo SessionInfo
+	Reason      DisconnectReason
+}
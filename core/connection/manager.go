@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	log "github.com/cihub/seelog"
 	"github.com/mysteriumnetwork/node/communication"
@@ -112,7 +113,7 @@ func (manager *connectionManager) Connect(consumerID identity.Identity, proposal
 
 	manager.mutex.Lock()
 	manager.ctx, manager.cleanConnection = context.WithCancel(context.Background())
-	manager.status = statusConnecting()
+	manager.status = statusConnecting(consumerID)
 	manager.mutex.Unlock()
 	defer func() {
 		if err != nil {
@@ -122,10 +123,17 @@ func (manager *connectionManager) Connect(consumerID identity.Identity, proposal
 		}
 	}()
 
+	started := time.Now()
 	err = manager.startConnection(consumerID, proposal, params)
 	if err == context.Canceled {
 		return ErrConnectionCancelled
 	}
+
+	manager.eventPublisher.Publish(AttemptEventTopic, AttemptEvent{
+		Proposal: proposal,
+		Duration: time.Since(started),
+		Error:    err,
+	})
 	return err
 }
 
@@ -137,7 +145,7 @@ func (manager *connectionManager) startConnection(consumerID identity.Identity,
 	var cancel []func()
 	defer func() {
 		manager.cleanConnection = func() {
-			manager.status = statusDisconnecting()
+			manager.status = statusDisconnecting(consumerID)
 			cancelCtx()
 			for i := range cancel { // Cancelling in a reverse order to keep correct workflow.
 				cancel[len(cancel)-i-1]()
@@ -181,10 +189,12 @@ func (manager *connectionManager) startConnection(consumerID identity.Identity,
 
 	go manager.payForService(payments)
 
+	protocol := dialog.Protocol()
 	consumerInfo := session.ConsumerInfo{
 		// TODO: once we're supporting payments from another identity make the changes accordingly
 		IssuerID:          consumerID,
 		MystClientVersion: metadata.VersionAsString(),
+		Protocol:          &protocol,
 	}
 
 	sessionID, sessionConfig, err := session.RequestSessionCreate(dialog, proposal.ID, sessionCreateConfig, consumerInfo)
@@ -317,6 +327,15 @@ func (manager *connectionManager) consumeConnectionStates(stateChannel <-chan St
 	manager.mutex.Lock()
 	defer manager.mutex.Unlock()
 
+	reason := DisconnectReasonDropped
+	if manager.status.State == Disconnecting {
+		reason = DisconnectReasonUser
+	}
+	manager.eventPublisher.Publish(DisconnectEventTopic, DisconnectEvent{
+		SessionInfo: manager.sessionInfo,
+		Reason:      reason,
+	})
+
 	manager.status = statusNotConnected()
 	log.Debug(managerLogPrefix, "State updater stopCalled")
 }
@@ -338,8 +357,8 @@ func (manager *connectionManager) onStateChanged(state State) {
 
 	switch state {
 	case Connected:
-		manager.status = statusConnected(manager.sessionInfo.SessionID, manager.sessionInfo.Proposal)
+		manager.status = statusConnected(manager.sessionInfo.SessionID, manager.sessionInfo.ConsumerID, manager.sessionInfo.Proposal)
 	case Reconnecting:
-		manager.status = statusReconnecting()
+		manager.status = statusReconnecting(manager.sessionInfo.ConsumerID)
 	}
 }
@@ -129,7 +129,7 @@ func (tc *testContext) TestOnConnectErrorStatusIsNotConnected() {
 func (tc *testContext) TestWhenManagerMadeConnectionStatusReturnsConnectedStateAndSessionId() {
 	err := tc.connManager.Connect(consumerID, activeProposal, ConnectParams{})
 	assert.NoError(tc.T(), err)
-	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, consumerID, activeProposal), tc.connManager.Status())
 }
 
 func (tc *testContext) TestStatusReportsConnectingWhenConnectionIsInProgress() {
@@ -141,7 +141,7 @@ func (tc *testContext) TestStatusReportsConnectingWhenConnectionIsInProgress() {
 
 	waitABit()
 
-	assert.Equal(tc.T(), statusConnecting(), tc.connManager.Status())
+	assert.Equal(tc.T(), statusConnecting(consumerID), tc.connManager.Status())
 	tc.connManager.Disconnect()
 }
 
@@ -149,10 +149,10 @@ func (tc *testContext) TestStatusReportsDisconnectingThenNotConnected() {
 	tc.fakeConnectionFactory.mockConnection.onStopReportStates = []fakeState{}
 	err := tc.connManager.Connect(consumerID, activeProposal, ConnectParams{})
 	assert.NoError(tc.T(), err)
-	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, consumerID, activeProposal), tc.connManager.Status())
 
 	assert.NoError(tc.T(), tc.connManager.Disconnect())
-	assert.Equal(tc.T(), statusDisconnecting(), tc.connManager.Status())
+	assert.Equal(tc.T(), statusDisconnecting(consumerID), tc.connManager.Status())
 	tc.fakeConnectionFactory.mockConnection.reportState(exitingState)
 	tc.fakeConnectionFactory.mockConnection.reportState(processExited)
 	waitABit()
@@ -172,12 +172,12 @@ func (tc *testContext) TestReconnectingStatusIsReportedWhenOpenVpnGoesIntoReconn
 	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
 	tc.fakeConnectionFactory.mockConnection.reportState(reconnectingState)
 	waitABit()
-	assert.Equal(tc.T(), statusReconnecting(), tc.connManager.Status())
+	assert.Equal(tc.T(), statusReconnecting(consumerID), tc.connManager.Status())
 }
 
 func (tc *testContext) TestDoubleDisconnectResultsInError() {
 	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
-	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, consumerID, activeProposal), tc.connManager.Status())
 	assert.NoError(tc.T(), tc.connManager.Disconnect())
 	waitABit()
 	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
@@ -186,13 +186,13 @@ func (tc *testContext) TestDoubleDisconnectResultsInError() {
 
 func (tc *testContext) TestTwoConnectDisconnectCyclesReturnNoError() {
 	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
-	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, consumerID, activeProposal), tc.connManager.Status())
 	assert.NoError(tc.T(), tc.connManager.Disconnect())
 	waitABit()
 	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
 
 	assert.NoError(tc.T(), tc.connManager.Connect(consumerID, activeProposal, ConnectParams{}))
-	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, consumerID, activeProposal), tc.connManager.Status())
 	assert.NoError(tc.T(), tc.connManager.Disconnect())
 	waitABit()
 	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
@@ -206,7 +206,7 @@ func (tc *testContext) TestConnectFailsIfConnectionFactoryReturnsError() {
 
 func (tc *testContext) TestStatusIsConnectedWhenConnectCommandReturnsWithoutError() {
 	tc.connManager.Connect(consumerID, activeProposal, ConnectParams{})
-	assert.Equal(tc.T(), statusConnected(establishedSessionID, activeProposal), tc.connManager.Status())
+	assert.Equal(tc.T(), statusConnected(establishedSessionID, consumerID, activeProposal), tc.connManager.Status())
 }
 
 func (tc *testContext) TestConnectingInProgressCanBeCanceled() {
@@ -220,7 +220,7 @@ func (tc *testContext) TestConnectingInProgressCanBeCanceled() {
 	}()
 
 	waitABit()
-	assert.Equal(tc.T(), statusConnecting(), tc.connManager.Status())
+	assert.Equal(tc.T(), statusConnecting(consumerID), tc.connManager.Status())
 	assert.NoError(tc.T(), tc.connManager.Disconnect())
 
 	connectWaiter.Wait()
@@ -288,6 +288,26 @@ func (tc *testContext) Test_SessionEndPublished_OnConnectError() {
 	assert.True(tc.T(), found)
 }
 
+func (tc *testContext) Test_AttemptFailurePublished_OnConnectError() {
+	tc.stubPublisher.Clear()
+
+	fatalErr := errors.New("fatal connection error")
+	tc.fakeConnectionFactory.mockConnection.onStartReturnError = fatalErr
+	err := tc.connManager.Connect(consumerID, activeProposal, ConnectParams{})
+	assert.Error(tc.T(), err)
+
+	found := false
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.calledWithTopic == AttemptEventTopic {
+			found = true
+			event := v.calledWithArgs[0].(AttemptEvent)
+			assert.Equal(tc.T(), fatalErr, event.Error)
+			assert.Equal(tc.T(), activeProposal.ProviderID, event.Proposal.ProviderID)
+		}
+	}
+	assert.True(tc.T(), found)
+}
+
 func (tc *testContext) Test_ManagerPublishesEvents() {
 	tc.stubPublisher.Clear()
 
@@ -301,7 +321,7 @@ func (tc *testContext) Test_ManagerPublishesEvents() {
 	waitABit()
 
 	history := tc.stubPublisher.GetEventHistory()
-	assert.Len(tc.T(), history, 3)
+	assert.Len(tc.T(), history, 4)
 
 	for _, v := range history {
 		if v.calledWithTopic == StatisticsEventTopic {
@@ -317,6 +337,11 @@ func (tc *testContext) Test_ManagerPublishesEvents() {
 			assert.Equal(tc.T(), activeProposal.ProviderID, event.SessionInfo.Proposal.ProviderID)
 			assert.Equal(tc.T(), activeProposal.ServiceType, event.SessionInfo.Proposal.ServiceType)
 		}
+		if v.calledWithTopic == AttemptEventTopic {
+			event := v.calledWithArgs[0].(AttemptEvent)
+			assert.NoError(tc.T(), event.Error)
+			assert.Equal(tc.T(), activeProposal.ProviderID, event.Proposal.ProviderID)
+		}
 		if v.calledWithTopic == SessionEventTopic {
 			event := v.calledWithArgs[0].(SessionEvent)
 			assert.Equal(tc.T(), SessionCreatedStatus, event.Status)
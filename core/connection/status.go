@@ -18,6 +18,7 @@
 package connection
 
 import (
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/session"
 )
@@ -40,29 +41,30 @@ const (
 	Unknown = State("Unknown")
 )
 
-// Status holds connection state, session id and proposal of the connection
+// Status holds connection state, session id, consumer and proposal of the connection
 type Status struct {
-	State     State
-	SessionID session.ID
-	Proposal  market.ServiceProposal
+	State      State
+	SessionID  session.ID
+	ConsumerID identity.Identity
+	Proposal   market.ServiceProposal
 }
 
-func statusConnecting() Status {
-	return Status{State: Connecting}
+func statusConnecting(consumerID identity.Identity) Status {
+	return Status{State: Connecting, ConsumerID: consumerID}
 }
 
-func statusConnected(sessionID session.ID, proposal market.ServiceProposal) Status {
-	return Status{Connected, sessionID, proposal}
+func statusConnected(sessionID session.ID, consumerID identity.Identity, proposal market.ServiceProposal) Status {
+	return Status{Connected, sessionID, consumerID, proposal}
 }
 
 func statusNotConnected() Status {
 	return Status{State: NotConnected}
 }
 
-func statusReconnecting() Status {
-	return Status{State: Reconnecting}
+func statusReconnecting(consumerID identity.Identity) Status {
+	return Status{State: Reconnecting, ConsumerID: consumerID}
 }
 
-func statusDisconnecting() Status {
-	return Status{State: Disconnecting}
+func statusDisconnecting(consumerID identity.Identity) Status {
+	return Status{State: Disconnecting, ConsumerID: consumerID}
 }
@@ -226,6 +226,10 @@ func (fd *fakeDialog) PeerID() identity.Identity {
 	return fd.peerID
 }
 
+func (fd *fakeDialog) Protocol() communication.ProtocolInfo {
+	return communication.NewProtocolInfo()
+}
+
 func (fd *fakeDialog) assertNotClosed() {
 	fd.RLock()
 	defer fd.RUnlock()
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+const consensusLogPrefix = "[ip-consensus] "
+
+var (
+	// ErrNoAnswers is returned when none of the sources managed to resolve IP address
+	ErrNoAnswers = errors.New("public IP was not resolved by any source")
+	// ErrNoConsensus is returned when sources disagree and no IP address has the majority of answers
+	ErrNoConsensus = errors.New("sources do not agree on public IP")
+)
+
+// ConsensusResolver queries several IP sources in parallel and resolves
+// public IP to the address returned by the majority of responding sources
+type ConsensusResolver struct {
+	sources  []Source
+	cacheTTL time.Duration
+	timeNow  func() time.Time
+
+	lock     sync.Mutex
+	cachedIP string
+	cachedAt time.Time
+}
+
+// NewConsensusResolver creates resolver which asks all given sources and caches the answer for given TTL
+func NewConsensusResolver(sources []Source, cacheTTL time.Duration) *ConsensusResolver {
+	return &ConsensusResolver{
+		sources:  sources,
+		cacheTTL: cacheTTL,
+		timeNow:  time.Now,
+	}
+}
+
+// GetPublicIP returns cached public IP or resolves it from the sources
+func (resolver *ConsensusResolver) GetPublicIP() (string, error) {
+	resolver.lock.Lock()
+	defer resolver.lock.Unlock()
+
+	if resolver.cachedIP != "" && resolver.timeNow().Sub(resolver.cachedAt) < resolver.cacheTTL {
+		return resolver.cachedIP, nil
+	}
+
+	ip, err := resolver.resolve()
+	if err != nil {
+		return "", err
+	}
+
+	log.Info(consensusLogPrefix, "IP detected: ", ip)
+	resolver.cachedIP = ip
+	resolver.cachedAt = resolver.timeNow()
+	return ip, nil
+}
+
+// GetOutboundIP returns IP address of the interface used for outgoing connections
+func (resolver *ConsensusResolver) GetOutboundIP() (string, error) {
+	return outboundIP()
+}
+
+// Invalidate forgets cached public IP, i.e. when traffic starts going through a different route
+func (resolver *ConsensusResolver) Invalidate() {
+	resolver.lock.Lock()
+	defer resolver.lock.Unlock()
+
+	resolver.cachedIP = ""
+}
+
+type sourceAnswer struct {
+	source string
+	ip     string
+	err    error
+}
+
+// resolve returns as soon as some IP address has more than half of all sources,
+// otherwise waits for all sources and votes among their answers
+func (resolver *ConsensusResolver) resolve() (string, error) {
+	answers := make(chan sourceAnswer, len(resolver.sources))
+	for _, source := range resolver.sources {
+		go func(source Source) {
+			answer := sourceAnswer{source: source.Name()}
+			answer.ip, answer.err = source.PublicIP()
+			if answer.err == nil {
+				answer.ip, answer.err = normalizeIP(answer.ip)
+			}
+			answers <- answer
+		}(source)
+	}
+
+	votes := make(map[string]int)
+	for range resolver.sources {
+		answer := <-answers
+		if answer.err != nil {
+			log.Warn(consensusLogPrefix, "source ", answer.source, " failed: ", answer.err)
+			continue
+		}
+		votes[answer.ip]++
+		if votes[answer.ip]*2 > len(resolver.sources) {
+			return answer.ip, nil
+		}
+	}
+
+	return majority(votes)
+}
+
+// majority returns the IP address which got more than half of the votes of its address family.
+// Hosts with both IPv4 and IPv6 connectivity get answers of both families, so address families
+// are voted separately and the family with more answers wins, IPv4 on a tie.
+func majority(votes map[string]int) (string, error) {
+	familyVotes := map[bool]map[string]int{true: {}, false: {}}
+	familyTotals := map[bool]int{}
+	for ip, count := range votes {
+		isIPv4 := net.ParseIP(ip).To4() != nil
+		familyVotes[isIPv4][ip] = count
+		familyTotals[isIPv4] += count
+	}
+
+	isIPv4 := familyTotals[true] >= familyTotals[false]
+	total := familyTotals[isIPv4]
+	if total == 0 {
+		return "", ErrNoAnswers
+	}
+
+	for ip, count := range familyVotes[isIPv4] {
+		if count*2 > total {
+			return ip, nil
+		}
+	}
+
+	log.Warn(consensusLogPrefix, "sources disagree: ", votes)
+	return "", ErrNoConsensus
+}
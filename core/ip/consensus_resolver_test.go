@@ -0,0 +1,218 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	ip    string
+	err   error
+	calls int
+}
+
+func (source *fakeSource) Name() string {
+	return "fake"
+}
+
+func (source *fakeSource) PublicIP() (string, error) {
+	source.calls++
+	return source.ip, source.err
+}
+
+type blockingSource struct {
+	release chan struct{}
+}
+
+func (source *blockingSource) Name() string {
+	return "blocking"
+}
+
+func (source *blockingSource) PublicIP() (string, error) {
+	<-source.release
+	return "", errors.New("timeout")
+}
+
+func newEchoServer(contentType, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", contentType)
+		fmt.Fprint(writer, body)
+	}))
+}
+
+func TestConsensusResolverReturnsMajorityAnswerOfHTTPSources(t *testing.T) {
+	jsonServer := newEchoServer("application/json", `{"ip":"1.2.3.4"}`)
+	defer jsonServer.Close()
+	plainServer := newEchoServer("text/plain", "1.2.3.4\n")
+	defer plainServer.Close()
+	otherServer := newEchoServer("text/plain", "5.6.7.8")
+	defer otherServer.Close()
+
+	sources, err := NewSources([]string{jsonServer.URL, plainServer.URL, otherServer.URL}, time.Second)
+	assert.NoError(t, err)
+
+	ip, err := NewConsensusResolver(sources, time.Minute).GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip)
+}
+
+func TestConsensusResolverIgnoresFailingSources(t *testing.T) {
+	failingServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingServer.Close()
+	garbageServer := newEchoServer("text/plain", "<html>not an ip</html>")
+	defer garbageServer.Close()
+	workingServer := newEchoServer("application/json", `{"IP":"1.2.3.4"}`)
+	defer workingServer.Close()
+
+	sources, err := NewSources([]string{failingServer.URL, garbageServer.URL, workingServer.URL}, time.Second)
+	assert.NoError(t, err)
+
+	ip, err := NewConsensusResolver(sources, time.Minute).GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip)
+}
+
+func TestConsensusResolverNormalizesIPv6Addresses(t *testing.T) {
+	fullServer := newEchoServer("text/plain", "2001:0db8:0000:0000:0000:0000:0000:0001")
+	defer fullServer.Close()
+	shortServer := newEchoServer("application/json", `{"ip":"2001:db8::1"}`)
+	defer shortServer.Close()
+
+	sources, err := NewSources([]string{fullServer.URL, shortServer.URL}, time.Second)
+	assert.NoError(t, err)
+
+	ip, err := NewConsensusResolver(sources, time.Minute).GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", ip)
+}
+
+func TestConsensusResolverFailsWithoutMajority(t *testing.T) {
+	resolver := NewConsensusResolver([]Source{
+		&fakeSource{ip: "1.1.1.1"},
+		&fakeSource{ip: "2.2.2.2"},
+		&fakeSource{err: errors.New("timeout")},
+	}, time.Minute)
+
+	_, err := resolver.GetPublicIP()
+	assert.Equal(t, ErrNoConsensus, err)
+}
+
+func TestConsensusResolverVotesSeparatelyForAddressFamilies(t *testing.T) {
+	resolver := NewConsensusResolver([]Source{
+		&fakeSource{ip: "1.2.3.4"},
+		&fakeSource{ip: "2001:db8::1"},
+		&fakeSource{ip: "1.2.3.4"},
+		&fakeSource{ip: "2001:db8::1"},
+	}, time.Minute)
+
+	ip, err := resolver.GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip)
+}
+
+func TestConsensusResolverPrefersAddressFamilyWithMoreAnswers(t *testing.T) {
+	resolver := NewConsensusResolver([]Source{
+		&fakeSource{ip: "1.2.3.4"},
+		&fakeSource{ip: "2001:db8::1"},
+		&fakeSource{ip: "2001:db8::1"},
+		&fakeSource{err: errors.New("timeout")},
+		&fakeSource{err: errors.New("refused")},
+	}, time.Minute)
+
+	ip, err := resolver.GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", ip)
+}
+
+func TestConsensusResolverDoesNotWaitForSlowSourcesOnceMajorityIsReached(t *testing.T) {
+	slowSource := &blockingSource{release: make(chan struct{})}
+	defer close(slowSource.release)
+	resolver := NewConsensusResolver([]Source{
+		&fakeSource{ip: "1.2.3.4"},
+		slowSource,
+		&fakeSource{ip: "1.2.3.4"},
+	}, time.Minute)
+
+	ip, err := resolver.GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip)
+}
+
+func TestConsensusResolverFailsWhenAllSourcesFail(t *testing.T) {
+	resolver := NewConsensusResolver([]Source{
+		&fakeSource{err: errors.New("timeout")},
+		&fakeSource{err: errors.New("refused")},
+	}, time.Minute)
+
+	_, err := resolver.GetPublicIP()
+	assert.Equal(t, ErrNoAnswers, err)
+}
+
+func TestConsensusResolverCachesAnswerForTTL(t *testing.T) {
+	source := &fakeSource{ip: "1.2.3.4"}
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	resolver := NewConsensusResolver([]Source{source}, time.Minute)
+	resolver.timeNow = func() time.Time { return now }
+
+	ip, err := resolver.GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip)
+
+	source.ip = "5.6.7.8"
+	now = now.Add(30 * time.Second)
+	ip, err = resolver.GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip)
+	assert.Equal(t, 1, source.calls)
+
+	now = now.Add(time.Minute)
+	ip, err = resolver.GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "5.6.7.8", ip)
+	assert.Equal(t, 2, source.calls)
+}
+
+func TestConsensusResolverInvalidateForgetsCachedAnswer(t *testing.T) {
+	source := &fakeSource{ip: "1.2.3.4"}
+	resolver := NewConsensusResolver([]Source{source}, time.Hour)
+
+	_, err := resolver.GetPublicIP()
+	assert.NoError(t, err)
+
+	source.ip = "5.6.7.8"
+	resolver.Invalidate()
+
+	ip, err := resolver.GetPublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "5.6.7.8", ip)
+}
+
+func TestNewSourceRejectsUnknownScheme(t *testing.T) {
+	_, err := NewSource("ftp://example.com", time.Second)
+	assert.Error(t, err)
+}
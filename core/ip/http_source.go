@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// maxResponseSize limits how much of echo service response is read
+const maxResponseSize = 1024
+
+type httpSource struct {
+	url        string
+	httpClient http.Client
+}
+
+func newHTTPSource(url string, timeout time.Duration) *httpSource {
+	return &httpSource{
+		url: url,
+		httpClient: http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				//dont cache tcp connections - first requests after state change (direct -> tunneled and vice versa) will always fail
+				DisableKeepAlives: true,
+			},
+		},
+	}
+}
+
+func (source *httpSource) Name() string {
+	return source.url
+}
+
+func (source *httpSource) PublicIP() (string, error) {
+	request, err := http.NewRequest("GET", source.url, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("User-Agent", ipifyAPIClient)
+	request.Header.Set("Accept", "application/json, text/plain")
+
+	response, err := source.httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if err := parseResponseError(response); err != nil {
+		return "", err
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	if err != nil {
+		return "", err
+	}
+
+	return parseIPBody(body)
+}
+
+// parseIPBody accepts both JSON responses with "ip" field and plain text responses
+func parseIPBody(body []byte) (string, error) {
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("{")) {
+		var ipResponse ipResponse
+		if err := json.Unmarshal(body, &ipResponse); err != nil {
+			return "", err
+		}
+		return normalizeIP(ipResponse.IP)
+	}
+	return normalizeIP(string(body))
+}
@@ -81,6 +81,10 @@ func (client *clientRest) GetPublicIP() (string, error) {
 }
 
 func (client *clientRest) GetOutboundIP() (string, error) {
+	return outboundIP()
+}
+
+func outboundIP() (string, error) {
 	conn, err := net.Dial("udp", "8.8.8.8:53")
 	if err != nil {
 		return "", err
@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Source is a single echo service which tells the IP address requests are coming from
+type Source interface {
+	Name() string
+	PublicIP() (string, error)
+}
+
+// NewSource creates IP source from given address. Addresses with http:// or https:// scheme are
+// queried over HTTP and may respond in plain text or JSON, addresses with stun: scheme are queried over STUN
+func NewSource(address string, timeout time.Duration) (Source, error) {
+	switch {
+	case strings.HasPrefix(address, "http://"), strings.HasPrefix(address, "https://"):
+		return newHTTPSource(address, timeout), nil
+	case strings.HasPrefix(address, "stun:"):
+		return newStunSource(strings.TrimPrefix(address, "stun:"), timeout), nil
+	default:
+		return nil, fmt.Errorf("unsupported IP source: %s", address)
+	}
+}
+
+// NewSources creates IP sources from given addresses
+func NewSources(addresses []string, timeout time.Duration) ([]Source, error) {
+	var sources []Source
+	for _, address := range addresses {
+		source, err := NewSource(address, timeout)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func normalizeIP(address string) (string, error) {
+	ip := net.ParseIP(strings.TrimSpace(address))
+	if ip == nil {
+		return "", fmt.Errorf("invalid IP address: %q", address)
+	}
+	return ip.String(), nil
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+)
+
+// STUN protocol constants as defined in RFC 5389
+const (
+	stunDefaultPort        = "3478"
+	stunHeaderSize         = 20
+	stunMagicCookie        = 0x2112A442
+	stunBindingRequest     = 0x0001
+	stunBindingSuccess     = 0x0101
+	stunAttrMappedAddress  = 0x0001
+	stunAttrXorMappedAddr  = 0x0020
+	stunAddressFamilyIPv4  = 0x01
+	stunAddressFamilyIPv6  = 0x02
+	stunMaxResponseSize    = 1024
+	stunTransactionIDSize  = 12
+	stunAttributeAlignment = 4
+)
+
+var (
+	errStunInvalidResponse = errors.New("invalid STUN response")
+	errStunNoAddress       = errors.New("STUN response has no mapped address")
+)
+
+type stunSource struct {
+	address string
+	timeout time.Duration
+}
+
+func newStunSource(address string, timeout time.Duration) *stunSource {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, stunDefaultPort)
+	}
+	return &stunSource{
+		address: address,
+		timeout: timeout,
+	}
+}
+
+func (source *stunSource) Name() string {
+	return "stun:" + source.address
+}
+
+func (source *stunSource) PublicIP() (string, error) {
+	conn, err := net.DialTimeout("udp", source.address, source.timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(source.timeout)); err != nil {
+		return "", err
+	}
+
+	request, err := newStunBindingRequest()
+	if err != nil {
+		return "", err
+	}
+	if _, err := conn.Write(request); err != nil {
+		return "", err
+	}
+
+	response := make([]byte, stunMaxResponseSize)
+	n, err := conn.Read(response)
+	if err != nil {
+		return "", err
+	}
+
+	ip, err := parseStunBindingResponse(response[:n], request[8:stunHeaderSize])
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
+func newStunBindingRequest() ([]byte, error) {
+	request := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(request[0:], stunBindingRequest)
+	binary.BigEndian.PutUint16(request[2:], 0)
+	binary.BigEndian.PutUint32(request[4:], stunMagicCookie)
+	if _, err := rand.Read(request[8:stunHeaderSize]); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func parseStunBindingResponse(response, transactionID []byte) (net.IP, error) {
+	if len(response) < stunHeaderSize {
+		return nil, errStunInvalidResponse
+	}
+	if binary.BigEndian.Uint16(response[0:]) != stunBindingSuccess ||
+		binary.BigEndian.Uint32(response[4:]) != stunMagicCookie ||
+		string(response[8:stunHeaderSize]) != string(transactionID) {
+		return nil, errStunInvalidResponse
+	}
+
+	length := int(binary.BigEndian.Uint16(response[2:]))
+	if stunHeaderSize+length > len(response) {
+		return nil, errStunInvalidResponse
+	}
+	attributes := response[stunHeaderSize : stunHeaderSize+length]
+
+	var mappedIP net.IP
+	for len(attributes) >= 4 {
+		attrType := binary.BigEndian.Uint16(attributes[0:])
+		attrLength := int(binary.BigEndian.Uint16(attributes[2:]))
+		if 4+attrLength > len(attributes) {
+			return nil, errStunInvalidResponse
+		}
+		value := attributes[4 : 4+attrLength]
+
+		switch attrType {
+		case stunAttrXorMappedAddr:
+			return parseStunAddress(value, response[4:stunHeaderSize])
+		case stunAttrMappedAddress:
+			ip, err := parseStunAddress(value, nil)
+			if err != nil {
+				return nil, err
+			}
+			mappedIP = ip
+		}
+
+		padded := (attrLength + stunAttributeAlignment - 1) / stunAttributeAlignment * stunAttributeAlignment
+		if 4+padded > len(attributes) {
+			break
+		}
+		attributes = attributes[4+padded:]
+	}
+
+	if mappedIP == nil {
+		return nil, errStunNoAddress
+	}
+	return mappedIP, nil
+}
+
+// parseStunAddress decodes (XOR-)MAPPED-ADDRESS attribute value, xorKey is nil for plain MAPPED-ADDRESS
+func parseStunAddress(value, xorKey []byte) (net.IP, error) {
+	if len(value) < 4 {
+		return nil, errStunInvalidResponse
+	}
+
+	var size int
+	switch value[1] {
+	case stunAddressFamilyIPv4:
+		size = net.IPv4len
+	case stunAddressFamilyIPv6:
+		size = net.IPv6len
+	default:
+		return nil, errStunInvalidResponse
+	}
+	if len(value) < 4+size {
+		return nil, errStunInvalidResponse
+	}
+
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+	for i := range ip {
+		if i < len(xorKey) {
+			ip[i] ^= xorKey[i]
+		}
+	}
+	return ip, nil
+}
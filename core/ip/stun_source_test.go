@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ip
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startStunServer answers binding requests with XOR-MAPPED-ADDRESS of given IP
+func startStunServer(t *testing.T, mappedIP net.IP) *net.UDPConn {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	assert.NoError(t, err)
+
+	go func() {
+		buffer := make([]byte, stunMaxResponseSize)
+		for {
+			n, addr, err := conn.ReadFromUDP(buffer)
+			if err != nil {
+				return
+			}
+			conn.WriteToUDP(newStunTestResponse(buffer[:n], mappedIP), addr)
+		}
+	}()
+	return conn
+}
+
+func newStunTestResponse(request []byte, mappedIP net.IP) []byte {
+	family := byte(stunAddressFamilyIPv6)
+	if ip4 := mappedIP.To4(); ip4 != nil {
+		family = stunAddressFamilyIPv4
+		mappedIP = ip4
+	}
+
+	value := make([]byte, 4+len(mappedIP))
+	value[1] = family
+	binary.BigEndian.PutUint16(value[2:], 1234^(stunMagicCookie>>16))
+	copy(value[4:], mappedIP)
+	for i := range mappedIP {
+		value[4+i] ^= request[4+i]
+	}
+
+	response := make([]byte, stunHeaderSize, stunHeaderSize+4+len(value))
+	binary.BigEndian.PutUint16(response[0:], stunBindingSuccess)
+	binary.BigEndian.PutUint16(response[2:], uint16(4+len(value)))
+	copy(response[4:], request[4:stunHeaderSize])
+	attribute := make([]byte, 4)
+	binary.BigEndian.PutUint16(attribute[0:], stunAttrXorMappedAddr)
+	binary.BigEndian.PutUint16(attribute[2:], uint16(len(value)))
+	response = append(response, attribute...)
+	return append(response, value...)
+}
+
+func TestStunSourceResolvesIPv4(t *testing.T) {
+	server := startStunServer(t, net.ParseIP("1.2.3.4"))
+	defer server.Close()
+
+	source, err := NewSource("stun:"+server.LocalAddr().String(), time.Second)
+	assert.NoError(t, err)
+
+	ip, err := source.PublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", ip)
+}
+
+func TestStunSourceResolvesIPv6(t *testing.T) {
+	server := startStunServer(t, net.ParseIP("2001:db8::1"))
+	defer server.Close()
+
+	source, err := NewSource("stun:"+server.LocalAddr().String(), time.Second)
+	assert.NoError(t, err)
+
+	ip, err := source.PublicIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "2001:db8::1", ip)
+}
+
+func TestStunSourceAddsDefaultPort(t *testing.T) {
+	source := newStunSource("stun.example.com", time.Second)
+	assert.Equal(t, "stun:stun.example.com:3478", source.Name())
+}
+
+func TestParseStunBindingResponseRejectsForeignTransaction(t *testing.T) {
+	request, err := newStunBindingRequest()
+	assert.NoError(t, err)
+	response := newStunTestResponse(request, net.ParseIP("1.2.3.4"))
+
+	_, err = parseStunBindingResponse(response, make([]byte, stunTransactionIDSize))
+	assert.Equal(t, errStunInvalidResponse, err)
+}
@@ -28,3 +28,10 @@ func TestBuiltInResolverWorks(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "RU", country)
 }
+
+func TestBuiltInResolverResolvesLocation(t *testing.T) {
+	location, err := NewBuiltInResolver().ResolveLocation("46.111.111.99")
+	assert.NoError(t, err)
+	assert.Equal(t, "RU", location.Country)
+	assert.Equal(t, "46.111.111.99", location.IP)
+}
@@ -19,47 +19,147 @@ package location
 
 import (
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"net"
+	"strings"
+	"time"
 
 	"github.com/oschwald/geoip2-golang"
 )
 
-// DbResolver struct represents ip -> country resolver which uses geoip2 data reader
+// DbResolver struct represents ip -> location resolver which uses geoip2 data readers.
+// Country or city database is required, autonomous system database is optional.
 type DbResolver struct {
-	dbReader *geoip2.Reader
+	dbReader  *geoip2.Reader
+	asnReader *geoip2.Reader
 }
 
-// NewExternalDbResolver returns Resolver which uses external country database
-func NewExternalDbResolver(databasePath string) Resolver {
-	db, err := geoip2.Open(databasePath)
+// NewExternalDbResolver returns Resolver which uses external databases.
+// Database kinds (country, city or ASN) are recognized by their metadata.
+func NewExternalDbResolver(databasePaths ...string) Resolver {
+	resolver, err := openDbResolver(databasePaths...)
 	if err != nil {
 		return NewFailingResolver(err)
 	}
+	return resolver
+}
+
+// openDbResolver reads databases into memory instead of mapping files,
+// so that database files can be replaced while resolver is in use
+func openDbResolver(databasePaths ...string) (*DbResolver, error) {
+	resolver := &DbResolver{}
+	for _, databasePath := range databasePaths {
+		db, err := openDatabase(databasePath)
+		if err != nil {
+			resolver.Close()
+			return nil, err
+		}
+
+		// later database of the same kind replaces the earlier one
+		replaced := &resolver.dbReader
+		if isDatabaseOfType(db, "ASN") {
+			replaced = &resolver.asnReader
+		}
+		if *replaced != nil {
+			(*replaced).Close()
+		}
+		*replaced = db
+	}
+
+	if resolver.dbReader == nil {
+		resolver.Close()
+		return nil, errors.New("country or city database is required")
+	}
+	return resolver, nil
+}
+
+func openDatabase(databasePath string) (*geoip2.Reader, error) {
+	data, err := ioutil.ReadFile(databasePath)
+	if err != nil {
+		return nil, err
+	}
+	return geoip2.FromBytes(data)
+}
+
+// Databases describes databases used by resolver
+func (r *DbResolver) Databases() []DatabaseInfo {
+	var databases []DatabaseInfo
+	for _, db := range []*geoip2.Reader{r.dbReader, r.asnReader} {
+		if db == nil {
+			continue
+		}
+		metadata := db.Metadata()
+		databases = append(databases, DatabaseInfo{
+			Type:      metadata.DatabaseType,
+			BuildDate: time.Unix(int64(metadata.BuildEpoch), 0).UTC(),
+		})
+	}
+	return databases
+}
 
-	return &DbResolver{
-		dbReader: db,
+// Close releases databases of resolver
+func (r *DbResolver) Close() {
+	for _, db := range []*geoip2.Reader{r.dbReader, r.asnReader} {
+		if db != nil {
+			db.Close()
+		}
 	}
 }
 
 // ResolveCountry maps given ip to country
 func (r *DbResolver) ResolveCountry(ip string) (string, error) {
+	location, err := r.ResolveLocation(ip)
+	return location.Country, err
+}
+
+// ResolveLocation maps given ip to country, city and autonomous system.
+// City and autonomous system are left empty when databases do not provide them.
+func (r *DbResolver) ResolveLocation(ip string) (Location, error) {
 	ipObject := net.ParseIP(ip)
 	if ipObject == nil {
-		return "", errors.New("failed to parse IP")
+		return Location{}, errors.New("failed to parse IP")
 	}
 
-	countryRecord, err := r.dbReader.Country(ipObject)
-	if err != nil {
-		return "", err
+	location := Location{IP: ip}
+	if isDatabaseOfType(r.dbReader, "City") {
+		cityRecord, err := r.dbReader.City(ipObject)
+		if err != nil {
+			return Location{}, err
+		}
+		location.Country = firstNonEmpty(cityRecord.Country.IsoCode, cityRecord.RegisteredCountry.IsoCode)
+		location.City = cityRecord.City.Names["en"]
+	} else {
+		countryRecord, err := r.dbReader.Country(ipObject)
+		if err != nil {
+			return Location{}, err
+		}
+		location.Country = firstNonEmpty(countryRecord.Country.IsoCode, countryRecord.RegisteredCountry.IsoCode)
 	}
 
-	country := countryRecord.Country.IsoCode
-	if country == "" {
-		country = countryRecord.RegisteredCountry.IsoCode
-		if country == "" {
-			return "", errors.New("failed to resolve country")
+	if location.Country == "" {
+		return Location{}, errors.New("failed to resolve country")
+	}
+
+	if r.asnReader != nil {
+		asnRecord, err := r.asnReader.ASN(ipObject)
+		if err == nil && asnRecord.AutonomousSystemNumber != 0 {
+			location.ASN = fmt.Sprintf("AS%d", asnRecord.AutonomousSystemNumber)
 		}
 	}
 
-	return country, nil
+	return location, nil
+}
+
+func isDatabaseOfType(db *geoip2.Reader, kind string) bool {
+	return strings.Contains(db.Metadata().DatabaseType, kind)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
 }
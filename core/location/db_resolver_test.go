@@ -50,3 +50,25 @@ func TestResolverResolveCountry(t *testing.T) {
 		}
 	}
 }
+
+func TestResolverResolveLocationWithCountryDatabase(t *testing.T) {
+	resolver := NewExternalDbResolver("db/GeoLite2-Country.mmdb")
+
+	location, err := resolver.ResolveLocation("95.85.39.36")
+	assert.NoError(t, err)
+	assert.Equal(t, Location{IP: "95.85.39.36", Country: "NL"}, location)
+}
+
+func TestExternalDbResolverRequiresCountryDatabase(t *testing.T) {
+	_, err := NewExternalDbResolver().ResolveLocation("8.8.8.8")
+	assert.EqualError(t, err, "country or city database is required")
+
+	_, err = NewExternalDbResolver("db/missing.mmdb").ResolveLocation("8.8.8.8")
+	assert.Error(t, err)
+}
+
+func TestExternalDbResolverFailsWhenLaterDatabaseIsMissing(t *testing.T) {
+	resolver, err := openDbResolver("db/GeoLite2-Country.mmdb", "db/missing.mmdb")
+	assert.Error(t, err)
+	assert.Nil(t, resolver)
+}
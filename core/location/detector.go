@@ -57,11 +57,11 @@ func (d *detector) DetectLocation() (Location, error) {
 		return Location{}, err
 	}
 
-	country, err := d.locationResolver.ResolveCountry(ipAddress)
+	location, err := d.locationResolver.ResolveLocation(ipAddress)
 	if err != nil {
 		return Location{}, err
 	}
 
-	location := Location{Country: country, IP: ipAddress}
+	location.IP = ipAddress
 	return location, nil
 }
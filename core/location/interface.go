@@ -17,9 +17,23 @@
 
 package location
 
+import "time"
+
 // Resolver allows resolving location by ip
 type Resolver interface {
 	ResolveCountry(ip string) (string, error)
+	ResolveLocation(ip string) (Location, error)
+}
+
+// DatabaseInfo describes geolocation database
+type DatabaseInfo struct {
+	Type      string
+	BuildDate time.Time
+}
+
+// DatabaseReporter is implemented by resolvers which use geolocation databases
+type DatabaseReporter interface {
+	Databases() []DatabaseInfo
 }
 
 // Detector allows detecting location by current ip
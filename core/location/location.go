@@ -17,7 +17,7 @@
 
 package location
 
-// Location structure represents location information (ip and country)
+// Location structure represents location information (ip, country, city and autonomous system)
 type Location struct {
 	// IP address
 	// example: 127.0.0.1
@@ -25,4 +25,11 @@ type Location struct {
 
 	// example: NL
 	Country string `json:"country"`
+
+	// example: Amsterdam
+	City string `json:"city,omitempty"`
+
+	// Autonomous System Number
+	// example: AS1136
+	ASN string `json:"asn,omitempty"`
 }
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+const reloadingLogPrefix = "[location-db] "
+
+// validationIP is an address which every valid database must be able to resolve
+const validationIP = "8.8.8.8"
+
+// ReloadingResolver resolves location using external databases and reloads them when database files change.
+// Changed databases are validated before switching, so that broken files do not replace working ones.
+type ReloadingResolver struct {
+	databasePaths []string
+	checkInterval time.Duration
+	open          func(databasePaths ...string) (*DbResolver, error)
+
+	lock      sync.RWMutex
+	current   *DbResolver
+	loadError error
+	modTimes  map[string]time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewReloadingResolver opens given external databases and returns resolver which reloads them on change.
+// Resolver returns error until databases are loaded successfully.
+func NewReloadingResolver(checkInterval time.Duration, databasePaths ...string) *ReloadingResolver {
+	resolver := &ReloadingResolver{
+		databasePaths: databasePaths,
+		checkInterval: checkInterval,
+		open:          openDbResolver,
+		stop:          make(chan struct{}),
+	}
+	resolver.reload()
+	return resolver
+}
+
+// Start launches checking of database files for changes
+func (r *ReloadingResolver) Start() {
+	go func() {
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-time.After(r.checkInterval):
+				r.reload()
+			}
+		}
+	}()
+}
+
+// Stop stops checking database files and releases databases
+func (r *ReloadingResolver) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if r.current != nil {
+			r.current.Close()
+			r.current = nil
+		}
+	})
+}
+
+// ResolveCountry maps given ip to country
+func (r *ReloadingResolver) ResolveCountry(ip string) (string, error) {
+	location, err := r.ResolveLocation(ip)
+	return location.Country, err
+}
+
+// ResolveLocation maps given ip to location using currently loaded databases
+func (r *ReloadingResolver) ResolveLocation(ip string) (Location, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.current == nil {
+		return Location{}, r.loadError
+	}
+	return r.current.ResolveLocation(ip)
+}
+
+// Databases describes currently loaded databases
+func (r *ReloadingResolver) Databases() []DatabaseInfo {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Databases()
+}
+
+// reload opens databases again if any of their files changed since last load
+func (r *ReloadingResolver) reload() {
+	modTimes, err := r.readModTimes()
+	if err != nil {
+		r.failLoad(err, nil)
+		return
+	}
+	if !r.changed(modTimes) {
+		return
+	}
+
+	resolver, err := r.open(r.databasePaths...)
+	if err == nil {
+		_, err = resolver.ResolveLocation(validationIP)
+		if err != nil {
+			resolver.Close()
+		}
+	}
+	if err != nil {
+		r.failLoad(err, modTimes)
+		return
+	}
+
+	r.lock.Lock()
+	previous := r.current
+	r.current = resolver
+	r.loadError = nil
+	r.modTimes = modTimes
+	r.lock.Unlock()
+
+	// readers of previous databases are done, as they hold the lock while resolving
+	if previous != nil {
+		previous.Close()
+	}
+	log.Info(reloadingLogPrefix, "location databases loaded: ", r.databasePaths)
+}
+
+// failLoad keeps previously loaded databases. Modification times of rejected files are remembered,
+// so that they are not loaded again until they change.
+func (r *ReloadingResolver) failLoad(err error, modTimes map[string]time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.current == nil {
+		r.loadError = err
+		return
+	}
+	if modTimes != nil {
+		r.modTimes = modTimes
+	}
+	log.Warn(reloadingLogPrefix, "failed to reload location databases, keeping previous ones: ", err)
+}
+
+func (r *ReloadingResolver) readModTimes() (map[string]time.Time, error) {
+	if len(r.databasePaths) == 0 {
+		return nil, errors.New("country or city database is required")
+	}
+
+	modTimes := make(map[string]time.Time, len(r.databasePaths))
+	for _, databasePath := range r.databasePaths {
+		info, err := os.Stat(databasePath)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[databasePath] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *ReloadingResolver) changed(modTimes map[string]time.Time) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.current == nil {
+		return true
+	}
+	for databasePath, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[databasePath]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadingResolverResolvesLocation(t *testing.T) {
+	dir, databasePath := prepareDatabaseCopy(t)
+	defer os.RemoveAll(dir)
+
+	resolver := NewReloadingResolver(time.Minute, databasePath)
+	defer resolver.Stop()
+
+	location, err := resolver.ResolveLocation("95.85.39.36")
+	assert.NoError(t, err)
+	assert.Equal(t, "NL", location.Country)
+
+	databases := resolver.Databases()
+	assert.Len(t, databases, 1)
+	assert.Equal(t, "GeoLite2-Country", databases[0].Type)
+	assert.False(t, databases[0].BuildDate.IsZero())
+}
+
+func TestReloadingResolverKeepsDatabaseWhenNewOneIsInvalid(t *testing.T) {
+	dir, databasePath := prepareDatabaseCopy(t)
+	defer os.RemoveAll(dir)
+
+	resolver := NewReloadingResolver(time.Minute, databasePath)
+	defer resolver.Stop()
+	previous := resolver.current
+
+	assert.NoError(t, ioutil.WriteFile(databasePath, []byte("broken"), 0644))
+	touch(t, databasePath, time.Now().Add(time.Hour))
+	resolver.reload()
+
+	assert.Equal(t, previous, resolver.current)
+	country, err := resolver.ResolveCountry("95.85.39.36")
+	assert.NoError(t, err)
+	assert.Equal(t, "NL", country)
+}
+
+func TestReloadingResolverSwapsChangedDatabase(t *testing.T) {
+	dir, databasePath := prepareDatabaseCopy(t)
+	defer os.RemoveAll(dir)
+
+	resolver := NewReloadingResolver(time.Minute, databasePath)
+	defer resolver.Stop()
+	previous := resolver.current
+
+	resolver.reload()
+	assert.Equal(t, previous, resolver.current, "unchanged database should not be reloaded")
+
+	touch(t, databasePath, time.Now().Add(time.Hour))
+	resolver.reload()
+
+	assert.NotEqual(t, previous, resolver.current)
+	country, err := resolver.ResolveCountry("95.85.39.36")
+	assert.NoError(t, err)
+	assert.Equal(t, "NL", country)
+}
+
+func TestReloadingResolverLoadsDatabaseWhenItAppears(t *testing.T) {
+	dir, databasePath := prepareDatabaseCopy(t)
+	defer os.RemoveAll(dir)
+
+	missingPath := filepath.Join(dir, "later.mmdb")
+	resolver := NewReloadingResolver(time.Minute, missingPath)
+	defer resolver.Stop()
+
+	_, err := resolver.ResolveLocation("95.85.39.36")
+	assert.Error(t, err)
+	assert.Nil(t, resolver.Databases())
+
+	assert.NoError(t, os.Rename(databasePath, missingPath))
+	resolver.reload()
+
+	_, err = resolver.ResolveLocation("95.85.39.36")
+	assert.NoError(t, err)
+}
+
+func prepareDatabaseCopy(t *testing.T) (string, string) {
+	dir, err := ioutil.TempDir("", "location-db")
+	assert.NoError(t, err)
+
+	data, err := ioutil.ReadFile("db/GeoLite2-Country.mmdb")
+	assert.NoError(t, err)
+
+	databasePath := filepath.Join(dir, "GeoLite2-Country.mmdb")
+	assert.NoError(t, ioutil.WriteFile(databasePath, data, 0644))
+	return dir, databasePath
+}
+
+func touch(t *testing.T, path string, modTime time.Time) {
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
@@ -43,3 +43,11 @@ func NewFailingResolver(err error) *StaticResolver {
 func (d *StaticResolver) ResolveCountry(ip string) (string, error) {
 	return d.country, d.error
 }
+
+// ResolveLocation maps given ip to location with specified country
+func (d *StaticResolver) ResolveLocation(ip string) (Location, error) {
+	if d.error != nil {
+		return Location{}, d.error
+	}
+	return Location{IP: ip, Country: d.country}, nil
+}
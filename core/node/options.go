@@ -17,6 +17,8 @@
 
 package node
 
+import "time"
+
 // Openvpn interface is abstraction over real openvpn options to unblock mobile development
 // will disappear as soon as go-openvpn will unify common factory for openvpn creation
 type Openvpn interface {
@@ -41,4 +43,6 @@ type Options struct {
 // OptionsKeystore stores the keystore configuration
 type OptionsKeystore struct {
 	UseLightweight bool
+	// LockIdle locks identities which were not used for given period, zero disables idle locking
+	LockIdle time.Duration
 }
@@ -20,6 +20,9 @@ package node
 // OptionsLocation describes possible parameters of location detection configuration
 type OptionsLocation struct {
 	IpifyUrl   string
+	IPSources  string
 	ExternalDb string
 	Country    string
+	// LeakCheckDNS enables check whether DNS queries are resolved through connection
+	LeakCheckDNS bool
 }
@@ -17,6 +17,13 @@
 
 package node
 
+const (
+	// DiscoveryTypeAPI finds proposals thru central discovery API
+	DiscoveryTypeAPI = "api"
+	// DiscoveryTypeBroker finds proposals by listening to provider announcements on message broker
+	DiscoveryTypeBroker = "broker"
+)
+
 // OptionsNetwork describes possible parameters of network configuration
 type OptionsNetwork struct {
 	Testnet  bool
@@ -25,11 +32,20 @@ type OptionsNetwork struct {
 	ExperimentIdentityCheck bool
 	ExperimentPayments      bool
 
+	// DiscoveryType selects proposal discovery backend, one of DiscoveryTypeAPI and DiscoveryTypeBroker
+	DiscoveryType       string
 	DiscoveryAPIAddress string
-	BrokerAddress       string
+	// BrokerAddress holds comma separated list of broker URIs
+	BrokerAddress string
 
 	EtherClientRPC       string
 	EtherPaymentsAddress string
 
+	// EtherRegistrationSubmit enables node to send registration transactions of its identities
+	EtherRegistrationSubmit bool
+	// EtherRegistrationPayer is keystore account paying for registration transactions, identity pays itself when empty
+	EtherRegistrationPayer           string
+	EtherRegistrationPayerPassphrase string
+
 	QualityOracle string
 }
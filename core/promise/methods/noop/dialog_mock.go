@@ -39,6 +39,10 @@ func (fd *fakeDialog) PeerID() identity.Identity {
 	return identity.Identity{}
 }
 
+func (fd *fakeDialog) Protocol() communication.ProtocolInfo {
+	return communication.NewProtocolInfo()
+}
+
 func (fd *fakeDialog) Close() error {
 	return nil
 }
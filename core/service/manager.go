@@ -20,6 +20,7 @@ package service
 import (
 	"encoding/json"
 	"errors"
+	"sync"
 
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/identity"
@@ -34,6 +35,10 @@ var (
 	ErrorLocation = errors.New("failed to detect service location")
 	// ErrUnsupportedServiceType indicates that manager tried to create an unsupported service type
 	ErrUnsupportedServiceType = errors.New("unsupported service type")
+	// ErrServiceNotStarted indicates that proposal can not be published before service is started
+	ErrServiceNotStarted = errors.New("service is not started")
+	// ErrProposalNotFound indicates that service does not publish proposal with given ID
+	ErrProposalNotFound = errors.New("proposal not found")
 )
 
 // ServiceFactory initiates instance which is able to serve connections
@@ -49,8 +54,16 @@ type Service interface {
 // DialogWaiterFactory initiates communication channel which waits for incoming dialogs
 type DialogWaiterFactory func(providerID identity.Identity, serviceType string) (communication.DialogWaiter, error)
 
+// dialogStatsProvider is a dialog waiter which counts rejected dialog requests
+type dialogStatsProvider interface {
+	Stats() communication.DialogWaiterStats
+}
+
 // DialogHandlerFactory initiates instance which is able to handle incoming dialogs
-type DialogHandlerFactory func(market.ServiceProposal, session.ConfigNegotiator) communication.DialogHandler
+type DialogHandlerFactory func(session.ProposalLookup, session.ConfigNegotiator) communication.DialogHandler
+
+// DiscoveryFactory initiates instance which announces proposal to consumers
+type DiscoveryFactory func() *registry.Discovery
 
 // NewManager creates new instance of pluggable services manager
 func NewManager(
@@ -58,14 +71,16 @@ func NewManager(
 	serviceFactory ServiceFactory,
 	dialogWaiterFactory DialogWaiterFactory,
 	dialogHandlerFactory DialogHandlerFactory,
-	discoveryService *registry.Discovery,
+	discoveryFactory DiscoveryFactory,
 ) *Manager {
 	return &Manager{
 		identityHandler:      identityLoader,
 		serviceFactory:       serviceFactory,
 		dialogWaiterFactory:  dialogWaiterFactory,
 		dialogHandlerFactory: dialogHandlerFactory,
-		discovery:            discoveryService,
+		discoveryFactory:     discoveryFactory,
+		generateProposalID:   market.NewProposalIDGenerator(),
+		proposals:            make(map[int]*registry.Discovery),
 	}
 }
 
@@ -80,7 +95,13 @@ type Manager struct {
 	serviceFactory ServiceFactory
 	service        Service
 
-	discovery *registry.Discovery
+	discoveryFactory   DiscoveryFactory
+	generateProposalID market.ProposalIDGenerator
+
+	proposalsLock   sync.RWMutex
+	providerID      identity.Identity
+	providerContact market.Contact
+	proposals       map[int]*registry.Discovery
 }
 
 // Start starts service - does not block
@@ -98,37 +119,169 @@ func (manager *Manager) Start(options Options) (err error) {
 
 	manager.service = service
 
-	manager.dialogWaiter, err = manager.dialogWaiterFactory(providerID, proposal.ServiceType)
+	dialogWaiter, err := manager.dialogWaiterFactory(providerID, proposal.ServiceType)
 	if err != nil {
 		return err
 	}
-	providerContact, err := manager.dialogWaiter.Start()
+	manager.proposalsLock.Lock()
+	manager.dialogWaiter = dialogWaiter
+	manager.proposalsLock.Unlock()
+
+	providerContact, err := dialogWaiter.Start()
 	if err != nil {
 		return err
 	}
-	proposal.SetProviderContact(providerID, providerContact)
 
-	dialogHandler := manager.dialogHandlerFactory(proposal, service)
-	if err = manager.dialogWaiter.ServeDialogs(dialogHandler); err != nil {
+	manager.proposalsLock.Lock()
+	manager.providerID = providerID
+	manager.providerContact = providerContact
+	manager.proposalsLock.Unlock()
+
+	dialogHandler := manager.dialogHandlerFactory(manager.FindProposal, service)
+	if err = dialogWaiter.ServeDialogs(dialogHandler); err != nil {
 		return err
 	}
 
-	manager.discovery.Start(providerID, proposal)
+	if _, err = manager.PublishProposal(proposal); err != nil {
+		return err
+	}
 
 	err = manager.service.Serve(providerID)
-	manager.discovery.Wait()
+	for _, discovery := range manager.discoveries() {
+		discovery.Wait()
+	}
 	return err
 }
 
+// PublishProposal announces given variant of the service proposal under newly generated ID
+func (manager *Manager) PublishProposal(proposal market.ServiceProposal) (int, error) {
+	manager.proposalsLock.Lock()
+	defer manager.proposalsLock.Unlock()
+
+	if manager.providerID.Address == "" {
+		return 0, ErrServiceNotStarted
+	}
+
+	proposal.ID = manager.generateProposalID()
+	proposal.SetProviderContact(manager.providerID, manager.providerContact)
+
+	discovery := manager.discoveryFactory()
+	discovery.Start(manager.providerID, proposal)
+	manager.proposals[proposal.ID] = discovery
+
+	return proposal.ID, nil
+}
+
+// UpdateProposal re-publishes modified proposal with the same ID, existing sessions are kept
+func (manager *Manager) UpdateProposal(proposal market.ServiceProposal) error {
+	manager.proposalsLock.RLock()
+	discovery, found := manager.proposals[proposal.ID]
+	providerID, providerContact := manager.providerID, manager.providerContact
+	manager.proposalsLock.RUnlock()
+
+	if !found {
+		return ErrProposalNotFound
+	}
+
+	proposal.SetProviderContact(providerID, providerContact)
+	return discovery.UpdateProposal(proposal)
+}
+
+// UnpublishProposal stops announcing proposal with given ID, new sessions for it are not accepted anymore
+func (manager *Manager) UnpublishProposal(proposalID int) error {
+	manager.proposalsLock.Lock()
+	discovery, found := manager.proposals[proposalID]
+	delete(manager.proposals, proposalID)
+	manager.proposalsLock.Unlock()
+
+	if !found {
+		return ErrProposalNotFound
+	}
+
+	discovery.Stop()
+	return nil
+}
+
+// FindProposal returns currently published proposal with given ID
+func (manager *Manager) FindProposal(proposalID int) (market.ServiceProposal, bool) {
+	manager.proposalsLock.RLock()
+	discovery, found := manager.proposals[proposalID]
+	manager.proposalsLock.RUnlock()
+
+	if !found {
+		return market.ServiceProposal{}, false
+	}
+	return discovery.Proposal(), true
+}
+
+// Proposals returns all currently published proposals of the service
+func (manager *Manager) Proposals() []market.ServiceProposal {
+	discoveries := manager.discoveries()
+
+	proposals := make([]market.ServiceProposal, 0, len(discoveries))
+	for _, discovery := range discoveries {
+		proposals = append(proposals, discovery.Proposal())
+	}
+	return proposals
+}
+
+// DiscoveryStates returns discovery progress of all currently published proposals
+func (manager *Manager) DiscoveryStates() []registry.State {
+	discoveries := manager.discoveries()
+
+	states := make([]registry.State, 0, len(discoveries))
+	for _, discovery := range discoveries {
+		states = append(states, discovery.State())
+	}
+	return states
+}
+
+func (manager *Manager) discoveries() []*registry.Discovery {
+	manager.proposalsLock.RLock()
+	defer manager.proposalsLock.RUnlock()
+
+	discoveries := make([]*registry.Discovery, 0, len(manager.proposals))
+	for _, discovery := range manager.proposals {
+		discoveries = append(discoveries, discovery)
+	}
+	return discoveries
+}
+
+// ProviderID returns identity the service is provided with, it is empty until service is started
+func (manager *Manager) ProviderID() identity.Identity {
+	manager.proposalsLock.RLock()
+	defer manager.proposalsLock.RUnlock()
+
+	return manager.providerID
+}
+
+// DialogStats returns counters of dialog requests rejected by the service,
+// found is false until service is started or when its dialog waiter does not count them
+func (manager *Manager) DialogStats() (stats communication.DialogWaiterStats, found bool) {
+	manager.proposalsLock.RLock()
+	defer manager.proposalsLock.RUnlock()
+
+	provider, ok := manager.dialogWaiter.(dialogStatsProvider)
+	if !ok {
+		return stats, false
+	}
+	return provider.Stats(), true
+}
+
 // Kill stops service
 func (manager *Manager) Kill() error {
 	var errDialogWaiter, errService error
 
-	if manager.discovery != nil {
-		manager.discovery.Stop()
+	manager.proposalsLock.Lock()
+	manager.providerID = identity.Identity{}
+	dialogWaiter := manager.dialogWaiter
+	manager.proposalsLock.Unlock()
+
+	for _, discovery := range manager.discoveries() {
+		discovery.Stop()
 	}
-	if manager.dialogWaiter != nil {
-		errDialogWaiter = manager.dialogWaiter.Stop()
+	if dialogWaiter != nil {
+		errDialogWaiter = dialogWaiter.Stop()
 	}
 	if manager.service != nil {
 		errService = manager.service.Stop()
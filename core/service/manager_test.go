@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/identity"
+	identity_registry "github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/market/proposals/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	managerProviderID = identity.FromAddress("0x1")
+	managerContact    = market.Contact{Type: "nats/v1"}
+)
+
+func TestManager_PublishProposalRequiresStartedService(t *testing.T) {
+	manager := NewManager(nil, nil, nil, nil, newDiscoveryFactory(&proposalRegistryFake{}))
+
+	_, err := manager.PublishProposal(market.ServiceProposal{ServiceType: "noop"})
+	assert.Equal(t, ErrServiceNotStarted, err)
+	assert.Len(t, manager.Proposals(), 0)
+}
+
+func TestManager_PublishProposals(t *testing.T) {
+	manager := startedManager(&proposalRegistryFake{})
+	defer manager.Kill()
+
+	firstID, err := manager.PublishProposal(market.ServiceProposal{ServiceType: "noop", PaymentMethodType: "first"})
+	assert.NoError(t, err)
+	secondID, err := manager.PublishProposal(market.ServiceProposal{ServiceType: "noop", PaymentMethodType: "second"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, firstID, secondID)
+
+	proposal, found := manager.FindProposal(secondID)
+	assert.True(t, found)
+	assert.Equal(t, secondID, proposal.ID)
+	assert.Equal(t, "second", proposal.PaymentMethodType)
+	assert.Equal(t, managerProviderID.Address, proposal.ProviderID)
+	assert.Equal(t, market.ContactList{managerContact}, proposal.ProviderContacts)
+	assert.Len(t, manager.Proposals(), 2)
+}
+
+func TestManager_UpdateProposal(t *testing.T) {
+	manager := startedManager(&proposalRegistryFake{})
+	defer manager.Kill()
+
+	id, err := manager.PublishProposal(market.ServiceProposal{ServiceType: "noop", PaymentMethodType: "old"})
+	assert.NoError(t, err)
+
+	err = manager.UpdateProposal(market.ServiceProposal{ID: id, ServiceType: "noop", PaymentMethodType: "new"})
+	assert.NoError(t, err)
+
+	proposal, found := manager.FindProposal(id)
+	assert.True(t, found)
+	assert.Equal(t, "new", proposal.PaymentMethodType)
+	assert.Equal(t, managerProviderID.Address, proposal.ProviderID)
+	assert.Equal(t, market.ContactList{managerContact}, proposal.ProviderContacts)
+	assert.Len(t, manager.Proposals(), 1)
+}
+
+func TestManager_UnpublishProposal(t *testing.T) {
+	manager := startedManager(&proposalRegistryFake{})
+	defer manager.Kill()
+
+	id, err := manager.PublishProposal(market.ServiceProposal{ServiceType: "noop"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, manager.UnpublishProposal(id))
+	_, found := manager.FindProposal(id)
+	assert.False(t, found)
+	assert.Len(t, manager.Proposals(), 0)
+}
+
+func TestManager_UnknownProposal(t *testing.T) {
+	manager := startedManager(&proposalRegistryFake{})
+	defer manager.Kill()
+
+	_, found := manager.FindProposal(42)
+	assert.False(t, found)
+	assert.Equal(t, ErrProposalNotFound, manager.UpdateProposal(market.ServiceProposal{ID: 42}))
+	assert.Equal(t, ErrProposalNotFound, manager.UnpublishProposal(42))
+}
+
+// startedManager returns manager which publishes proposals as if its service was started
+func startedManager(proposalRegistry registry.ProposalRegistry) *Manager {
+	manager := NewManager(nil, nil, nil, nil, newDiscoveryFactory(proposalRegistry))
+	manager.providerID = managerProviderID
+	manager.providerContact = managerContact
+	return manager
+}
+
+func newDiscoveryFactory(proposalRegistry registry.ProposalRegistry) DiscoveryFactory {
+	return func() *registry.Discovery {
+		return registry.NewService(
+			&identity_registry.FakeRegistry{Registered: true},
+			&identity_registry.FakeRegistrationDataProvider{},
+			nil,
+			proposalRegistry,
+			func(identity.Identity) identity.Signer { return &identity.SignerFake{} },
+		)
+	}
+}
+
+type proposalRegistryFake struct {
+	lock       sync.Mutex
+	registered map[int]market.ServiceProposal
+}
+
+func (fake *proposalRegistryFake) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	if fake.registered == nil {
+		fake.registered = make(map[int]market.ServiceProposal)
+	}
+	fake.registered[proposal.ID] = proposal
+	return nil
+}
+
+func (fake *proposalRegistryFake) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return nil
+}
+
+func (fake *proposalRegistryFake) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	delete(fake.registered, proposal.ID)
+	return nil
+}
@@ -19,6 +19,11 @@ package service
 
 import (
 	"fmt"
+
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/market/proposals/registry"
 )
 
 // RunnableService represents a runnable service
@@ -27,6 +32,29 @@ type RunnableService interface {
 	Kill() error
 }
 
+// discoverableService is a service which publishes its proposals to discovery
+type discoverableService interface {
+	DiscoveryStates() []registry.State
+}
+
+// dialogCountingService is a service which counts rejected dialog requests
+type dialogCountingService interface {
+	DialogStats() (communication.DialogWaiterStats, bool)
+}
+
+// publishingService is a service which publishes several variants of its proposal
+type publishingService interface {
+	PublishProposal(proposal market.ServiceProposal) (int, error)
+	UpdateProposal(proposal market.ServiceProposal) error
+	UnpublishProposal(proposalID int) error
+	Proposals() []market.ServiceProposal
+}
+
+// identifiedService is a service which is provided with an identity
+type identifiedService interface {
+	ProviderID() identity.Identity
+}
+
 // RunnableServiceFactory creates a new runnable service instance
 type RunnableServiceFactory func() RunnableService
 
@@ -70,3 +98,83 @@ func (sr *Runner) KillAll() []error {
 	}
 	return errors
 }
+
+// DiscoveryStates returns discovery progress of published proposals grouped by service type
+func (sr *Runner) DiscoveryStates() map[string][]registry.State {
+	states := make(map[string][]registry.State)
+	for serviceType, serviceManager := range sr.serviceManagers {
+		if discoverable, ok := serviceManager.(discoverableService); ok {
+			states[serviceType] = discoverable.DiscoveryStates()
+		}
+	}
+	return states
+}
+
+// DialogStats returns counters of rejected dialog requests grouped by service type
+func (sr *Runner) DialogStats() map[string]communication.DialogWaiterStats {
+	stats := make(map[string]communication.DialogWaiterStats)
+	for serviceType, serviceManager := range sr.serviceManagers {
+		if counting, ok := serviceManager.(dialogCountingService); ok {
+			if serviceStats, found := counting.DialogStats(); found {
+				stats[serviceType] = serviceStats
+			}
+		}
+	}
+	return stats
+}
+
+// Proposals returns proposals currently published by the service of given type
+func (sr *Runner) Proposals(serviceType string) ([]market.ServiceProposal, error) {
+	publisher, err := sr.publisher(serviceType)
+	if err != nil {
+		return nil, err
+	}
+	return publisher.Proposals(), nil
+}
+
+// PublishProposal announces new variant of the proposal by the service of given type
+func (sr *Runner) PublishProposal(serviceType string, proposal market.ServiceProposal) (int, error) {
+	publisher, err := sr.publisher(serviceType)
+	if err != nil {
+		return 0, err
+	}
+	proposal.ServiceType = serviceType
+	return publisher.PublishProposal(proposal)
+}
+
+// UpdateProposal re-publishes modified proposal of the service of given type
+func (sr *Runner) UpdateProposal(serviceType string, proposal market.ServiceProposal) error {
+	publisher, err := sr.publisher(serviceType)
+	if err != nil {
+		return err
+	}
+	proposal.ServiceType = serviceType
+	return publisher.UpdateProposal(proposal)
+}
+
+// UnpublishProposal stops announcing proposal of the service of given type
+func (sr *Runner) UnpublishProposal(serviceType string, proposalID int) error {
+	publisher, err := sr.publisher(serviceType)
+	if err != nil {
+		return err
+	}
+	return publisher.UnpublishProposal(proposalID)
+}
+
+func (sr *Runner) publisher(serviceType string) (publishingService, error) {
+	publisher, ok := sr.serviceManagers[serviceType].(publishingService)
+	if !ok {
+		return nil, ErrUnsupportedServiceType
+	}
+	return publisher, nil
+}
+
+// UsesIdentity tells if any of the started services is provided with given identity
+func (sr *Runner) UsesIdentity(id identity.Identity) bool {
+	for _, serviceManager := range sr.serviceManagers {
+		if identified, ok := serviceManager.(identifiedService); ok && identified.ProviderID() == id {
+			return true
+		}
+	}
+	return false
+}
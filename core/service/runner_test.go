@@ -24,6 +24,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/market/proposals/registry"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -123,3 +127,126 @@ func Test_RunnerKillReturnsErrors(t *testing.T) {
 	err := runner.StartServiceByType(sType, Options{})
 	assert.Nil(t, err)
 }
+
+type mockDiscoverable struct {
+	MockRunnable
+	states []registry.State
+}
+
+func (md *mockDiscoverable) DiscoveryStates() []registry.State {
+	return md.states
+}
+
+func Test_RunnerReturnsDiscoveryStates(t *testing.T) {
+	states := []registry.State{{Status: registry.PingProposal, ProposalID: 1, ServiceType: "noop"}}
+	runner := NewRunner(func() RunnableService {
+		return &mockDiscoverable{states: states}
+	})
+	runner.Register("noop")
+
+	assert.Equal(t, map[string][]registry.State{"noop": states}, runner.DiscoveryStates())
+}
+
+func Test_RunnerSkipsNonDiscoverableServices(t *testing.T) {
+	m := &mockFactory{MockRunnable: &MockRunnable{}}
+	runner := NewRunner(m.serviceFactory)
+	runner.Register("test")
+
+	assert.Len(t, runner.DiscoveryStates(), 0)
+}
+
+type mockDialogCounting struct {
+	MockRunnable
+	stats communication.DialogWaiterStats
+	found bool
+}
+
+func (mdc *mockDialogCounting) DialogStats() (communication.DialogWaiterStats, bool) {
+	return mdc.stats, mdc.found
+}
+
+func Test_RunnerReturnsDialogStatsOfStartedServices(t *testing.T) {
+	stats := communication.DialogWaiterStats{RejectedCapacity: 2, RejectedRateLimited: 1}
+	services := map[string]RunnableService{
+		"openvpn": &mockDialogCounting{stats: stats, found: true},
+		"noop":    &mockDialogCounting{},
+		"test":    &MockRunnable{},
+	}
+	runner := NewRunner(nil)
+	for serviceType, service := range services {
+		runner.serviceManagers[serviceType] = service
+	}
+
+	assert.Equal(t, map[string]communication.DialogWaiterStats{"openvpn": stats}, runner.DialogStats())
+}
+
+type mockPublishing struct {
+	MockRunnable
+	proposals []market.ServiceProposal
+}
+
+func (mp *mockPublishing) PublishProposal(proposal market.ServiceProposal) (int, error) {
+	proposal.ID = len(mp.proposals) + 1
+	mp.proposals = append(mp.proposals, proposal)
+	return proposal.ID, nil
+}
+
+func (mp *mockPublishing) UpdateProposal(proposal market.ServiceProposal) error {
+	return nil
+}
+
+func (mp *mockPublishing) UnpublishProposal(proposalID int) error {
+	return ErrProposalNotFound
+}
+
+func (mp *mockPublishing) Proposals() []market.ServiceProposal {
+	return mp.proposals
+}
+
+func Test_RunnerPublishesProposalsOfServiceType(t *testing.T) {
+	runner := NewRunner(func() RunnableService {
+		return &mockPublishing{}
+	})
+	runner.Register("noop")
+
+	id, err := runner.PublishProposal("noop", market.ServiceProposal{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	proposals, err := runner.Proposals("noop")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{{ID: 1, ServiceType: "noop"}}, proposals)
+	assert.Equal(t, ErrProposalNotFound, runner.UnpublishProposal("noop", 2))
+}
+
+func Test_RunnerRejectsProposalsOfUnknownServiceType(t *testing.T) {
+	runner := NewRunner(func() RunnableService {
+		return &MockRunnable{}
+	})
+	runner.Register("test")
+
+	_, err := runner.Proposals("openvpn")
+	assert.Equal(t, ErrUnsupportedServiceType, err)
+	_, err = runner.PublishProposal("test", market.ServiceProposal{})
+	assert.Equal(t, ErrUnsupportedServiceType, err)
+	assert.Equal(t, ErrUnsupportedServiceType, runner.UpdateProposal("test", market.ServiceProposal{}))
+}
+
+type mockIdentified struct {
+	MockRunnable
+	providerID identity.Identity
+}
+
+func (mi *mockIdentified) ProviderID() identity.Identity {
+	return mi.providerID
+}
+
+func Test_RunnerUsesIdentityOfStartedServices(t *testing.T) {
+	runner := NewRunner(func() RunnableService {
+		return &mockIdentified{providerID: identity.FromAddress("0x1")}
+	})
+	runner.Register("noop")
+
+	assert.True(t, runner.UsesIdentity(identity.FromAddress("0x1")))
+	assert.False(t, runner.UsesIdentity(identity.FromAddress("0x2")))
+}
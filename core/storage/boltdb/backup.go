@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations"
+	bolt "go.etcd.io/bbolt"
+)
+
+// fileOpenTimeout limits waiting for the lock of database file, which is held while node is running
+const fileOpenTimeout = time.Second
+
+var (
+	// ErrDatabaseInUse is returned when database file is locked by a running node
+	ErrDatabaseInUse = errors.New("database is in use by a running node")
+	// ErrUnknownMigrations is returned when restored database has migrations unknown to this version
+	ErrUnknownMigrations = errors.New("database has migrations unknown to this version")
+	// ErrDatabaseDamaged is returned when restored database has records not matching known schemas
+	ErrDatabaseDamaged = errors.New("database has records not matching known schemas")
+)
+
+// Backup writes a consistent snapshot of the database to the given writer, while the database stays in use
+func (b *Bolt) Backup(w io.Writer) (int64, error) {
+	return writeSnapshot(b.db.Bolt, w)
+}
+
+// BackupFile writes a consistent snapshot of the given database file to the given writer.
+// ErrDatabaseInUse is returned when the file is used by a running node, its backup has to be taken by the node itself.
+func BackupFile(file string, w io.Writer) (int64, error) {
+	db, err := openFile(file, true)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	return writeSnapshot(db, w)
+}
+
+// Restore replaces the database file with the given backup file.
+// Backup is refused if it has migrations unknown to the given sequence or records not matching given schemas.
+// Replaced database file is kept with ".old" suffix, which is prefixed by current time when such file already exists.
+func Restore(file, backupFile string, schemas []Schema, sequence []migrations.Migration) (CheckReport, error) {
+	report, err := CheckFile(backupFile, schemas, sequence)
+	if err != nil {
+		return report, err
+	}
+	if len(report.UnknownMigrations) > 0 {
+		return report, ErrUnknownMigrations
+	}
+	if !report.Healthy() {
+		return report, ErrDatabaseDamaged
+	}
+
+	restoredFile := file + ".restore"
+	if err := copyFile(backupFile, restoredFile); err != nil {
+		return report, err
+	}
+
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return report, os.Rename(restoredFile, file)
+	}
+
+	err = replaceFile(file, restoredFile)
+	if err != nil {
+		os.Remove(restoredFile)
+	}
+	return report, err
+}
+
+// replaceFile moves database file aside and puts the restored one in its place.
+// Lock of the database file is held meanwhile, so that a running node can not use it while it's replaced.
+func replaceFile(file, restoredFile string) error {
+	oldFile, err := oldFileName(file)
+	if err != nil {
+		return err
+	}
+
+	db, err := openFile(file, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := os.Rename(file, oldFile); err != nil {
+		return err
+	}
+	if err := os.Rename(restoredFile, file); err != nil {
+		os.Rename(oldFile, file)
+		return err
+	}
+	return nil
+}
+
+// oldFileName returns name for the replaced database file, which does not overwrite earlier replaced one
+func oldFileName(file string) (string, error) {
+	oldFile := file + ".old"
+	if _, err := os.Stat(oldFile); os.IsNotExist(err) {
+		return oldFile, nil
+	}
+
+	oldFile = fmt.Sprintf("%s.%s.old", file, time.Now().UTC().Format("20060102T150405Z"))
+	if _, err := os.Stat(oldFile); os.IsNotExist(err) {
+		return oldFile, nil
+	}
+	return "", fmt.Errorf("replaced database file already exists: %s", oldFile)
+}
+
+// openFile opens existing database file without creating a new one
+func openFile(file string, readOnly bool) (*bolt.DB, error) {
+	if _, err := os.Stat(file); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(file, 0600, &bolt.Options{Timeout: fileOpenTimeout, ReadOnly: readOnly})
+	if err == bolt.ErrTimeout {
+		return nil, ErrDatabaseInUse
+	}
+	return db, err
+}
+
+func writeSnapshot(db *bolt.DB, w io.Writer) (size int64, err error) {
+	err = db.View(func(tx *bolt.Tx) error {
+		size, err = tx.WriteTo(w)
+		return err
+	})
+	return size, err
+}
+
+func copyFile(source, destination string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(destination, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(destination)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(destination)
+		return err
+	}
+	return out.Close()
+}
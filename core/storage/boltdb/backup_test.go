@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	recordSchema    = SchemaOf(testBucket, record{})
+	backupMigration = migrations.Migration{Name: "backup-test", Date: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)}
+)
+
+func createBackup(t *testing.T, dir string, bolt *Bolt) string {
+	backupFile := filepath.Join(dir, "backup.db")
+	file, err := os.Create(backupFile)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	size, err := bolt.Backup(file)
+	assert.Nil(t, err)
+	assert.True(t, size > 0)
+	return backupFile
+}
+
+func TestSchemaOf(t *testing.T) {
+	assert.Equal(t, Schema{Bucket: "records", Record: "record", Fields: []string{"ID", "Kind", "Value"}}, recordSchema)
+	assert.Equal(t,
+		Schema{Bucket: "migrations", Record: "Migration", Fields: []string{"Name", "Date"}},
+		SchemaOf("migrations", &migrations.Migration{}),
+	)
+}
+
+func TestBackupIsHealthy(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+	assert.Nil(t, NewMigrator(bolt).RunMigrations([]migrations.Migration{backupMigration}))
+
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+	backupFile := createBackup(t, dir, bolt)
+
+	report, err := CheckFile(backupFile, []Schema{recordSchema}, []migrations.Migration{backupMigration})
+	assert.Nil(t, err)
+	assert.True(t, report.Healthy())
+	assert.Equal(t,
+		[]BucketReport{{Name: "migrations", Records: 1}, {Name: testBucket, Records: len(testRecords)}},
+		report.Buckets,
+	)
+	assert.Len(t, report.UnknownBuckets, 0)
+	assert.Len(t, report.UnknownMigrations, 0)
+}
+
+func TestCheckReportsUnknownContents(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+	assert.Nil(t, NewMigrator(bolt).RunMigrations([]migrations.Migration{backupMigration}))
+	assert.Nil(t, bolt.Store("other", &record{ID: 1}))
+
+	otherSchema := Schema{Bucket: "rec*", Record: "record", Fields: []string{"ID", "Kind"}}
+	report, err := bolt.Check([]Schema{otherSchema}, nil)
+	assert.Nil(t, err)
+	assert.False(t, report.Healthy())
+	assert.Equal(t, []string{"other"}, report.UnknownBuckets)
+	assert.Equal(t, []string{backupMigration.Name}, report.UnknownMigrations)
+	assert.Len(t, report.Buckets, 2)
+	assert.Equal(t, testBucket, report.Buckets[1].Name)
+	assert.Len(t, report.Buckets[1].Problems, len(testRecords))
+}
+
+func TestBackupFileRefusesDatabaseInUse(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+	bolt, err := NewStorage(dir)
+	assert.Nil(t, err)
+	defer bolt.Close()
+
+	_, err = BackupFile(DatabaseFile(dir), ioutil.Discard)
+	assert.Equal(t, ErrDatabaseInUse, err)
+}
+
+func TestRestore(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+	assert.Nil(t, NewMigrator(bolt).RunMigrations([]migrations.Migration{backupMigration}))
+
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+	backupFile := createBackup(t, dir, bolt)
+
+	restoreDir := filepath.Join(dir, "db")
+	assert.Nil(t, os.Mkdir(restoreDir, 0700))
+	restored, err := NewStorage(restoreDir)
+	assert.Nil(t, err)
+	assert.Nil(t, restored.Close())
+
+	_, err = Restore(DatabaseFile(restoreDir), backupFile, []Schema{recordSchema}, nil)
+	assert.Equal(t, ErrUnknownMigrations, err)
+
+	_, err = Restore(DatabaseFile(restoreDir), backupFile, []Schema{recordSchema}, []migrations.Migration{backupMigration})
+	assert.Nil(t, err)
+	_, err = os.Stat(DatabaseFile(restoreDir) + ".old")
+	assert.Nil(t, err)
+
+	// earlier replaced database file is kept
+	_, err = Restore(DatabaseFile(restoreDir), backupFile, []Schema{recordSchema}, []migrations.Migration{backupMigration})
+	assert.Nil(t, err)
+	oldFiles, err := filepath.Glob(DatabaseFile(restoreDir) + ".*.old")
+	assert.Nil(t, err)
+	assert.Len(t, oldFiles, 1)
+
+	restored, err = NewStorage(restoreDir)
+	assert.Nil(t, err)
+	defer restored.Close()
+	var records []record
+	assert.Nil(t, restored.GetAllFrom(testBucket, &records))
+	assert.Equal(t, testRecords, records)
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations"
+	bolt "go.etcd.io/bbolt"
+)
+
+// storm keeps its metadata and indexes in buckets with this prefix
+const stormPrefix = "__storm"
+
+// maxBucketProblems limits count of problems reported for a single bucket
+const maxBucketProblems = 10
+
+// Schema describes records kept by storm in a bucket
+type Schema struct {
+	// Bucket is the name of bucket, trailing "*" matches all buckets with the given prefix
+	Bucket string
+	// Record is the name of record struct type, storm keeps records in the nested bucket named after it
+	Record string
+	// Fields are the keys of JSON encoded records
+	Fields []string
+}
+
+// SchemaOf describes the bucket keeping records of the given struct type
+func SchemaOf(bucket string, record interface{}) Schema {
+	recordType := reflect.Indirect(reflect.ValueOf(record)).Type()
+	schema := Schema{Bucket: bucket, Record: recordType.Name()}
+	for i := 0; i < recordType.NumField(); i++ {
+		field := recordType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		schema.Fields = append(schema.Fields, name)
+	}
+	return schema
+}
+
+func (schema Schema) matches(bucket string) bool {
+	if strings.HasSuffix(schema.Bucket, "*") {
+		return strings.HasPrefix(bucket, strings.TrimSuffix(schema.Bucket, "*"))
+	}
+	return bucket == schema.Bucket
+}
+
+func (schema Schema) hasField(name string) bool {
+	for _, field := range schema.Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckReport describes database contents compared to known schemas and migrations
+type CheckReport struct {
+	Buckets           []BucketReport
+	UnknownBuckets    []string
+	UnknownMigrations []string
+}
+
+// Healthy tells whether all records of known buckets match their schemas and all applied migrations are known
+func (report CheckReport) Healthy() bool {
+	if len(report.UnknownMigrations) > 0 {
+		return false
+	}
+	for _, bucket := range report.Buckets {
+		if len(bucket.Problems) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// BucketReport describes contents of a single bucket
+type BucketReport struct {
+	Name     string
+	Records  int
+	Problems []string
+}
+
+func (report *BucketReport) problem(format string, args ...interface{}) {
+	if len(report.Problems) < maxBucketProblems {
+		report.Problems = append(report.Problems, fmt.Sprintf(format, args...))
+	}
+}
+
+// Check verifies contents of the database against given schemas and migrations sequence
+func (b *Bolt) Check(schemas []Schema, sequence []migrations.Migration) (report CheckReport, err error) {
+	err = b.db.Bolt.View(func(tx *bolt.Tx) error {
+		report, err = check(tx, schemas, sequence)
+		return err
+	})
+	return report, err
+}
+
+// CheckFile verifies contents of the given database file against given schemas and migrations sequence.
+// ErrDatabaseInUse is returned when the file is used by a running node.
+func CheckFile(file string, schemas []Schema, sequence []migrations.Migration) (report CheckReport, err error) {
+	db, err := openFile(file, true)
+	if err != nil {
+		return report, err
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		report, err = check(tx, schemas, sequence)
+		return err
+	})
+	return report, err
+}
+
+func check(tx *bolt.Tx, schemas []Schema, sequence []migrations.Migration) (CheckReport, error) {
+	var report CheckReport
+	schemas = append([]Schema{SchemaOf(migrationIndexBucketName, migrations.Migration{})}, schemas...)
+
+	err := tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+		if strings.HasPrefix(string(name), stormPrefix) {
+			return nil
+		}
+		for _, schema := range schemas {
+			if schema.matches(string(name)) {
+				report.Buckets = append(report.Buckets, checkBucket(string(name), bucket, schema))
+				return nil
+			}
+		}
+		report.UnknownBuckets = append(report.UnknownBuckets, string(name))
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	applied, err := appliedMigrations(tx)
+	if err != nil {
+		return report, err
+	}
+	for _, name := range applied {
+		if !knownMigration(sequence, name) {
+			report.UnknownMigrations = append(report.UnknownMigrations, name)
+		}
+	}
+	return report, nil
+}
+
+func checkBucket(name string, bucket *bolt.Bucket, schema Schema) BucketReport {
+	report := BucketReport{Name: name}
+	bucket.ForEach(func(key, value []byte) error {
+		if strings.HasPrefix(string(key), stormPrefix) {
+			return nil
+		}
+		if value != nil || string(key) != schema.Record {
+			report.problem("unknown key %q", key)
+			return nil
+		}
+
+		return bucket.Bucket(key).ForEach(func(id, value []byte) error {
+			if value == nil || strings.HasPrefix(string(id), stormPrefix) {
+				return nil
+			}
+			report.Records++
+
+			var record map[string]json.RawMessage
+			if err := json.Unmarshal(value, &record); err != nil {
+				report.problem("record %q is not valid: %v", id, err)
+				return nil
+			}
+			for field := range record {
+				if !schema.hasField(field) {
+					report.problem("record %q has unknown field %q", id, field)
+				}
+			}
+			return nil
+		})
+	})
+	return report
+}
+
+func appliedMigrations(tx *bolt.Tx) ([]string, error) {
+	bucket := tx.Bucket([]byte(migrationIndexBucketName))
+	if bucket == nil {
+		return nil, nil
+	}
+	records := bucket.Bucket([]byte(reflect.TypeOf(migrations.Migration{}).Name()))
+	if records == nil {
+		return nil, nil
+	}
+
+	var names []string
+	err := records.ForEach(func(id, value []byte) error {
+		if value == nil || strings.HasPrefix(string(id), stormPrefix) {
+			return nil
+		}
+		var migration migrations.Migration
+		if err := json.Unmarshal(value, &migration); err != nil {
+			return err
+		}
+		names = append(names, migration.Name)
+		return nil
+	})
+	return names, err
+}
+
+func knownMigration(sequence []migrations.Migration, name string) bool {
+	for _, migration := range sequence {
+		if migration.Name == name {
+			return true
+		}
+	}
+	return false
+}
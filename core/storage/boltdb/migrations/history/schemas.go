@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package history
+
+import (
+	"github.com/mysteriumnetwork/node/consumer/preferences"
+	"github.com/mysteriumnetwork/node/consumer/quality"
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/promise"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	identity_registry "github.com/mysteriumnetwork/node/identity/registry"
+	proposals_repository "github.com/mysteriumnetwork/node/market/proposals/repository"
+)
+
+// Schemas describes the buckets kept in boltdb by the current version, as of the last migration in Sequence
+var Schemas = []boltdb.Schema{
+	boltdb.SchemaOf("session-history", consumer_session.History{}),
+	boltdb.SchemaOf("provider-quality", quality.Record{}),
+	boltdb.SchemaOf("provider-preferences", preferences.Entry{}),
+	// promises are kept in buckets of their issuer identities
+	boltdb.SchemaOf("0x*", promise.Promise{}),
+	boltdb.SchemaOf(proposals_repository.StoredProposals()),
+	boltdb.SchemaOf(identity_registry.StoredCursors()),
+}
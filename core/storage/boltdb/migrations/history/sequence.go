@@ -31,4 +31,10 @@ var Sequence = []migrations.Migration{
 			2018, 12, 04, 12, 00, 00, 0, time.UTC),
 		Migrate: migrations.MigrateSessionToHistory,
 	},
+	{
+		Name: "session-history-indexes",
+		Date: time.Date(
+			2019, 06, 20, 12, 00, 00, 0, time.UTC),
+		Migrate: migrations.MigrateSessionHistoryIndexes,
+	},
 }
@@ -0,0 +1,33 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"github.com/asdine/storm"
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+)
+
+// MigrateSessionHistoryIndexes builds indexes of session history records saved before the fields were indexed
+func MigrateSessionHistoryIndexes(db *storm.DB) error {
+	err := db.From("session-history").ReIndex(&consumer_session.History{})
+	if err == storm.ErrNotFound {
+		// nothing to index, session history bucket is created on the first save
+		return nil
+	}
+	return err
+}
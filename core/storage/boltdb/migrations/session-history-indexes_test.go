@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"testing"
+	"time"
+
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	node_session "github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionHistoryIndexesMigrationWithNoData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	err := MigrateSessionHistoryIndexes(db)
+	assert.Nil(t, err)
+}
+
+func TestSessionHistoryIndexesMigrationWithData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	// History as it was stored before its fields were indexed
+	type History struct {
+		SessionID   node_session.ID `storm:"id"`
+		ServiceType string
+		Started     time.Time
+		Status      string
+	}
+	err := db.From(bucketName).Save(&History{
+		SessionID:   sessionID,
+		ServiceType: serviceType,
+		Started:     timeStarted,
+		Status:      consumer_session.SessionStatusCompleted,
+	})
+	assert.Nil(t, err)
+
+	err = MigrateSessionHistoryIndexes(db)
+	assert.Nil(t, err)
+
+	histories := []consumer_session.History{}
+	err = db.From(bucketName).Find("ServiceType", serviceType, &histories)
+	assert.Nil(t, err)
+	assert.Len(t, histories, 1)
+	assert.Equal(t, sessionID, histories[0].SessionID)
+
+	histories = []consumer_session.History{}
+	err = db.From(bucketName).Range("Started", timeStarted.Add(-time.Minute), timeStarted.Add(time.Minute), &histories)
+	assert.Nil(t, err)
+	assert.Len(t, histories, 1)
+}
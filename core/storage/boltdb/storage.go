@@ -19,47 +19,139 @@ package boltdb
 
 import (
 	"path/filepath"
+	"reflect"
 
 	"github.com/asdine/storm"
+	"github.com/asdine/storm/index"
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/core/storage"
 )
 
+const boltLogPrefix = "[boltdb] "
+const dbFileName = "myst.db"
+
 // Bolt is a wrapper around boltdb
 type Bolt struct {
+	nodeStorage
 	db *storm.DB
 }
 
 // NewStorage creates a new BoltDB storage for service promises
 func NewStorage(path string) (*Bolt, error) {
-	return openDB(filepath.Join(path, "myst.db"))
+	return openDB(DatabaseFile(path))
+}
+
+// DatabaseFile returns the path of database file kept in the given storage directory
+func DatabaseFile(path string) string {
+	return filepath.Join(path, dbFileName)
 }
 
 // openDB creates new or open existing BoltDB
 func openDB(name string) (*Bolt, error) {
 	db, err := storm.Open(name)
-	return &Bolt{db}, err
+	return &Bolt{nodeStorage{db}, db}, err
+}
+
+// Transaction runs the given function in a read-write transaction.
+// All storage operations performed by the function are committed if it succeeds and rolled back otherwise.
+func (b *Bolt) Transaction(fn func(tx storage.Tx) error) error {
+	node, err := b.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			if rollbackErr := node.Rollback(); rollbackErr != nil {
+				log.Error(boltLogPrefix, "transaction rollback failed: ", rollbackErr)
+			}
+			panic(p)
+		}
+	}()
+
+	if err := fn(&nodeStorage{node}); err != nil {
+		if rollbackErr := node.Rollback(); rollbackErr != nil {
+			log.Error(boltLogPrefix, "transaction rollback failed: ", rollbackErr)
+		}
+		return err
+	}
+	return node.Commit()
+}
+
+// Close closes database
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
+
+// nodeStorage performs storage operations on a storm node, which is either the whole database or a transaction
+type nodeStorage struct {
+	node storm.Node
 }
 
 // Store allows to keep struct grouped by the bucket
-func (b *Bolt) Store(bucket string, data interface{}) error {
-	return b.db.From(bucket).Save(data)
+func (s *nodeStorage) Store(bucket string, data interface{}) error {
+	return s.node.From(bucket).Save(data)
 }
 
 // GetAllFrom allows to get all structs from the bucket
-func (b *Bolt) GetAllFrom(bucket string, data interface{}) error {
-	return b.db.From(bucket).All(data)
+func (s *nodeStorage) GetAllFrom(bucket string, data interface{}) error {
+	return s.node.From(bucket).All(data)
+}
+
+// Find allows to get structs selected by the query from the bucket
+func (s *nodeStorage) Find(bucket string, query storage.Query, array interface{}) error {
+	node := s.node.From(bucket)
+	options := queryOptions(query)
+	switch {
+	case query.Field == "":
+		return node.All(array, options...)
+	case query.Value != nil:
+		return ignoreNotFound(node.Find(query.Field, query.Value, array, options...))
+	default:
+		return ignoreNotFound(node.Range(query.Field, query.Min, query.Max, array, options...))
+	}
+}
+
+// Count returns count of structs of the given type selected by the query from the bucket
+func (s *nodeStorage) Count(bucket string, query storage.Query, object interface{}) (int, error) {
+	if query.Field == "" && query.Skip == 0 && query.Limit == 0 {
+		return s.node.From(bucket).Count(object)
+	}
+
+	array := reflect.New(reflect.SliceOf(reflect.Indirect(reflect.ValueOf(object)).Type()))
+	if err := s.Find(bucket, query, array.Interface()); err != nil {
+		return 0, err
+	}
+	return array.Elem().Len(), nil
 }
 
 // Delete removes the given struct from the given bucket
-func (b *Bolt) Delete(bucket string, data interface{}) error {
-	return b.db.From(bucket).DeleteStruct(data)
+func (s *nodeStorage) Delete(bucket string, data interface{}) error {
+	return s.node.From(bucket).DeleteStruct(data)
 }
 
 // Update allows to update the struct in the given bucket
-func (b *Bolt) Update(bucket string, object interface{}) error {
-	return b.db.From(bucket).Update(object)
+func (s *nodeStorage) Update(bucket string, object interface{}) error {
+	return s.node.From(bucket).Update(object)
 }
 
-// Close closes database
-func (b *Bolt) Close() error {
-	return b.db.Close()
+func queryOptions(query storage.Query) []func(*index.Options) {
+	var options []func(*index.Options)
+	if query.Skip > 0 {
+		options = append(options, storm.Skip(query.Skip))
+	}
+	if query.Limit > 0 {
+		options = append(options, storm.Limit(query.Limit))
+	}
+	if query.Reverse {
+		options = append(options, storm.Reverse())
+	}
+	return options
+}
+
+// ignoreNotFound treats an empty query result as a successful one, leaving the result array empty
+func ignoreNotFound(err error) error {
+	if err == storm.ErrNotFound {
+		return nil
+	}
+	return err
 }
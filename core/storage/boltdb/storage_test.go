@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testBucket = "records"
+
+type record struct {
+	ID    int    `storm:"id"`
+	Kind  string `storm:"index"`
+	Value int    `storm:"index"`
+}
+
+var testRecords = []record{
+	{ID: 1, Kind: "a", Value: 30},
+	{ID: 2, Kind: "b", Value: 10},
+	{ID: 3, Kind: "a", Value: 20},
+	{ID: 4, Kind: "a", Value: 40},
+}
+
+func createStorage(t *testing.T) (*Bolt, func()) {
+	dir := boltdbtest.CreateTempDir(t)
+	bolt, err := NewStorage(dir)
+	assert.Nil(t, err)
+
+	for i := range testRecords {
+		assert.Nil(t, bolt.Store(testBucket, &testRecords[i]))
+	}
+	return bolt, func() {
+		assert.Nil(t, bolt.Close())
+		boltdbtest.RemoveTempDir(t, dir)
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+
+	var records []record
+	err := bolt.Find(testBucket, storage.Query{}, &records)
+	assert.Nil(t, err)
+	assert.Equal(t, testRecords, records)
+}
+
+func TestFindByValue(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+
+	var records []record
+	err := bolt.Find(testBucket, storage.Query{Field: "Kind", Value: "a", Skip: 1, Limit: 1}, &records)
+	assert.Nil(t, err)
+	assert.Equal(t, []record{testRecords[2]}, records)
+
+	records = nil
+	err = bolt.Find(testBucket, storage.Query{Field: "Kind", Value: "c"}, &records)
+	assert.Nil(t, err)
+	assert.Len(t, records, 0)
+}
+
+func TestFindByRange(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+
+	var records []record
+	err := bolt.Find(testBucket, storage.Query{Field: "Value", Min: 15, Max: 40, Reverse: true, Limit: 2}, &records)
+	assert.Nil(t, err)
+	assert.Equal(t, []record{testRecords[3], testRecords[0]}, records)
+
+	records = nil
+	err = bolt.Find(testBucket, storage.Query{Field: "Value", Min: 100, Max: 200}, &records)
+	assert.Nil(t, err)
+	assert.Len(t, records, 0)
+}
+
+func TestCount(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+
+	count, err := bolt.Count(testBucket, storage.Query{}, &record{})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count)
+
+	count, err = bolt.Count(testBucket, storage.Query{Field: "Kind", Value: "a"}, &record{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = bolt.Count(testBucket, storage.Query{Field: "Value", Min: 0, Max: 25}, &record{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestTransactionCommits(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+
+	err := bolt.Transaction(func(tx storage.Tx) error {
+		if err := tx.Delete(testBucket, &testRecords[0]); err != nil {
+			return err
+		}
+		return tx.Store("other", &record{ID: 5, Kind: "c"})
+	})
+	assert.Nil(t, err)
+
+	count, err := bolt.Count(testBucket, storage.Query{}, &record{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+	count, err = bolt.Count("other", storage.Query{}, &record{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestTransactionRollsBackOnError(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+
+	txErr := errors.New("failed")
+	err := bolt.Transaction(func(tx storage.Tx) error {
+		if err := tx.Delete(testBucket, &testRecords[0]); err != nil {
+			return err
+		}
+		if err := tx.Store("other", &record{ID: 5, Kind: "c"}); err != nil {
+			return err
+		}
+		return txErr
+	})
+	assert.Equal(t, txErr, err)
+
+	var records []record
+	assert.Nil(t, bolt.GetAllFrom(testBucket, &records))
+	assert.Equal(t, testRecords, records)
+	count, err := bolt.Count("other", storage.Query{}, &record{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestTransactionRollsBackOnPanic(t *testing.T) {
+	bolt, cleanup := createStorage(t)
+	defer cleanup()
+
+	assert.Panics(t, func() {
+		bolt.Transaction(func(tx storage.Tx) error {
+			if err := tx.Delete(testBucket, &testRecords[0]); err != nil {
+				return err
+			}
+			panic("failed")
+		})
+	})
+
+	var records []record
+	assert.Nil(t, bolt.GetAllFrom(testBucket, &records))
+	assert.Equal(t, testRecords, records)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+// Query selects records of a bucket by their indexed field.
+// Records which field is equal to Value are selected when Value is set,
+// otherwise records which field is within the inclusive range from Min to Max.
+// Query without Field selects all records of the bucket ordered by their id.
+type Query struct {
+	Field string
+	Value interface{}
+	Min   interface{}
+	Max   interface{}
+
+	// Skip and Limit paginate selected records in the order of the field, Limit 0 means no limit
+	Skip  int
+	Limit int
+	// Reverse selects records in the descending order of the field
+	Reverse bool
+}
+
+// Tx groups storage operations, which are run atomically when performed in a transaction
+type Tx interface {
+	Store(bucket string, data interface{}) error
+	Update(bucket string, object interface{}) error
+	Delete(bucket string, data interface{}) error
+	GetAllFrom(bucket string, array interface{}) error
+	Find(bucket string, query Query, array interface{}) error
+	Count(bucket string, query Query, object interface{}) (int, error)
+}
+
+// Transactor runs storage operations in a transaction, which is rolled back if the given function fails
+type Transactor interface {
+	Transaction(fn func(tx Tx) error) error
+}
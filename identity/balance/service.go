@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package balance
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/payments/contracts/abigen"
+)
+
+// ErrBalanceOverflow is returned when balance in payments contract does not fit into money amount
+var ErrBalanceOverflow = errors.New("balance is too big")
+
+// Balance is MYST balance of identity at the moment it was read from payments contract
+type Balance struct {
+	Amount    money.Money
+	UpdatedAt time.Time
+}
+
+type balanceCaller interface {
+	Balances(opts *bind.CallOpts, identity common.Address) (*big.Int, error)
+}
+
+// Service provides MYST balances of identities kept in payments contract.
+// Balances are cached for given period to avoid querying blockchain on every request
+type Service struct {
+	contract balanceCaller
+	ttl      time.Duration
+	timeNow  func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]Balance
+}
+
+// NewService creates balance service reading balances from payments contract at given address
+func NewService(contractBackend bind.ContractCaller, paymentsAddress common.Address, ttl time.Duration) (*Service, error) {
+	contract, err := abigen.NewIdentityPromisesCaller(paymentsAddress, contractBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	return newService(contract, ttl), nil
+}
+
+func newService(contract balanceCaller, ttl time.Duration) *Service {
+	return &Service{
+		contract: contract,
+		ttl:      ttl,
+		timeNow:  time.Now,
+		cache:    make(map[string]Balance),
+	}
+}
+
+// Get returns balance of given identity, reading it from payments contract when cached one is outdated.
+// Lock is not held while contract is queried, so that slow blockchain does not block other identities.
+func (service *Service) Get(id identity.Identity) (Balance, error) {
+	key := strings.ToLower(id.Address)
+
+	service.lock.Lock()
+	now := service.timeNow()
+	cached, ok := service.cache[key]
+	service.lock.Unlock()
+	if ok && now.Sub(cached.UpdatedAt) < service.ttl {
+		return cached, nil
+	}
+
+	amount, err := service.contract.Balances(&bind.CallOpts{}, common.HexToAddress(id.Address))
+	if err != nil {
+		return Balance{}, err
+	}
+	if !amount.IsUint64() {
+		return Balance{}, ErrBalanceOverflow
+	}
+
+	balance := Balance{
+		Amount:    money.Money{Amount: amount.Uint64(), Currency: money.CURRENCY_MYST},
+		UpdatedAt: now,
+	}
+	service.lock.Lock()
+	service.cache[key] = balance
+	service.lock.Unlock()
+	return balance, nil
+}
+
+// Balance returns balance amount of given identity, it satisfies identity.Balance
+func (service *Service) Balance(id identity.Identity) (uint64, error) {
+	balance, err := service.Get(id)
+	return balance.Amount.Amount, err
+}
+
+var _ identity.Balance = (&Service{}).Balance
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package balance
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/payments/contracts/abigen"
+	"github.com/mysteriumnetwork/payments/mysttoken"
+	"github.com/stretchr/testify/assert"
+)
+
+var testIdentity = identity.FromAddress("0x000000000000000000000000000000000000000A")
+
+func TestServiceReadsBalanceFromPaymentsContract(t *testing.T) {
+	deployerKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	deployer := bind.NewKeyedTransactor(deployerKey)
+
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		deployer.From: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)},
+	})
+
+	tokenAddress, _, _, err := mysttoken.DeployMystToken(deployer, backend)
+	assert.NoError(t, err)
+	backend.Commit()
+
+	paymentsAddress, _, _, err := abigen.DeployIdentityPromises(deployer, backend, tokenAddress, big.NewInt(100))
+	assert.NoError(t, err)
+	backend.Commit()
+
+	service, err := NewService(backend, paymentsAddress, time.Minute)
+	assert.NoError(t, err)
+
+	balance, err := service.Get(testIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, money.Money{Amount: 0, Currency: money.CURRENCY_MYST}, balance.Amount)
+	assert.False(t, balance.UpdatedAt.IsZero())
+}
+
+func TestServiceCachesBalance(t *testing.T) {
+	contract := &fakeBalanceCaller{balance: big.NewInt(1500)}
+	service := newService(contract, time.Minute)
+	now := time.Date(2018, 11, 1, 10, 0, 0, 0, time.UTC)
+	service.timeNow = func() time.Time { return now }
+
+	balance, err := service.Get(testIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, money.Money{Amount: 1500, Currency: money.CURRENCY_MYST}, balance.Amount)
+	assert.Equal(t, now, balance.UpdatedAt)
+	assert.Equal(t, common.HexToAddress(testIdentity.Address), contract.lastIdentity)
+
+	contract.balance = big.NewInt(2000)
+	now = now.Add(30 * time.Second)
+	amount, err := service.Balance(identity.Identity{Address: "0x000000000000000000000000000000000000000A"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1500), amount)
+	assert.Equal(t, 1, contract.calls)
+
+	now = now.Add(30 * time.Second)
+	amount, err = service.Balance(testIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2000), amount)
+	assert.Equal(t, 2, contract.calls)
+}
+
+func TestServiceReturnsContractError(t *testing.T) {
+	service := newService(&fakeBalanceCaller{err: errors.New("no contract code at given address")}, time.Minute)
+
+	_, err := service.Get(testIdentity)
+	assert.EqualError(t, err, "no contract code at given address")
+
+	_, err = service.Balance(testIdentity)
+	assert.Error(t, err)
+}
+
+func TestServiceRejectsTooBigBalance(t *testing.T) {
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 64)
+	service := newService(&fakeBalanceCaller{balance: tooBig}, time.Minute)
+
+	_, err := service.Get(testIdentity)
+	assert.Equal(t, ErrBalanceOverflow, err)
+}
+
+type fakeBalanceCaller struct {
+	balance      *big.Int
+	err          error
+	calls        int
+	lastIdentity common.Address
+}
+
+func (caller *fakeBalanceCaller) Balances(opts *bind.CallOpts, identity common.Address) (*big.Int, error) {
+	caller.calls++
+	caller.lastIdentity = identity
+	return caller.balance, caller.err
+}
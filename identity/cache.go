@@ -65,6 +65,23 @@ func (ic *IdentityCache) StoreIdentity(identity Identity) error {
 	return ic.writeCache(cache)
 }
 
+// ForgetIdentity removes cache when it holds given identity
+func (ic *IdentityCache) ForgetIdentity(identity Identity) error {
+	if !ic.cacheExists() {
+		return nil
+	}
+
+	cache, err := ic.readCache()
+	if err != nil {
+		return err
+	}
+	if cache.Identity != identity {
+		return nil
+	}
+
+	return os.Remove(ic.File)
+}
+
 func (ic *IdentityCache) cacheExists() bool {
 	if _, err := os.Stat(ic.File); os.IsNotExist(err) {
 		return false
@@ -36,3 +36,11 @@ func (icf *identityCacheFake) StoreIdentity(identity Identity) error {
 	icf.identity = identity
 	return nil
 }
+
+// ForgetIdentity removes identity if it is the cached one
+func (icf *identityCacheFake) ForgetIdentity(identity Identity) error {
+	if icf.identity == identity {
+		icf.identity = Identity{}
+	}
+	return nil
+}
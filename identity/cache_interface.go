@@ -21,4 +21,5 @@ package identity
 type IdentityCacheInterface interface {
 	GetIdentity() (identity Identity, err error)
 	StoreIdentity(identity Identity) error
+	ForgetIdentity(identity Identity) error
 }
@@ -75,3 +75,24 @@ func TestIdentityCache_cacheExists(t *testing.T) {
 	_, err = os.Stat(file)
 	assert.True(t, err == nil && !os.IsNotExist(err))
 }
+
+func TestIdentityCache_ForgetIdentity(t *testing.T) {
+	identity := FromAddress("0x000000000000000000000000000000000000000A")
+	cache := IdentityCache{
+		File: file,
+	}
+
+	err := cache.StoreIdentity(identity)
+	assert.Nil(t, err)
+
+	err = cache.ForgetIdentity(FromAddress("0x000000000000000000000000000000000000000B"))
+	assert.Nil(t, err)
+	assert.True(t, cache.cacheExists())
+
+	err = cache.ForgetIdentity(identity)
+	assert.Nil(t, err)
+	assert.False(t, cache.cacheExists())
+
+	err = cache.ForgetIdentity(identity)
+	assert.Nil(t, err)
+}
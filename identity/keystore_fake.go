@@ -18,16 +18,20 @@
 package identity
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 type keyStoreFake struct {
-	AccountsMock []accounts.Account
-	ErrorMock    error
-	LastHash     []byte
+	AccountsMock    []accounts.Account
+	ErrorMock       error
+	DeleteErrorMock error
+	LastHash        []byte
+	LockedMock      []common.Address
 }
 
 func (keyStore *keyStoreFake) Accounts() []accounts.Account {
@@ -55,6 +59,15 @@ func (keyStore *keyStoreFake) Unlock(a accounts.Account, passphrase string) erro
 	return nil
 }
 
+func (keyStore *keyStoreFake) Lock(addr common.Address) error {
+	if keyStore.ErrorMock != nil {
+		return keyStore.ErrorMock
+	}
+
+	keyStore.LockedMock = append(keyStore.LockedMock, addr)
+	return nil
+}
+
 func (keyStore *keyStoreFake) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
 	if keyStore.ErrorMock != nil {
 		return []byte{}, keyStore.ErrorMock
@@ -77,3 +90,52 @@ func (keyStore *keyStoreFake) Find(a accounts.Account) (accounts.Account, error)
 
 	return a, errors.New("account not found")
 }
+
+func (keyStore *keyStoreFake) Export(a accounts.Account, passphrase, newPassphrase string) ([]byte, error) {
+	if keyStore.ErrorMock != nil {
+		return nil, keyStore.ErrorMock
+	}
+
+	return []byte(fmt.Sprintf(`{"address":"%s"}`, a.Address.Hex()[2:])), nil
+}
+
+func (keyStore *keyStoreFake) Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error) {
+	if keyStore.ErrorMock != nil {
+		return accounts.Account{}, keyStore.ErrorMock
+	}
+
+	var key struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyJSON, &key); err != nil {
+		return accounts.Account{}, err
+	}
+
+	accountNew := accounts.Account{
+		Address: common.HexToAddress(key.Address),
+	}
+	keyStore.AccountsMock = append(keyStore.AccountsMock, accountNew)
+
+	return accountNew, nil
+}
+
+func (keyStore *keyStoreFake) Update(a accounts.Account, passphrase, newPassphrase string) error {
+	return keyStore.ErrorMock
+}
+
+func (keyStore *keyStoreFake) Delete(a accounts.Account, passphrase string) error {
+	if keyStore.ErrorMock != nil {
+		return keyStore.ErrorMock
+	}
+	if keyStore.DeleteErrorMock != nil {
+		return keyStore.DeleteErrorMock
+	}
+
+	for i, acc := range keyStore.AccountsMock {
+		if acc.Address == a.Address {
+			keyStore.AccountsMock = append(keyStore.AccountsMock[:i], keyStore.AccountsMock[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("account not found")
+}
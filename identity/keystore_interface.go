@@ -17,7 +17,10 @@
 
 package identity
 
-import "github.com/ethereum/go-ethereum/accounts"
+import (
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
 
 // Keystore allows actions with accounts (listing, creating, unlocking, signing)
 type Keystore interface {
@@ -25,5 +28,10 @@ type Keystore interface {
 	NewAccount(passphrase string) (accounts.Account, error)
 	Find(a accounts.Account) (accounts.Account, error)
 	Unlock(a accounts.Account, passphrase string) error
+	Lock(addr common.Address) error
 	SignHash(a accounts.Account, hash []byte) ([]byte, error)
+	Export(a accounts.Account, passphrase, newPassphrase string) ([]byte, error)
+	Import(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error)
+	Update(a accounts.Account, passphrase, newPassphrase string) error
+	Delete(a accounts.Account, passphrase string) error
 }
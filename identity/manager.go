@@ -21,20 +21,47 @@
 package identity
 
 import (
+	"encoding/json"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 )
 
+var (
+	// ErrIdentityExists is returned when imported identity is already in keystore
+	ErrIdentityExists = errors.New("identity already exists")
+	// ErrInvalidPassphrase is returned when identity key can not be decrypted with given passphrase
+	ErrInvalidPassphrase = errors.New("invalid passphrase")
+	// ErrIdentityInUse is returned when identity needed by running service or active connection is being locked
+	ErrIdentityInUse = errors.New("identity is in use")
+)
+
 type identityManager struct {
 	keystoreManager Keystore
+	policy          UnlockPolicy
+	timeNow         func() time.Time
+
+	unlockLock sync.Mutex
+	unlocked   map[string]*unlockState
+	stop       chan struct{}
 }
 
-// NewIdentityManager creates and returns new identityManager
+// NewIdentityManager creates and returns new identityManager, which keeps identities unlocked until locked explicitly
 func NewIdentityManager(keystore Keystore) *identityManager {
+	return NewIdentityManagerWithPolicy(keystore, UnlockPolicy{})
+}
+
+// NewIdentityManagerWithPolicy creates and returns new identityManager, which locks identities according to given policy
+func NewIdentityManagerWithPolicy(keystore Keystore, policy UnlockPolicy) *identityManager {
 	return &identityManager{
 		keystoreManager: keystore,
+		policy:          policy,
+		timeNow:         time.Now,
+		unlocked:        make(map[string]*unlockState),
 	}
 }
 
@@ -88,12 +115,127 @@ func (idm *identityManager) HasIdentity(address string) bool {
 }
 
 func (idm *identityManager) Unlock(address string, passphrase string) error {
+	return idm.UnlockWithTimeout(address, passphrase, 0)
+}
+
+// UnlockWithTimeout unlocks identity until timeout passes, zero timeout keeps identity unlocked until it gets idle
+func (idm *identityManager) UnlockWithTimeout(address string, passphrase string, timeout time.Duration) error {
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return err
+	}
+
+	if err := idm.keystoreManager.Unlock(account, passphrase); err != nil {
+		return err
+	}
+
+	now := idm.timeNow()
+	state := &unlockState{lastActive: now}
+	if timeout > 0 {
+		state.deadline = now.Add(timeout)
+	}
+
+	idm.unlockLock.Lock()
+	defer idm.unlockLock.Unlock()
+	idm.unlocked[accountToIdentity(account).Address] = state
+	return nil
+}
+
+// Lock locks identity unless it is used by running service or active connection
+func (idm *identityManager) Lock(address string) error {
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return err
+	}
+
+	id := accountToIdentity(account)
+	if idm.policy.InUse != nil && idm.policy.InUse(id) {
+		return ErrIdentityInUse
+	}
+
+	idm.unlockLock.Lock()
+	defer idm.unlockLock.Unlock()
+	delete(idm.unlocked, id.Address)
+	return idm.keystoreManager.Lock(account.Address)
+}
+
+// ExportIdentity returns encrypted key of identity, re-encrypted with newPassphrase
+func (idm *identityManager) ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error) {
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	keyJSON, err := idm.keystoreManager.Export(account, passphrase, newPassphrase)
+	if err == keystore.ErrDecrypt {
+		return nil, ErrInvalidPassphrase
+	}
+	return keyJSON, err
+}
+
+// ImportIdentity decrypts exported key with passphrase and stores it in keystore encrypted with newPassphrase
+func (idm *identityManager) ImportIdentity(keyJSON []byte, passphrase, newPassphrase string) (identity Identity, err error) {
+	var key struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyJSON, &key); err != nil {
+		return identity, err
+	}
+	if key.Address != "" && idm.HasIdentity(key.Address) {
+		return identity, ErrIdentityExists
+	}
+
+	account, err := idm.keystoreManager.Import(keyJSON, passphrase, newPassphrase)
+	if err == keystore.ErrDecrypt {
+		return identity, ErrInvalidPassphrase
+	}
+	if err != nil {
+		return identity, err
+	}
+
+	return accountToIdentity(account), nil
+}
+
+// ChangePassphrase re-encrypts identity key with newPassphrase
+func (idm *identityManager) ChangePassphrase(address, passphrase, newPassphrase string) error {
 	account, err := idm.findAccount(address)
 	if err != nil {
 		return err
 	}
 
-	return idm.keystoreManager.Unlock(account, passphrase)
+	err = idm.keystoreManager.Update(account, passphrase, newPassphrase)
+	if err == keystore.ErrDecrypt {
+		return ErrInvalidPassphrase
+	}
+	return err
+}
+
+// DeleteIdentity removes identity key from keystore unless it is used by running service or active connection.
+// Identity is locked after deletion, as keystore keeps unlocked keys of deleted accounts.
+func (idm *identityManager) DeleteIdentity(address, passphrase string) error {
+	account, err := idm.findAccount(address)
+	if err != nil {
+		return err
+	}
+
+	idm.unlockLock.Lock()
+	defer idm.unlockLock.Unlock()
+
+	id := accountToIdentity(account)
+	if idm.policy.InUse != nil && idm.policy.InUse(id) {
+		return ErrIdentityInUse
+	}
+
+	err = idm.keystoreManager.Delete(account, passphrase)
+	if err == keystore.ErrDecrypt {
+		return ErrInvalidPassphrase
+	}
+	if err != nil {
+		return err
+	}
+
+	delete(idm.unlocked, id.Address)
+	return idm.keystoreManager.Lock(account.Address)
 }
 
 func (idm *identityManager) findAccount(address string) (accounts.Account, error) {
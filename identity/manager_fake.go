@@ -17,7 +17,10 @@
 
 package identity
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type idmFake struct {
 	LastUnlockAddress    string
@@ -25,18 +28,29 @@ type idmFake struct {
 	existingIdentities   []Identity
 	newIdentity          Identity
 	unlockFails          bool
+	identityInUse        bool
+	LastDeletedAddress   string
+	LastUnlockTimeout    time.Duration
+	LastLockAddress      string
 }
 
 // NewIdentityManagerFake creates fake identity manager for testing purposes
 // TODO each caller should use it's own mocked manager part instead of global one
 func NewIdentityManagerFake(existingIdentities []Identity, newIdentity Identity) *idmFake {
-	return &idmFake{"", "", existingIdentities, newIdentity, false}
+	return &idmFake{
+		existingIdentities: existingIdentities,
+		newIdentity:        newIdentity,
+	}
 }
 
 func (fakeIdm *idmFake) MarkUnlockToFail() {
 	fakeIdm.unlockFails = true
 }
 
+func (fakeIdm *idmFake) MarkIdentityInUse() {
+	fakeIdm.identityInUse = true
+}
+
 func (fakeIdm *idmFake) CreateNewIdentity(_ string) (Identity, error) {
 	return fakeIdm.newIdentity, nil
 }
@@ -56,10 +70,59 @@ func (fakeIdm *idmFake) HasIdentity(_ string) bool {
 }
 
 func (fakeIdm *idmFake) Unlock(address string, passphrase string) error {
+	return fakeIdm.UnlockWithTimeout(address, passphrase, 0)
+}
+
+func (fakeIdm *idmFake) UnlockWithTimeout(address string, passphrase string, timeout time.Duration) error {
 	fakeIdm.LastUnlockAddress = address
+	fakeIdm.LastUnlockTimeout = timeout
 	fakeIdm.LastUnlockPassphrase = passphrase
 	if fakeIdm.unlockFails {
 		return errors.New("Unlock failed")
 	}
 	return nil
 }
+
+func (fakeIdm *idmFake) ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error) {
+	if _, err := fakeIdm.GetIdentity(address); err != nil {
+		return nil, err
+	}
+	return []byte(`{"address":"` + address + `"}`), nil
+}
+
+func (fakeIdm *idmFake) ImportIdentity(keyJSON []byte, passphrase, newPassphrase string) (Identity, error) {
+	return fakeIdm.newIdentity, nil
+}
+
+func (fakeIdm *idmFake) ChangePassphrase(address, passphrase, newPassphrase string) error {
+	if fakeIdm.unlockFails {
+		return ErrInvalidPassphrase
+	}
+	_, err := fakeIdm.GetIdentity(address)
+	return err
+}
+
+func (fakeIdm *idmFake) DeleteIdentity(address, passphrase string) error {
+	if fakeIdm.unlockFails {
+		return ErrInvalidPassphrase
+	}
+	if _, err := fakeIdm.GetIdentity(address); err != nil {
+		return err
+	}
+	if fakeIdm.identityInUse {
+		return ErrIdentityInUse
+	}
+	fakeIdm.LastDeletedAddress = address
+	return nil
+}
+
+func (fakeIdm *idmFake) Lock(address string) error {
+	if _, err := fakeIdm.GetIdentity(address); err != nil {
+		return err
+	}
+	if fakeIdm.identityInUse {
+		return ErrIdentityInUse
+	}
+	fakeIdm.LastLockAddress = address
+	return nil
+}
@@ -17,6 +17,8 @@
 
 package identity
 
+import "time"
+
 // Manager interface exposes identity management methods
 // TODO this interface must decay into caller specific smaller interfaces
 type Manager interface {
@@ -25,4 +27,10 @@ type Manager interface {
 	GetIdentity(address string) (Identity, error)
 	HasIdentity(address string) bool
 	Unlock(address string, passphrase string) error
+	UnlockWithTimeout(address string, passphrase string, timeout time.Duration) error
+	Lock(address string) error
+	ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error)
+	ImportIdentity(keyJSON []byte, passphrase, newPassphrase string) (Identity, error)
+	ChangePassphrase(address, passphrase, newPassphrase string) error
+	DeleteIdentity(address, passphrase string) error
 }
@@ -22,25 +22,23 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
 func newManager(accountValue string) *identityManager {
-	return &identityManager{
-		keystoreManager: &keyStoreFake{
-			AccountsMock: []accounts.Account{
-				addressToAccount(accountValue),
-			},
+	return NewIdentityManager(&keyStoreFake{
+		AccountsMock: []accounts.Account{
+			addressToAccount(accountValue),
 		},
-	}
+	})
 }
 
 func newManagerWithError(errorMock error) *identityManager {
-	return &identityManager{
-		keystoreManager: &keyStoreFake{
-			ErrorMock: errorMock,
-		},
-	}
+	return NewIdentityManager(&keyStoreFake{
+		ErrorMock: errorMock,
+	})
 }
 
 func TestManager_CreateNewIdentity(t *testing.T) {
@@ -95,3 +93,80 @@ func TestManager_HasIdentity(t *testing.T) {
 	assert.True(t, manager.HasIdentity("0x000000000000000000000000000000000000000a"))
 	assert.False(t, manager.HasIdentity("0x000000000000000000000000000000000000000B"))
 }
+
+func TestManager_ExportIdentity(t *testing.T) {
+	manager := newManager("0x000000000000000000000000000000000000000A")
+
+	keyJSON, err := manager.ExportIdentity("0x000000000000000000000000000000000000000a", "old", "new")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"address":"000000000000000000000000000000000000000A"}`, string(keyJSON))
+
+	_, err = manager.ExportIdentity("0x000000000000000000000000000000000000000B", "old", "new")
+	assert.EqualError(t, err, "identity not found: 0x000000000000000000000000000000000000000B")
+}
+
+func TestManager_ImportIdentity(t *testing.T) {
+	manager := newManager("0x000000000000000000000000000000000000000A")
+
+	identity, err := manager.ImportIdentity([]byte(`{"address":"000000000000000000000000000000000000000b"}`), "old", "new")
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{"0x000000000000000000000000000000000000000b"}, identity)
+	assert.True(t, manager.HasIdentity("0x000000000000000000000000000000000000000B"))
+}
+
+func TestManager_ImportExistingIdentity(t *testing.T) {
+	manager := newManager("0x000000000000000000000000000000000000000A")
+
+	_, err := manager.ImportIdentity([]byte(`{"address":"000000000000000000000000000000000000000a"}`), "old", "new")
+	assert.Equal(t, ErrIdentityExists, err)
+	assert.Len(t, manager.keystoreManager.Accounts(), 1)
+}
+
+func TestManager_ImportIdentityWithInvalidPassphrase(t *testing.T) {
+	manager := newManagerWithError(keystore.ErrDecrypt)
+
+	_, err := manager.ImportIdentity([]byte(`{"address":"000000000000000000000000000000000000000b"}`), "wrong", "new")
+	assert.Equal(t, ErrInvalidPassphrase, err)
+}
+
+func TestManager_ImportInvalidKey(t *testing.T) {
+	manager := newManager("0x000000000000000000000000000000000000000A")
+
+	_, err := manager.ImportIdentity([]byte(`not a key`), "old", "new")
+	assert.Error(t, err)
+}
+
+func TestManager_ChangePassphrase(t *testing.T) {
+	manager := newManager("0x000000000000000000000000000000000000000A")
+
+	err := manager.ChangePassphrase("0x000000000000000000000000000000000000000A", "old", "new")
+	assert.NoError(t, err)
+
+	err = manager.ChangePassphrase("0x000000000000000000000000000000000000000B", "old", "new")
+	assert.EqualError(t, err, "identity not found: 0x000000000000000000000000000000000000000B")
+}
+
+func TestManager_DeleteIdentity(t *testing.T) {
+	manager := newManager("0x000000000000000000000000000000000000000A")
+
+	err := manager.DeleteIdentity("0x000000000000000000000000000000000000000A", "passphrase")
+	assert.NoError(t, err)
+	assert.False(t, manager.HasIdentity("0x000000000000000000000000000000000000000A"))
+	assert.Equal(
+		t,
+		[]common.Address{common.HexToAddress("0x000000000000000000000000000000000000000A")},
+		manager.keystoreManager.(*keyStoreFake).LockedMock,
+	)
+
+	err = manager.DeleteIdentity("0x000000000000000000000000000000000000000A", "passphrase")
+	assert.EqualError(t, err, "identity not found: 0x000000000000000000000000000000000000000A")
+}
+
+func TestManager_DeleteIdentityKeepsItUnlockedWhenDeletionFails(t *testing.T) {
+	manager := newManager("0x000000000000000000000000000000000000000A")
+	manager.keystoreManager.(*keyStoreFake).DeleteErrorMock = keystore.ErrDecrypt
+
+	err := manager.DeleteIdentity("0x000000000000000000000000000000000000000A", "wrong")
+	assert.Equal(t, ErrInvalidPassphrase, err)
+	assert.Empty(t, manager.keystoreManager.(*keyStoreFake).LockedMock)
+}
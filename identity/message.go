@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import "strconv"
+
+// messagePrefix is prepended to arbitrary messages before signing them. It starts with a byte
+// which can not start protocol messages, so signatures of arbitrary messages can not be replayed
+// as signatures of protocol messages, i.e. session credentials
+const messagePrefix = "\x19Mysterium Signed Message:\n"
+
+// PrefixMessage returns arbitrary message in the form it is signed by SignMessage
+func PrefixMessage(message []byte) []byte {
+	prefixed := []byte(messagePrefix + strconv.Itoa(len(message)))
+	return append(prefixed, message...)
+}
+
+// SignMessage signs arbitrary message, prefixed to distinguish it from protocol messages
+func SignMessage(signer Signer, message []byte) (Signature, error) {
+	return signer.Sign(PrefixMessage(message))
+}
+
+// ExtractMessageSigner returns identity which signed arbitrary message with SignMessage
+func ExtractMessageSigner(extractor Extractor, message []byte, signature Signature) (Identity, error) {
+	return extractor.Extract(PrefixMessage(message), signature)
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixMessage(t *testing.T) {
+	assert.Equal(t, []byte("\x19Mysterium Signed Message:\n5hello"), PrefixMessage([]byte("hello")))
+}
+
+func TestSignedMessageSignerIsExtracted(t *testing.T) {
+	ks := NewKeystoreFilesystem("test_data", true)
+	signerID := FromAddress("0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+	assert.NoError(t, NewIdentityManager(ks).Unlock(signerID.Address, ""))
+
+	signature, err := SignMessage(NewSigner(ks, signerID), []byte("link to account 42"))
+	assert.NoError(t, err)
+
+	extracted, err := ExtractMessageSigner(NewExtractor(), []byte("link to account 42"), signature)
+	assert.NoError(t, err)
+	assert.Equal(t, signerID, extracted)
+
+	extracted, err = NewExtractor().Extract([]byte("link to account 42"), signature)
+	assert.NoError(t, err)
+	assert.NotEqual(t, signerID, extracted)
+}
@@ -19,7 +19,9 @@ package registry
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/identity"
@@ -63,17 +65,46 @@ type RegistrationDataDTO struct {
 	PublicKey PublicKeyPartsDTO `json:"publicKey"`
 
 	Signature SignatureDTO `json:"signature"`
+
+	// Progress of registration transaction submitted by node, missing when node did not submit it
+	Progress *RegistrationProgressDTO `json:"progress,omitempty"`
+}
+
+// RegistrationProgressDTO represents registration transaction submitted by node
+//
+// swagger:model RegistrationProgressDTO
+type RegistrationProgressDTO struct {
+	// Transaction status - submitted, confirmed or failed
+	// example: submitted
+	Status string `json:"status"`
+	// Account paying for registration transaction
+	// example: 0x0000000000000000000000000000000000000001
+	Payer string `json:"payer"`
+	// Hash of registration transaction, missing when transaction was not sent
+	// example: 0x1321313212312...
+	TxHash string `json:"txHash,omitempty"`
+	// Time when transaction was submitted in RFC3339 format
+	// example: 2018-10-29T16:22:05Z
+	SubmittedAt string `json:"submittedAt"`
+	// Reason of failed registration
+	Error string `json:"error,omitempty"`
 }
 
 type registrationEndpoint struct {
 	dataProvider   RegistrationDataProvider
 	statusProvider IdentityRegistry
+	registrator    IdentityRegistrator
 }
 
-func newRegistrationEndpoint(dataProvider RegistrationDataProvider, statusProvider IdentityRegistry) *registrationEndpoint {
+func newRegistrationEndpoint(
+	dataProvider RegistrationDataProvider,
+	statusProvider IdentityRegistry,
+	registrator IdentityRegistrator,
+) *registrationEndpoint {
 	return &registrationEndpoint{
 		dataProvider:   dataProvider,
 		statusProvider: statusProvider,
+		registrator:    registrator,
 	}
 }
 
@@ -81,6 +112,7 @@ func newRegistrationEndpoint(dataProvider RegistrationDataProvider, statusProvid
 // ---
 // summary: Provide identity registration status
 // description: Provides registration status for given identity, if identity is not registered - provides additional data required for identity registration
+//   and progress of registration transaction submitted by node
 // parameters:
 //   - in: path
 //     name: id
@@ -123,16 +155,103 @@ func (endpoint *registrationEndpoint) IdentityRegistrationData(resp http.Respons
 			V: registrationData.Signature.V,
 		},
 	}
+	if endpoint.registrator != nil {
+		if progress, ok := endpoint.registrator.Progress(id); ok {
+			registrationDataDTO.Progress = toRegistrationProgressDTO(progress)
+		}
+	}
 	utils.WriteAsJSON(registrationDataDTO, resp)
 }
 
-// AddIdentityRegistrationEndpoint adds identity registration data endpoint to given http router
-func AddIdentityRegistrationEndpoint(router *httprouter.Router, dataProvider RegistrationDataProvider, statusProvider IdentityRegistry) {
+// swagger:operation POST /identities/{id}/registration Identity registerIdentity
+// ---
+// summary: Registers identity
+// description: Submits registration transaction of given identity to payments contract. Transaction is paid by configured account or by identity itself
+// parameters:
+//   - in: path
+//     name: id
+//     description: hex address of identity
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Registration transaction submitted
+//     schema:
+//       "$ref": "#/definitions/RegistrationProgressDTO"
+//   409:
+//     description: Identity is already registered or its registration is in progress
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   501:
+//     description: Node is not configured to submit registration transactions
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *registrationEndpoint) RegisterIdentity(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	if endpoint.registrator == nil {
+		utils.SendErrorMessage(resp, "Registration transactions are not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	id := identity.FromAddress(params.ByName("id"))
+	isRegistered, err := endpoint.statusProvider.IsRegistered(id)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	if isRegistered {
+		utils.SendErrorMessage(resp, "Identity is already registered", http.StatusConflict)
+		return
+	}
+
+	progress, err := endpoint.registrator.RegisterIdentity(id)
+	if err == ErrRegistrationInProgress {
+		utils.SendError(resp, err, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json; charset=utf-8")
+	resp.WriteHeader(http.StatusAccepted)
+	utils.WriteAsJSON(toRegistrationProgressDTO(progress), resp)
+}
+
+func toRegistrationProgressDTO(progress RegistrationProgress) *RegistrationProgressDTO {
+	dto := &RegistrationProgressDTO{
+		Status:      string(progress.Status),
+		Payer:       progress.Payer.Hex(),
+		SubmittedAt: progress.SubmittedAt.UTC().Format(time.RFC3339),
+	}
+	if progress.TxHash != (common.Hash{}) {
+		dto.TxHash = progress.TxHash.Hex()
+	}
+	if progress.Error != nil {
+		dto.Error = progress.Error.Error()
+	}
+	return dto
+}
+
+// AddIdentityRegistrationEndpoint adds identity registration endpoints to given http router.
+// Registrator is optional, without it node does not submit registration transactions
+func AddIdentityRegistrationEndpoint(
+	router *httprouter.Router,
+	dataProvider RegistrationDataProvider,
+	statusProvider IdentityRegistry,
+	registrator IdentityRegistrator,
+) {
 
 	registrationEndpoint := newRegistrationEndpoint(
 		dataProvider,
 		statusProvider,
+		registrator,
 	)
 
 	router.GET("/identities/:id/registration", registrationEndpoint.IdentityRegistrationData)
+	router.POST("/identities/:id/registration", registrationEndpoint.RegisterIdentity)
 }
@@ -18,9 +18,13 @@
 package registry
 
 import (
+	"encoding/json"
+	"errors"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/julienschmidt/httprouter"
@@ -54,7 +58,7 @@ func TestIdentityRegistrationEndpointReturnsRegistrationData(t *testing.T) {
 		Registered: false,
 	}
 
-	endpoint := newRegistrationEndpoint(mockedDataProvider, mockedStatusProvider)
+	endpoint := newRegistrationEndpoint(mockedDataProvider, mockedStatusProvider, nil)
 
 	req, err := http.NewRequest(
 		http.MethodGet,
@@ -97,6 +101,130 @@ func TestIdentityRegistrationEndpointReturnsRegistrationData(t *testing.T) {
 
 }
 
+func TestIdentityRegistrationEndpointReturnsRegistrationProgress(t *testing.T) {
+	registrator := &mockRegistrator{
+		progress: &RegistrationProgress{
+			Status:      RegistrationFailed,
+			Payer:       common.HexToAddress("0x1"),
+			SubmittedAt: time.Date(2018, 10, 29, 16, 22, 5, 0, time.UTC),
+			Error:       errors.New("insufficient funds for gas * price + value"),
+		},
+	}
+	endpoint := newRegistrationEndpoint(newMockRegistrationDataProvider(), &mockRegistrationStatus{}, registrator)
+
+	resp := httptest.NewRecorder()
+	endpoint.IdentityRegistrationData(resp, nil, httprouter.Params{{Key: "id", Value: "0x1231323131"}})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"status": "failed",
+			"payer": "0x0000000000000000000000000000000000000001",
+			"submittedAt": "2018-10-29T16:22:05Z",
+			"error": "insufficient funds for gas * price + value"
+		}`,
+		extractJSONField(t, resp.Body.Bytes(), "progress"),
+	)
+}
+
+func TestRegisterIdentitySubmitsTransaction(t *testing.T) {
+	registrator := &mockRegistrator{}
+	endpoint := newRegistrationEndpoint(newMockRegistrationDataProvider(), &mockRegistrationStatus{}, registrator)
+
+	resp := httptest.NewRecorder()
+	endpoint.RegisterIdentity(resp, nil, httprouter.Params{{Key: "id", Value: "0x12"}})
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, identity.FromAddress("0x12"), registrator.registered)
+	assert.JSONEq(
+		t,
+		`{
+			"status": "submitted",
+			"payer": "0x0000000000000000000000000000000000000012",
+			"txHash": "0x0000000000000000000000000000000000000000000000000000000000000001",
+			"submittedAt": "2018-10-29T16:22:05Z"
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestRegisterIdentityFailsForRegisteredIdentity(t *testing.T) {
+	registrator := &mockRegistrator{}
+	endpoint := newRegistrationEndpoint(newMockRegistrationDataProvider(), &mockRegistrationStatus{Registered: true}, registrator)
+
+	resp := httptest.NewRecorder()
+	endpoint.RegisterIdentity(resp, nil, httprouter.Params{{Key: "id", Value: "0x12"}})
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"message": "Identity is already registered"}`, resp.Body.String())
+	assert.Equal(t, identity.Identity{}, registrator.registered)
+}
+
+func TestRegisterIdentityFailsWhenRegistrationInProgress(t *testing.T) {
+	registrator := &mockRegistrator{err: ErrRegistrationInProgress}
+	endpoint := newRegistrationEndpoint(newMockRegistrationDataProvider(), &mockRegistrationStatus{}, registrator)
+
+	resp := httptest.NewRecorder()
+	endpoint.RegisterIdentity(resp, nil, httprouter.Params{{Key: "id", Value: "0x12"}})
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"message": "registration transaction is already submitted"}`, resp.Body.String())
+}
+
+func TestRegisterIdentityWithoutRegistrator(t *testing.T) {
+	endpoint := newRegistrationEndpoint(newMockRegistrationDataProvider(), &mockRegistrationStatus{}, nil)
+
+	resp := httptest.NewRecorder()
+	endpoint.RegisterIdentity(resp, nil, httprouter.Params{{Key: "id", Value: "0x12"}})
+
+	assert.Equal(t, http.StatusNotImplemented, resp.Code)
+}
+
+func extractJSONField(t *testing.T, body []byte, field string) string {
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(body, &fields))
+	return string(fields[field])
+}
+
+func newMockRegistrationDataProvider() *mockRegistrationDataProvider {
+	return &mockRegistrationDataProvider{
+		RegistrationData: &registry.RegistrationData{
+			PublicKey: registry.PublicKeyParts{
+				Part1: common.FromHex(testPublicKeyPart1),
+				Part2: common.FromHex(testPublicKeyPart2),
+			},
+			Signature: &payments_identity.DecomposedSignature{},
+		},
+	}
+}
+
+type mockRegistrator struct {
+	progress   *RegistrationProgress
+	registered identity.Identity
+	err        error
+}
+
+func (m *mockRegistrator) RegisterIdentity(id identity.Identity) (RegistrationProgress, error) {
+	if m.err != nil {
+		return RegistrationProgress{}, m.err
+	}
+	m.registered = id
+	return RegistrationProgress{
+		Status:      RegistrationSubmitted,
+		Payer:       common.HexToAddress(id.Address),
+		TxHash:      common.BigToHash(big.NewInt(1)),
+		SubmittedAt: time.Date(2018, 10, 29, 16, 22, 5, 0, time.UTC),
+	}, nil
+}
+
+func (m *mockRegistrator) Progress(id identity.Identity) (RegistrationProgress, bool) {
+	if m.progress == nil {
+		return RegistrationProgress{}, false
+	}
+	return *m.progress, true
+}
+
 type mockRegistrationStatus struct {
 	Registered bool
 }
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/payments/contracts/abigen"
+)
+
+// registrationConfirmationTimeout limits how long registration transaction is waited to be mined
+const registrationConfirmationTimeout = 10 * time.Minute
+
+// RegistrationStatus describes progress of identity registration transaction
+type RegistrationStatus string
+
+// Possible registration transaction statuses
+const (
+	// RegistrationSubmitted means that transaction was sent and is waiting to be mined
+	RegistrationSubmitted = RegistrationStatus("submitted")
+	// RegistrationConfirmed means that transaction was mined successfully
+	RegistrationConfirmed = RegistrationStatus("confirmed")
+	// RegistrationFailed means that transaction was not sent, was reverted or was not mined in time
+	RegistrationFailed = RegistrationStatus("failed")
+)
+
+// ErrRegistrationInProgress is returned when identity registration transaction is already submitted
+var ErrRegistrationInProgress = errors.New("registration transaction is already submitted")
+
+// RegistrationProgress describes registration transaction submitted for identity
+type RegistrationProgress struct {
+	Status      RegistrationStatus
+	Payer       common.Address
+	TxHash      common.Hash
+	SubmittedAt time.Time
+	Error       error
+}
+
+// IdentityRegistrator submits identity registration transactions to payments contract
+type IdentityRegistrator interface {
+	RegisterIdentity(identity.Identity) (RegistrationProgress, error)
+	Progress(identity.Identity) (RegistrationProgress, bool)
+}
+
+// TransactorFactory creates options to sign transactions on behalf of given payer account
+type TransactorFactory func(payer common.Address) *bind.TransactOpts
+
+// RegistrationBackend allows to send transactions and wait until they are mined
+type RegistrationBackend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+}
+
+// NewRegistrationTransactor creates identity registrator which sends registration transactions.
+// Transactions are paid by payer account, or by identity itself when payer is zero address
+func NewRegistrationTransactor(
+	backend RegistrationBackend,
+	registryAddress common.Address,
+	dataProvider RegistrationDataProvider,
+	transactorFactory TransactorFactory,
+	payer common.Address,
+) (*registrationTransactor, error) {
+	contract, err := abigen.NewIdentityPromisesTransactor(registryAddress, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	return &registrationTransactor{
+		backend:           backend,
+		contract:          contract,
+		dataProvider:      dataProvider,
+		transactorFactory: transactorFactory,
+		payer:             payer,
+		timeout:           registrationConfirmationTimeout,
+		progress:          make(map[identity.Identity]RegistrationProgress),
+		submitting:        make(map[identity.Identity]bool),
+	}, nil
+}
+
+type registrationTransactor struct {
+	backend           RegistrationBackend
+	contract          *abigen.IdentityPromisesTransactor
+	dataProvider      RegistrationDataProvider
+	transactorFactory TransactorFactory
+	payer             common.Address
+	timeout           time.Duration
+
+	lock     sync.Mutex
+	progress map[identity.Identity]RegistrationProgress
+	// submitting holds identities which transactions are being sent, lock is not held while sending
+	submitting map[identity.Identity]bool
+}
+
+// RegisterIdentity sends registration transaction of given identity and tracks its confirmation in background
+func (rt *registrationTransactor) RegisterIdentity(id identity.Identity) (RegistrationProgress, error) {
+	id = identity.FromAddress(id.Address)
+
+	rt.lock.Lock()
+	if rt.submitting[id] {
+		progress := rt.progress[id]
+		rt.lock.Unlock()
+		return progress, ErrRegistrationInProgress
+	}
+	if progress, ok := rt.progress[id]; ok && progress.Status != RegistrationFailed {
+		rt.lock.Unlock()
+		return progress, ErrRegistrationInProgress
+	}
+	rt.submitting[id] = true
+	rt.lock.Unlock()
+
+	payer := rt.payer
+	if payer == (common.Address{}) {
+		payer = common.HexToAddress(id.Address)
+	}
+	progress := RegistrationProgress{
+		Status:      RegistrationFailed,
+		Payer:       payer,
+		SubmittedAt: time.Now(),
+	}
+
+	tx, err := rt.submit(id, payer)
+
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	delete(rt.submitting, id)
+
+	if err != nil {
+		progress.Error = err
+		rt.progress[id] = progress
+		return progress, err
+	}
+
+	log.Info(logPrefix, "registration of identity ", id.Address, " submitted in transaction ", tx.Hash().Hex())
+	progress.Status = RegistrationSubmitted
+	progress.TxHash = tx.Hash()
+	rt.progress[id] = progress
+
+	go rt.waitConfirmation(id, tx)
+	return progress, nil
+}
+
+// Progress returns last registration transaction progress of given identity
+func (rt *registrationTransactor) Progress(id identity.Identity) (RegistrationProgress, bool) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	progress, ok := rt.progress[identity.FromAddress(id.Address)]
+	return progress, ok
+}
+
+func (rt *registrationTransactor) submit(id identity.Identity, payer common.Address) (*types.Transaction, error) {
+	data, err := rt.dataProvider.ProvideRegistrationData(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var pubKeyPart1, pubKeyPart2 [32]byte
+	copy(pubKeyPart1[:], data.PublicKey.Part1)
+	copy(pubKeyPart2[:], data.PublicKey.Part2)
+
+	return rt.contract.RegisterIdentity(
+		rt.transactorFactory(payer),
+		pubKeyPart1,
+		pubKeyPart2,
+		data.Signature.V,
+		data.Signature.R,
+		data.Signature.S,
+	)
+}
+
+func (rt *registrationTransactor) waitConfirmation(id identity.Identity, tx *types.Transaction) {
+	ctx, cancel := context.WithTimeout(context.Background(), rt.timeout)
+	defer cancel()
+
+	receipt, err := bind.WaitMined(ctx, rt.backend, tx)
+	if err == nil && receipt.Status != types.ReceiptStatusSuccessful {
+		err = errors.New("registration transaction was reverted")
+	}
+
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	progress := rt.progress[id]
+	if err != nil {
+		log.Error(logPrefix, "registration of identity ", id.Address, " failed: ", err)
+		progress.Status = RegistrationFailed
+		progress.Error = err
+	} else {
+		log.Info(logPrefix, "registration of identity ", id.Address, " confirmed")
+		progress.Status = RegistrationConfirmed
+	}
+	rt.progress[id] = progress
+}
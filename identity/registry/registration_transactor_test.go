@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/payments/cli/helpers"
+	"github.com/mysteriumnetwork/payments/contracts/abigen"
+	"github.com/mysteriumnetwork/payments/mysttoken"
+	"github.com/stretchr/testify/assert"
+)
+
+const registrationFee = 100
+
+type registrationEnvironment struct {
+	backend         *backends.SimulatedBackend
+	ks              *keystore.KeyStore
+	registryAddress common.Address
+	identity        accounts.Account
+}
+
+// newRegistrationEnvironment deploys payments contract to simulated blockchain and funds unlocked identity with ether and tokens
+func newRegistrationEnvironment(t *testing.T, dir string) *registrationEnvironment {
+	deployerKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	deployer := bind.NewKeyedTransactor(deployerKey)
+
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	id, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(id, ""))
+
+	ether := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		deployer.From: {Balance: ether},
+		id.Address:    {Balance: ether},
+	})
+
+	tokenAddress, _, token, err := mysttoken.DeployMystToken(deployer, backend)
+	assert.NoError(t, err)
+	backend.Commit()
+
+	registryAddress, _, _, err := abigen.DeployIdentityPromises(deployer, backend, tokenAddress, big.NewInt(registrationFee))
+	assert.NoError(t, err)
+	backend.Commit()
+
+	_, err = token.Mint(deployer, id.Address, big.NewInt(registrationFee))
+	assert.NoError(t, err)
+	backend.Commit()
+
+	_, err = token.Approve(helpers.CreateNewKeystoreTransactor(ks, &id), registryAddress, big.NewInt(registrationFee))
+	assert.NoError(t, err)
+	backend.Commit()
+
+	return &registrationEnvironment{
+		backend:         backend,
+		ks:              ks,
+		registryAddress: registryAddress,
+		identity:        id,
+	}
+}
+
+func (env *registrationEnvironment) newTransactor(t *testing.T, payer common.Address) *registrationTransactor {
+	transactorFactory := func(payer common.Address) *bind.TransactOpts {
+		return helpers.CreateNewKeystoreTransactor(env.ks, &accounts.Account{Address: payer})
+	}
+
+	transactor, err := NewRegistrationTransactor(env.backend, env.registryAddress, NewRegistrationDataProvider(env.ks), transactorFactory, payer)
+	assert.NoError(t, err)
+	return transactor
+}
+
+func waitForRegistration(transactor *registrationTransactor, id identity.Identity) RegistrationProgress {
+	var progress RegistrationProgress
+	for i := 0; i < 50; i++ {
+		progress, _ = transactor.Progress(id)
+		if progress.Status != RegistrationSubmitted {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return progress
+}
+
+func TestRegistrationTransactorRegistersIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registration")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	env := newRegistrationEnvironment(t, dir)
+	transactor := env.newTransactor(t, common.Address{})
+	id := identity.FromAddress(env.identity.Address.Hex())
+
+	progress, err := transactor.RegisterIdentity(id)
+	assert.NoError(t, err)
+	assert.Equal(t, RegistrationSubmitted, progress.Status)
+	assert.Equal(t, env.identity.Address, progress.Payer)
+	assert.NotEqual(t, common.Hash{}, progress.TxHash)
+
+	env.backend.Commit()
+
+	progress = waitForRegistration(transactor, id)
+	assert.Equal(t, RegistrationConfirmed, progress.Status)
+	assert.NoError(t, progress.Error)
+
+	contract, err := abigen.NewIdentityPromisesCaller(env.registryAddress, env.backend)
+	assert.NoError(t, err)
+	registered, err := contract.IsRegistered(&bind.CallOpts{}, env.identity.Address)
+	assert.NoError(t, err)
+	assert.True(t, registered)
+}
+
+func TestRegistrationTransactorRejectsRepeatedRegistration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registration")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	env := newRegistrationEnvironment(t, dir)
+	transactor := env.newTransactor(t, common.Address{})
+	id := identity.FromAddress(env.identity.Address.Hex())
+
+	submitted, err := transactor.RegisterIdentity(id)
+	assert.NoError(t, err)
+
+	progress, err := transactor.RegisterIdentity(id)
+	assert.Equal(t, ErrRegistrationInProgress, err)
+	assert.Equal(t, submitted, progress)
+}
+
+func TestRegistrationTransactorReportsFailureOfUnfundedPayer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registration")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	env := newRegistrationEnvironment(t, dir)
+	payer, err := env.ks.NewAccount("")
+	assert.NoError(t, err)
+	assert.NoError(t, env.ks.Unlock(payer, ""))
+
+	transactor := env.newTransactor(t, payer.Address)
+	id := identity.FromAddress(env.identity.Address.Hex())
+
+	_, err = transactor.RegisterIdentity(id)
+	assert.Error(t, err)
+
+	progress, ok := transactor.Progress(id)
+	assert.True(t, ok)
+	assert.Equal(t, RegistrationFailed, progress.Status)
+	assert.Equal(t, payer.Address, progress.Payer)
+	assert.Equal(t, err, progress.Error)
+}
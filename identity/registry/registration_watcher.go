@@ -0,0 +1,266 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/mysteriumnetwork/payments/contracts/abigen"
+)
+
+// registrationSource reads identity registrations from payments contract
+type registrationSource interface {
+	IsRegistered(identity common.Address) (bool, error)
+	LatestBlock() (uint64, error)
+	RegisteredBetween(identity common.Address, from, to uint64) (bool, error)
+	WatchRegistered(identity common.Address, sink chan<- struct{}) (event.Subscription, error)
+}
+
+// watchRegistration reports registration of identity, checking it on every registration event notification and periodically.
+// Notifications need backend supporting subscriptions (i.e. websocket), otherwise registration is only polled
+func (registry *contractRegistry) watchRegistration(address common.Address, events chan<- RegistrationEvent, stop <-chan struct{}) {
+	notifications := make(chan struct{}, 1)
+	var subscription event.Subscription
+	var subscriptionErr <-chan error
+	defer func() {
+		if subscription != nil {
+			subscription.Unsubscribe()
+		}
+	}()
+
+	delay := registry.pollInterval
+	for {
+		if subscription == nil {
+			var err error
+			if subscription, err = registry.source.WatchRegistered(address, notifications); err != nil {
+				log.Trace(logPrefix, "registration events are not streamed, polling: ", err)
+				subscription = nil
+			} else {
+				subscriptionErr = subscription.Err()
+			}
+		}
+
+		registered, err := registry.checkRegistration(address)
+		switch {
+		case err != nil:
+			delay *= 2
+			if delay > registry.maxPollInterval {
+				delay = registry.maxPollInterval
+			}
+			log.Warn(logPrefix, "failed to check registration of ", address.Hex(), ", retrying after ", delay, ": ", err)
+		case registered:
+			events <- Registered
+			return
+		default:
+			delay = registry.pollInterval
+		}
+
+		select {
+		case <-stop:
+			events <- Cancelled
+			return
+		case <-notifications:
+			log.Info(logPrefix, "registration event received for ", address.Hex())
+			events <- Registered
+			return
+		case err := <-subscriptionErr:
+			log.Warn(logPrefix, "registration event subscription failed, polling: ", err)
+			subscription.Unsubscribe()
+			subscription, subscriptionErr = nil, nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// checkRegistration looks for registration events since the last checked block and checks contract state,
+// which also covers registrations done before the identity was watched
+func (registry *contractRegistry) checkRegistration(address common.Address) (bool, error) {
+	head, err := registry.source.LatestBlock()
+	if err != nil {
+		return false, err
+	}
+
+	from, known := registry.cursors.Get(address)
+	if known && from <= head {
+		registered, err := registry.source.RegisteredBetween(address, from, head)
+		if err != nil || registered {
+			return registered, err
+		}
+	}
+
+	registered, err := registry.source.IsRegistered(address)
+	if err != nil || registered {
+		return registered, err
+	}
+
+	if err := registry.cursors.Set(address, head+1); err != nil {
+		log.Warn(logPrefix, "failed to store last checked block: ", err)
+	}
+	return false, nil
+}
+
+type contractSource struct {
+	backend         RegistryBackend
+	contractSession *abigen.IdentityPromisesCallerSession
+	filterer        *abigen.IdentityPromisesFilterer
+}
+
+func (source *contractSource) IsRegistered(identity common.Address) (bool, error) {
+	return source.contractSession.IsRegistered(identity)
+}
+
+func (source *contractSource) LatestBlock() (uint64, error) {
+	header, err := source.backend.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+func (source *contractSource) RegisteredBetween(identity common.Address, from, to uint64) (bool, error) {
+	filterOpts := &bind.FilterOpts{
+		Start:   from,
+		End:     &to,
+		Context: context.Background(),
+	}
+
+	logIterator, err := source.filterer.FilterRegistered(filterOpts, []common.Address{identity})
+	if err != nil {
+		return false, err
+	}
+	defer logIterator.Close()
+
+	if logIterator.Next() {
+		return true, nil
+	}
+	return false, logIterator.Error()
+}
+
+func (source *contractSource) WatchRegistered(identity common.Address, sink chan<- struct{}) (event.Subscription, error) {
+	registrations := make(chan *abigen.IdentityPromisesRegistered)
+	subscription, err := source.filterer.WatchRegistered(&bind.WatchOpts{Context: context.Background()}, registrations, []common.Address{identity})
+	if err != nil {
+		return nil, err
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer subscription.Unsubscribe()
+		for {
+			select {
+			case <-registrations:
+				select {
+				case sink <- struct{}{}:
+				default:
+				}
+			case err := <-subscription.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// Storer allows to save and load last checked blocks of registration events
+type Storer interface {
+	Store(bucket string, object interface{}) error
+	GetAllFrom(bucket string, array interface{}) error
+}
+
+const cursorBucket = "identity-registration-blocks"
+
+// registrationCursor holds the first block not yet checked for registration events of identity
+type registrationCursor struct {
+	Identity  string `storm:"id"`
+	NextBlock uint64
+}
+
+// StoredCursors returns the bucket and the record of registration cursors kept in storage
+func StoredCursors() (bucket string, record interface{}) {
+	return cursorBucket, registrationCursor{}
+}
+
+// cursorStore keeps registration cursors in memory and in optional persistent storage
+type cursorStore struct {
+	storage Storer
+
+	lock    sync.Mutex
+	cursors map[string]uint64
+}
+
+func newCursorStore(storage Storer) *cursorStore {
+	return &cursorStore{storage: storage}
+}
+
+// Get returns the first block not yet checked for registration events of identity
+func (store *cursorStore) Get(address common.Address) (uint64, bool) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.load()
+	block, ok := store.cursors[cursorKey(address)]
+	return block, ok
+}
+
+// Set remembers the first block not yet checked for registration events of identity
+func (store *cursorStore) Set(address common.Address, block uint64) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.load()
+	key := cursorKey(address)
+	if store.cursors[key] == block {
+		return nil
+	}
+	store.cursors[key] = block
+
+	if store.storage == nil {
+		return nil
+	}
+	return store.storage.Store(cursorBucket, &registrationCursor{Identity: key, NextBlock: block})
+}
+
+func (store *cursorStore) load() {
+	if store.cursors != nil {
+		return
+	}
+	store.cursors = make(map[string]uint64)
+	if store.storage == nil {
+		return
+	}
+
+	var cursors []registrationCursor
+	if err := store.storage.GetAllFrom(cursorBucket, &cursors); err != nil {
+		log.Warn(logPrefix, "failed to load last checked blocks, registrations will be checked from the latest block: ", err)
+		return
+	}
+	for _, cursor := range cursors {
+		store.cursors[cursor.Identity] = cursor.NextBlock
+	}
+}
+
+func cursorKey(address common.Address) string {
+	return strings.ToLower(address.Hex())
+}
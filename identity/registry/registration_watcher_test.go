@@ -0,0 +1,296 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+var watchedIdentity = identity.FromAddress("0x000000000000000000000000000000000000000a")
+
+func newWatchedRegistry(source *fakeRegistrationSource, storage Storer) *contractRegistry {
+	return &contractRegistry{
+		source:          source,
+		cursors:         newCursorStore(storage),
+		pollInterval:    time.Millisecond,
+		maxPollInterval: 5 * time.Millisecond,
+	}
+}
+
+func waitForEvent(t *testing.T, events chan RegistrationEvent) RegistrationEvent {
+	select {
+	case registrationEvent := <-events:
+		return registrationEvent
+	case <-time.After(2 * time.Second):
+		t.Fatal("registration event was not received")
+		return Cancelled
+	}
+}
+
+func TestSubscribeToRegistrationEventDetectsRegisteredIdentity(t *testing.T) {
+	source := &fakeRegistrationSource{head: 10}
+	registry := newWatchedRegistry(source, nil)
+
+	events, _ := registry.SubscribeToRegistrationEvent(watchedIdentity)
+	source.setRegistered(true)
+
+	assert.Equal(t, Registered, waitForEvent(t, events))
+}
+
+func TestSubscribeToRegistrationEventFindsEventsSinceLastCheckedBlock(t *testing.T) {
+	storage := &fakeCursorStorage{}
+	storage.Store(cursorBucket, &registrationCursor{Identity: watchedIdentity.Address, NextBlock: 5})
+	source := &fakeRegistrationSource{head: 10, eventBlock: 7}
+	registry := newWatchedRegistry(source, storage)
+
+	events, _ := registry.SubscribeToRegistrationEvent(watchedIdentity)
+
+	assert.Equal(t, Registered, waitForEvent(t, events))
+	assert.Equal(t, [2]uint64{5, 10}, source.lastFilteredRange())
+}
+
+func TestSubscribeToRegistrationEventStoresLastCheckedBlock(t *testing.T) {
+	storage := &fakeCursorStorage{}
+	source := &fakeRegistrationSource{head: 10}
+	registry := newWatchedRegistry(source, storage)
+
+	events, unsubscribe := registry.SubscribeToRegistrationEvent(watchedIdentity)
+	for storage.nextBlock(watchedIdentity.Address) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	source.setHead(12)
+	for storage.nextBlock(watchedIdentity.Address) != 13 {
+		time.Sleep(time.Millisecond)
+	}
+	unsubscribe()
+
+	assert.Equal(t, Cancelled, waitForEvent(t, events))
+	assert.Equal(t, [2]uint64{11, 12}, source.lastFilteredRange())
+}
+
+func TestSubscribeToRegistrationEventRetriesFailedChecks(t *testing.T) {
+	source := &fakeRegistrationSource{head: 10, err: errors.New("connection refused")}
+	registry := newWatchedRegistry(source, nil)
+
+	events, _ := registry.SubscribeToRegistrationEvent(watchedIdentity)
+	for source.checkCount() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	source.setError(nil)
+	source.setRegistered(true)
+
+	assert.Equal(t, Registered, waitForEvent(t, events))
+}
+
+func TestSubscribeToRegistrationEventUsesEventSubscription(t *testing.T) {
+	source := &fakeRegistrationSource{head: 10, subscribable: true}
+	registry := newWatchedRegistry(source, nil)
+	registry.pollInterval = time.Hour
+
+	events, _ := registry.SubscribeToRegistrationEvent(watchedIdentity)
+	source.notify()
+
+	assert.Equal(t, Registered, waitForEvent(t, events))
+}
+
+func TestSubscribeToRegistrationEventFallsBackToPollingWhenSubscriptionFails(t *testing.T) {
+	source := &fakeRegistrationSource{head: 10, subscribable: true}
+	registry := newWatchedRegistry(source, nil)
+
+	events, _ := registry.SubscribeToRegistrationEvent(watchedIdentity)
+	source.failSubscription(errors.New("websocket closed"))
+	source.setRegistered(true)
+
+	assert.Equal(t, Registered, waitForEvent(t, events))
+}
+
+func TestSubscribeToRegistrationEventCancelled(t *testing.T) {
+	source := &fakeRegistrationSource{head: 10}
+	registry := newWatchedRegistry(source, nil)
+
+	events, unsubscribe := registry.SubscribeToRegistrationEvent(watchedIdentity)
+	unsubscribe()
+	unsubscribe()
+
+	assert.Equal(t, Cancelled, waitForEvent(t, events))
+}
+
+type fakeRegistrationSource struct {
+	lock          sync.Mutex
+	head          uint64
+	registered    bool
+	eventBlock    uint64
+	err           error
+	checks        int
+	filteredRange [2]uint64
+	subscribable  bool
+	sink          chan<- struct{}
+	subscription  event.Subscription
+	subscriptionE chan error
+}
+
+func (source *fakeRegistrationSource) IsRegistered(address common.Address) (bool, error) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	return source.registered, source.err
+}
+
+func (source *fakeRegistrationSource) LatestBlock() (uint64, error) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	source.checks++
+	return source.head, source.err
+}
+
+func (source *fakeRegistrationSource) RegisteredBetween(address common.Address, from, to uint64) (bool, error) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	source.filteredRange = [2]uint64{from, to}
+	return source.eventBlock != 0 && from <= source.eventBlock && source.eventBlock <= to, source.err
+}
+
+func (source *fakeRegistrationSource) WatchRegistered(address common.Address, sink chan<- struct{}) (event.Subscription, error) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	if !source.subscribable {
+		return nil, errors.New("notifications not supported")
+	}
+	source.sink = sink
+	source.subscriptionE = make(chan error, 1)
+	errs := source.subscriptionE
+	source.subscription = event.NewSubscription(func(quit <-chan struct{}) error {
+		select {
+		case err := <-errs:
+			return err
+		case <-quit:
+			return nil
+		}
+	})
+	return source.subscription, nil
+}
+
+func (source *fakeRegistrationSource) waitSubscription() {
+	for {
+		source.lock.Lock()
+		subscribed := source.sink != nil
+		source.lock.Unlock()
+		if subscribed {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (source *fakeRegistrationSource) notify() {
+	source.waitSubscription()
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	source.sink <- struct{}{}
+}
+
+func (source *fakeRegistrationSource) failSubscription(err error) {
+	source.waitSubscription()
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	source.subscribable = false
+	source.subscriptionE <- err
+}
+
+func (source *fakeRegistrationSource) setRegistered(registered bool) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	source.registered = registered
+}
+
+func (source *fakeRegistrationSource) setHead(head uint64) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	source.head = head
+}
+
+func (source *fakeRegistrationSource) setError(err error) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	source.err = err
+}
+
+func (source *fakeRegistrationSource) checkCount() int {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	return source.checks
+}
+
+func (source *fakeRegistrationSource) lastFilteredRange() [2]uint64 {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	return source.filteredRange
+}
+
+type fakeCursorStorage struct {
+	lock    sync.Mutex
+	cursors map[string]registrationCursor
+}
+
+func (storage *fakeCursorStorage) Store(bucket string, object interface{}) error {
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+
+	if storage.cursors == nil {
+		storage.cursors = make(map[string]registrationCursor)
+	}
+	cursor := object.(*registrationCursor)
+	storage.cursors[cursor.Identity] = *cursor
+	return nil
+}
+
+func (storage *fakeCursorStorage) GetAllFrom(bucket string, array interface{}) error {
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+
+	cursors := array.(*[]registrationCursor)
+	for _, cursor := range storage.cursors {
+		*cursors = append(*cursors, cursor)
+	}
+	return nil
+}
+
+func (storage *fakeCursorStorage) nextBlock(address string) uint64 {
+	storage.lock.Lock()
+	defer storage.lock.Unlock()
+
+	return storage.cursors[address].NextBlock
+}
@@ -19,19 +19,35 @@ package registry
 
 import (
 	"context"
+	"math/big"
+	"sync"
 	"time"
 
-	log "github.com/cihub/seelog"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/contracts/abigen"
 )
 
 const logPrefix = "[registry] "
 
-// NewIdentityRegistryContract creates identity registry service which uses blockchain for information
-func NewIdentityRegistryContract(contractBackend bind.ContractBackend, registryAddress common.Address) (*contractRegistry, error) {
+const (
+	// registrationPollInterval defines how often registration of identity is checked
+	registrationPollInterval = 15 * time.Second
+	// registrationMaxPollInterval limits how long checking is delayed after blockchain RPC failures
+	registrationMaxPollInterval = 5 * time.Minute
+)
+
+// RegistryBackend is blockchain backend used to check identity registration
+type RegistryBackend interface {
+	bind.ContractBackend
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// NewIdentityRegistryContract creates identity registry service which uses blockchain for information.
+// Storage keeps the last checked block of registration events between restarts, it is optional
+func NewIdentityRegistryContract(contractBackend RegistryBackend, registryAddress common.Address, storage Storer) (*contractRegistry, error) {
 	contract, err := abigen.NewIdentityPromisesCaller(registryAddress, contractBackend)
 	if err != nil {
 		return nil, err
@@ -50,20 +66,26 @@ func NewIdentityRegistryContract(contractBackend bind.ContractBackend, registryA
 	}
 
 	return &contractRegistry{
-		contractSession,
-		filterer,
+		source: &contractSource{
+			backend:         contractBackend,
+			contractSession: contractSession,
+			filterer:        filterer,
+		},
+		cursors:         newCursorStore(storage),
+		pollInterval:    registrationPollInterval,
+		maxPollInterval: registrationMaxPollInterval,
 	}, nil
 }
 
 type contractRegistry struct {
-	contractSession *abigen.IdentityPromisesCallerSession
-	filterer        *abigen.IdentityPromisesFilterer
+	source          registrationSource
+	cursors         *cursorStore
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
 }
 
 func (registry *contractRegistry) IsRegistered(id identity.Identity) (bool, error) {
-	return registry.contractSession.IsRegistered(
-		common.HexToAddress(id.Address),
-	)
+	return registry.source.IsRegistered(common.HexToAddress(id.Address))
 }
 
 // RegistrationEvent describes registration events
@@ -75,61 +97,21 @@ const (
 	Cancelled  RegistrationEvent = 1
 )
 
-// SubscribeToRegistrationEvent returns registration event if given providerAddress was registered within payments contract
+// SubscribeToRegistrationEvent returns registration event if given providerAddress was registered within payments contract.
+// Registration is checked until it happens or subscription is cancelled, blockchain failures are retried
 func (registry *contractRegistry) SubscribeToRegistrationEvent(id identity.Identity) (
 	registrationEvent chan RegistrationEvent,
 	unsubscribe func(),
 ) {
-	registrationEvent = make(chan RegistrationEvent)
+	registrationEvent = make(chan RegistrationEvent, 1)
 
-	stopLoop := make(chan bool)
+	stop := make(chan struct{})
+	var stopOnce sync.Once
 	unsubscribe = func() {
 		// cancel (stop) identity registration loop
-		stopLoop <- true
-	}
-
-	identities := []common.Address{
-		common.HexToAddress(id.Address),
-	}
-
-	filterOps := &bind.FilterOpts{
-		Start:   0,
-		End:     nil,
-		Context: context.Background(),
+		stopOnce.Do(func() { close(stop) })
 	}
 
-	go func() {
-		for {
-			select {
-			case <-stopLoop:
-				registrationEvent <- Cancelled
-			case <-time.After(1 * time.Second):
-				logIterator, err := registry.filterer.FilterRegistered(filterOps, identities)
-				if err != nil {
-					registrationEvent <- Cancelled
-					log.Error(logPrefix, err)
-					return
-				}
-				if logIterator == nil {
-					registrationEvent <- Cancelled
-					return
-				}
-				for {
-					next := logIterator.Next()
-					if next {
-						registrationEvent <- Registered
-					} else {
-						err = logIterator.Error()
-						if err != nil {
-							log.Error(logPrefix, err)
-						}
-						break
-					}
-				}
-			case <-time.After(30 * time.Second):
-				log.Trace(logPrefix, "no identity registration, sleeping for 1s")
-			}
-		}
-	}()
+	go registry.watchRegistration(common.HexToAddress(id.Address), registrationEvent, stop)
 	return
 }
@@ -18,10 +18,16 @@
 package identity
 
 import (
+	"errors"
+
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// ErrIdentityLocked is returned when signing with identity which is not unlocked
+var ErrIdentityLocked = errors.New("identity is locked")
+
 // SignerFactory callback returning Signer
 type SignerFactory func(id Identity) Signer
 
@@ -48,6 +54,9 @@ func NewSigner(keystore Keystore, identity Identity) Signer {
 // Sign signs given message and returns signature
 func (ksSigner *keystoreSigner) Sign(message []byte) (Signature, error) {
 	signature, err := ksSigner.keystore.SignHash(ksSigner.account, messageHash(message))
+	if err == keystore.ErrLocked {
+		return Signature{}, ErrIdentityLocked
+	}
 	if err != nil {
 		return Signature{}, err
 	}
@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+const unlockPolicyLogPrefix = "[identity-unlock] "
+
+// unlockCheckInterval defines how often unlocked identities are checked for expiry
+const unlockCheckInterval = 10 * time.Second
+
+// UnlockPolicy defines when unlocked identities are locked again
+type UnlockPolicy struct {
+	// IdleTimeout locks identity which was not in use for this long, zero disables idle lock
+	IdleTimeout time.Duration
+	// InUse tells if identity is needed unlocked, i.e. by running service or active connection with payments.
+	// Such identities are not locked until they are not needed anymore
+	InUse func(Identity) bool
+}
+
+type unlockState struct {
+	// deadline is zero when identity was unlocked without timeout
+	deadline   time.Time
+	lastActive time.Time
+}
+
+// activeSigner signs with identity and marks it active, so that signing postpones idle lock
+type activeSigner struct {
+	Signer
+	markActive func()
+}
+
+func (signer *activeSigner) Sign(message []byte) (Signature, error) {
+	signature, err := signer.Signer.Sign(message)
+	if err == nil {
+		signer.markActive()
+	}
+	return signature, err
+}
+
+// NewSigner returns signer of given identity, signing with it postpones idle lock of identity
+func (idm *identityManager) NewSigner(id Identity) Signer {
+	return &activeSigner{
+		Signer: NewSigner(idm.keystoreManager, id),
+		markActive: func() {
+			idm.markActive(id)
+		},
+	}
+}
+
+// markActive refreshes last activity time of unlocked identity
+func (idm *identityManager) markActive(id Identity) {
+	idm.unlockLock.Lock()
+	defer idm.unlockLock.Unlock()
+
+	if state, ok := idm.unlocked[accountToIdentity(identityToAccount(id)).Address]; ok {
+		state.lastActive = idm.timeNow()
+	}
+}
+
+// Start starts locking unlocked identities according to the unlock policy
+func (idm *identityManager) Start() {
+	idm.unlockLock.Lock()
+	defer idm.unlockLock.Unlock()
+
+	if idm.stop != nil {
+		return
+	}
+	idm.stop = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(unlockCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				idm.lockExpired()
+			}
+		}
+	}(idm.stop)
+}
+
+// Stop stops locking identities
+func (idm *identityManager) Stop() {
+	idm.unlockLock.Lock()
+	defer idm.unlockLock.Unlock()
+
+	if idm.stop != nil {
+		close(idm.stop)
+		idm.stop = nil
+	}
+}
+
+// lockExpired locks identities which timed out or were idle for too long and are not in use
+func (idm *identityManager) lockExpired() {
+	idm.unlockLock.Lock()
+	defer idm.unlockLock.Unlock()
+
+	now := idm.timeNow()
+	for address, state := range idm.unlocked {
+		id := FromAddress(address)
+		if idm.policy.InUse != nil && idm.policy.InUse(id) {
+			state.lastActive = now
+			continue
+		}
+
+		timedOut := !state.deadline.IsZero() && !now.Before(state.deadline)
+		idle := idm.policy.IdleTimeout > 0 && now.Sub(state.lastActive) >= idm.policy.IdleTimeout
+		if !timedOut && !idle {
+			continue
+		}
+
+		if err := idm.keystoreManager.Lock(identityToAccount(id).Address); err != nil {
+			log.Warn(unlockPolicyLogPrefix, "failed to lock identity ", address, ": ", err)
+			continue
+		}
+		delete(idm.unlocked, address)
+		log.Info(unlockPolicyLogPrefix, "identity locked: ", address)
+	}
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const unlockTestAddress = "0x000000000000000000000000000000000000000A"
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) Advance(duration time.Duration) {
+	clock.now = clock.now.Add(duration)
+}
+
+func newPolicyManager(policy UnlockPolicy) (*identityManager, *keyStoreFake, *fakeClock) {
+	ks := &keyStoreFake{
+		AccountsMock: []accounts.Account{
+			addressToAccount(unlockTestAddress),
+		},
+	}
+	clock := &fakeClock{now: time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)}
+
+	manager := NewIdentityManagerWithPolicy(ks, policy)
+	manager.timeNow = clock.Now
+	return manager, ks, clock
+}
+
+func TestUnlockPolicy_LocksIdentityAfterTimeout(t *testing.T) {
+	manager, ks, clock := newPolicyManager(UnlockPolicy{})
+
+	assert.NoError(t, manager.UnlockWithTimeout(unlockTestAddress, "", time.Minute))
+
+	clock.Advance(59 * time.Second)
+	manager.lockExpired()
+	assert.Empty(t, ks.LockedMock)
+
+	clock.Advance(time.Second)
+	manager.lockExpired()
+	assert.Equal(t, []common.Address{common.HexToAddress(unlockTestAddress)}, ks.LockedMock)
+	assert.Empty(t, manager.unlocked)
+}
+
+func TestUnlockPolicy_LocksIdleIdentity(t *testing.T) {
+	manager, ks, clock := newPolicyManager(UnlockPolicy{IdleTimeout: time.Hour})
+
+	assert.NoError(t, manager.Unlock(unlockTestAddress, ""))
+
+	clock.Advance(30 * time.Minute)
+	manager.lockExpired()
+	assert.Empty(t, ks.LockedMock)
+
+	clock.Advance(30 * time.Minute)
+	manager.lockExpired()
+	assert.Len(t, ks.LockedMock, 1)
+}
+
+func TestUnlockPolicy_SigningPostponesIdleLock(t *testing.T) {
+	manager, ks, clock := newPolicyManager(UnlockPolicy{IdleTimeout: time.Hour})
+	signer := manager.NewSigner(FromAddress(unlockTestAddress))
+
+	assert.NoError(t, manager.Unlock(unlockTestAddress, ""))
+
+	clock.Advance(50 * time.Minute)
+	_, err := signer.Sign([]byte("message"))
+	assert.NoError(t, err)
+
+	clock.Advance(50 * time.Minute)
+	manager.lockExpired()
+	assert.Empty(t, ks.LockedMock)
+
+	clock.Advance(10 * time.Minute)
+	manager.lockExpired()
+	assert.Len(t, ks.LockedMock, 1)
+}
+
+func TestUnlockPolicy_KeepsUnlockedWithoutIdleTimeout(t *testing.T) {
+	manager, ks, clock := newPolicyManager(UnlockPolicy{})
+
+	assert.NoError(t, manager.Unlock(unlockTestAddress, ""))
+
+	clock.Advance(24 * time.Hour)
+	manager.lockExpired()
+	assert.Empty(t, ks.LockedMock)
+}
+
+func TestUnlockPolicy_KeepsIdentityInUseUnlocked(t *testing.T) {
+	inUse := true
+	manager, ks, clock := newPolicyManager(UnlockPolicy{
+		IdleTimeout: time.Hour,
+		InUse: func(Identity) bool {
+			return inUse
+		},
+	})
+
+	assert.NoError(t, manager.UnlockWithTimeout(unlockTestAddress, "", time.Minute))
+
+	clock.Advance(2 * time.Hour)
+	manager.lockExpired()
+	assert.Empty(t, ks.LockedMock)
+
+	inUse = false
+	clock.Advance(time.Minute)
+	manager.lockExpired()
+	assert.Len(t, ks.LockedMock, 1)
+}
+
+func TestUnlockPolicy_IdlePeriodStartsAfterIdentityIsReleased(t *testing.T) {
+	inUse := true
+	manager, ks, clock := newPolicyManager(UnlockPolicy{
+		IdleTimeout: time.Hour,
+		InUse: func(Identity) bool {
+			return inUse
+		},
+	})
+
+	assert.NoError(t, manager.Unlock(unlockTestAddress, ""))
+
+	clock.Advance(2 * time.Hour)
+	manager.lockExpired()
+
+	inUse = false
+	clock.Advance(30 * time.Minute)
+	manager.lockExpired()
+	assert.Empty(t, ks.LockedMock)
+
+	clock.Advance(30 * time.Minute)
+	manager.lockExpired()
+	assert.Len(t, ks.LockedMock, 1)
+}
+
+func TestManager_Lock(t *testing.T) {
+	manager, ks, _ := newPolicyManager(UnlockPolicy{})
+
+	assert.NoError(t, manager.Unlock(unlockTestAddress, ""))
+	assert.NoError(t, manager.Lock(unlockTestAddress))
+	assert.Len(t, ks.LockedMock, 1)
+	assert.Empty(t, manager.unlocked)
+}
+
+func TestManager_LockIdentityInUse(t *testing.T) {
+	manager, ks, _ := newPolicyManager(UnlockPolicy{
+		InUse: func(Identity) bool {
+			return true
+		},
+	})
+
+	assert.NoError(t, manager.Unlock(unlockTestAddress, ""))
+	assert.Equal(t, ErrIdentityInUse, manager.Lock(unlockTestAddress))
+	assert.Empty(t, ks.LockedMock)
+	assert.Len(t, manager.unlocked, 1)
+}
+
+func TestManager_DeleteIdentityInUse(t *testing.T) {
+	manager, ks, _ := newPolicyManager(UnlockPolicy{
+		InUse: func(Identity) bool {
+			return true
+		},
+	})
+
+	assert.Equal(t, ErrIdentityInUse, manager.DeleteIdentity(unlockTestAddress, ""))
+	assert.True(t, manager.HasIdentity(unlockTestAddress))
+	assert.Empty(t, ks.LockedMock)
+}
+
+func TestManager_LockUnknownIdentity(t *testing.T) {
+	manager, _, _ := newPolicyManager(UnlockPolicy{})
+
+	assert.Error(t, manager.Lock("0x000000000000000000000000000000000000000B"))
+}
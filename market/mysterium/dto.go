@@ -33,6 +33,7 @@ type NodeRegisterRequest struct {
 type NodeStatsRequest struct {
 	NodeKey     string         `json:"node_key"`
 	ServiceType string         `json:"service_type"`
+	ProposalID  int            `json:"proposal_id"`
 	Sessions    []SessionStats `json:"sessions"`
 }
 
@@ -41,6 +42,8 @@ type ProposalUnregisterRequest struct {
 	// Unique identifier of a provider
 	ProviderID  string `json:"provider_id"`
 	ServiceType string `json:"service_type"`
+	// Identifier of the proposal variant, provider may serve several of the same service type
+	ProposalID int `json:"proposal_id"`
 }
 
 // ProposalsRequest represents JSON request for the proposals
@@ -26,6 +26,7 @@ import (
 	log "github.com/cihub/seelog"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/market/proposals/registry"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/node/session"
 )
@@ -101,6 +102,7 @@ func (mApi *MysteriumAPI) UnregisterProposal(proposal market.ServiceProposal, si
 	req, err := requests.NewSignedPostRequest(mApi.discoveryAPIAddress, "unregister_proposal", ProposalUnregisterRequest{
 		ProviderID:  proposal.ProviderID,
 		ServiceType: proposal.ServiceType,
+		ProposalID:  proposal.ID,
 	}, signer)
 	if err != nil {
 		return err
@@ -115,17 +117,30 @@ func (mApi *MysteriumAPI) UnregisterProposal(proposal market.ServiceProposal, si
 	return err
 }
 
-// PingProposal pings service proposal as being alive
+// PingProposal pings service proposal as being alive.
+// Returns registry.ErrProposalUnknown when discovery does not know the proposal anymore.
 func (mApi *MysteriumAPI) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
 	req, err := requests.NewSignedPostRequest(mApi.discoveryAPIAddress, "ping_proposal", NodeStatsRequest{
 		NodeKey:     proposal.ProviderID,
 		ServiceType: proposal.ServiceType,
+		ProposalID:  proposal.ID,
 	}, signer)
 	if err != nil {
 		return err
 	}
 
-	err = mApi.doRequest(req)
+	resp, err := mApi.http.Do(req)
+	if err != nil {
+		log.Error(mysteriumAPILogPrefix, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return registry.ErrProposalUnknown
+	}
+
+	err = ParseResponseError(resp)
 	if err == nil {
 		log.Info(mysteriumAPILogPrefix, "Proposal pinged for node: ", proposal.ProviderID, " service type: ", proposal.ServiceType)
 	}
@@ -207,6 +222,8 @@ func supportedProposalsOnly(proposals []market.ServiceProposal) (supported []mar
 	for _, proposal := range proposals {
 		if proposal.IsSupported() {
 			supported = append(supported, proposal)
+		} else if !proposal.IsSigned() {
+			log.Warn(mysteriumAPILogPrefix, "Proposal signature does not match provider, skipping: ", proposal.ProviderID)
 		}
 	}
 	return
@@ -18,11 +18,15 @@
 package mysterium
 
 import (
+	"io/ioutil"
 	"net"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/market/proposals/registry"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -54,6 +58,46 @@ func TestHttpTransportDoesntBlockForeverIfServerFailsToSendAnyResponse(t *testin
 	}
 }
 
+func TestPingProposalReturnsUnknownProposalError(t *testing.T) {
+	address, err := createHTTPServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	})
+	assert.NoError(t, err)
+
+	api := NewClient("http://" + address + "/")
+	err = api.PingProposal(market.ServiceProposal{ProviderID: "0x1", ServiceType: "noop"}, &identity.SignerFake{})
+	assert.Equal(t, registry.ErrProposalUnknown, err)
+}
+
+func TestPingProposalReturnsServerError(t *testing.T) {
+	address, err := createHTTPServer(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+	})
+	assert.NoError(t, err)
+
+	api := NewClient("http://" + address + "/")
+	err = api.PingProposal(market.ServiceProposal{ProviderID: "0x1", ServiceType: "noop"}, &identity.SignerFake{})
+	assert.Error(t, err)
+	assert.NotEqual(t, registry.ErrProposalUnknown, err)
+}
+
+func TestUnregisterAndPingProposalIdentifyProposalVariant(t *testing.T) {
+	bodies := make(chan string, 2)
+	address, err := createHTTPServer(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		bodies <- string(body)
+	})
+	assert.NoError(t, err)
+
+	api := NewClient("http://" + address + "/")
+	proposal := market.ServiceProposal{ID: 7, ProviderID: "0x1", ServiceType: "noop"}
+	assert.NoError(t, api.PingProposal(proposal, &identity.SignerFake{}))
+	assert.NoError(t, api.UnregisterProposal(proposal, &identity.SignerFake{}))
+
+	assert.Contains(t, <-bodies, `"proposal_id":7`)
+	assert.Contains(t, <-bodies, `"proposal_id":7`)
+}
+
 func createHTTPServer(handlerFunc http.HandlerFunc) (address string, err error) {
 	listener, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
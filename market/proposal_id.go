@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+	"sync/atomic"
+)
+
+// ProposalIDGenerator generates per provider unique proposal IDs
+type ProposalIDGenerator func() int
+
+// NewProposalIDGenerator returns generator of sequential proposal IDs.
+// Sequence starts at a random number, so that IDs are unlikely to repeat after provider restart.
+func NewProposalIDGenerator() ProposalIDGenerator {
+	lastID := randomProposalIDSeed()
+	return func() int {
+		return int(atomic.AddInt64(&lastID, 1))
+	}
+}
+
+// randomProposalIDSeed keeps upper half of positive int32 range for IDs generated during single run
+func randomProposalIDSeed() int64 {
+	seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt32/2))
+	if err != nil {
+		return 0
+	}
+	return seed.Int64()
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProposalIDGenerator_GeneratesUniqueIDs(t *testing.T) {
+	generateID := NewProposalIDGenerator()
+
+	first := generateID()
+	assert.True(t, first > 0)
+	assert.True(t, first <= math.MaxInt32/2)
+	assert.Equal(t, first+1, generateID())
+	assert.Equal(t, first+2, generateID())
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package broker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const logPrefix = "[proposals-broker] "
+
+// maxProposals limits count of proposals kept by finder, so that announcement flood can not exhaust memory
+const maxProposals = 10000
+
+var (
+	errAnnouncementStale    = errors.New("announcement is issued outside of expiry time")
+	errAnnouncementOutdated = errors.New("announcement is older than the last one of the proposal")
+	errTooManyProposals     = errors.New("too many proposals are announced")
+)
+
+// Finder builds list of proposals by listening to provider announcements on message broker.
+// Proposals which are not announced again within expiry time are dropped.
+// Announcements issued outside of expiry time or before the last seen one of the same proposal are ignored.
+type Finder struct {
+	receiver        communication.Receiver
+	expiry          time.Duration
+	verifierFactory func(identity.Identity) identity.Verifier
+	timeNow         func() time.Time
+
+	lock      sync.RWMutex
+	proposals map[string]announcedProposal
+}
+
+type announcedProposal struct {
+	proposal    market.ServiceProposal
+	announcedAt time.Time
+	issuedAt    time.Time
+	// unregistered proposal is kept until it expires, so that replayed registration of it is ignored
+	unregistered bool
+}
+
+// NewFinder creates proposal finder listening thru given receiver
+func NewFinder(receiver communication.Receiver, expiry time.Duration) *Finder {
+	return &Finder{
+		receiver: receiver,
+		expiry:   expiry,
+		verifierFactory: func(id identity.Identity) identity.Verifier {
+			return identity.NewVerifierIdentity(id)
+		},
+		timeNow:   time.Now,
+		proposals: make(map[string]announcedProposal),
+	}
+}
+
+// Start starts listening to proposal announcements
+func (finder *Finder) Start() error {
+	err := finder.receiver.Receive(&messageConsumer{endpoint: endpointRegister, handler: finder.handleRegister})
+	if err != nil {
+		return err
+	}
+	return finder.receiver.Receive(&messageConsumer{endpoint: endpointUnregister, handler: finder.handleUnregister})
+}
+
+// Stop stops listening to proposal announcements
+func (finder *Finder) Stop() {
+	finder.receiver.Unsubscribe()
+}
+
+// FindProposals returns announced and not yet expired proposals matching given provider and service type
+func (finder *Finder) FindProposals(providerID string, serviceType string) ([]market.ServiceProposal, error) {
+	finder.lock.Lock()
+	defer finder.lock.Unlock()
+
+	finder.removeExpired(finder.timeNow())
+	keys := make([]string, 0, len(finder.proposals))
+	for key, announced := range finder.proposals {
+		if !announced.unregistered {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	proposals := make([]market.ServiceProposal, 0)
+	for _, key := range keys {
+		proposal := finder.proposals[key].proposal
+		if providerID != "" && proposal.ProviderID != providerID {
+			continue
+		}
+		if serviceType != "" && proposal.ServiceType != serviceType {
+			continue
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+func (finder *Finder) handleRegister(message *proposalMessage) error {
+	return finder.handleAnnouncement(message, false)
+}
+
+func (finder *Finder) handleUnregister(message *proposalMessage) error {
+	return finder.handleAnnouncement(message, true)
+}
+
+func (finder *Finder) handleAnnouncement(message *proposalMessage, unregistered bool) error {
+	announcement, err := message.unpack(finder.verifierFactory)
+	if err != nil {
+		return err
+	}
+	proposal := announcement.Proposal
+	if !unregistered && !proposal.IsSupported() {
+		log.Debug(logPrefix, "Ignoring unsupported proposal of provider: ", proposal.ProviderID)
+		return nil
+	}
+
+	finder.lock.Lock()
+	defer finder.lock.Unlock()
+
+	now := finder.timeNow()
+	key := proposalKey(proposal)
+	if err := finder.checkAnnouncement(key, announcement.IssuedAt, now); err != nil {
+		return err
+	}
+
+	finder.proposals[key] = announcedProposal{
+		proposal:     proposal,
+		announcedAt:  now,
+		issuedAt:     announcement.IssuedAt,
+		unregistered: unregistered,
+	}
+	return nil
+}
+
+// checkAnnouncement rejects replayed and outdated announcements, as well as new proposals once finder is full
+func (finder *Finder) checkAnnouncement(key string, issuedAt time.Time, now time.Time) error {
+	if issuedAt.Before(now.Add(-finder.expiry)) || issuedAt.After(now.Add(finder.expiry)) {
+		return errAnnouncementStale
+	}
+
+	if previous, exists := finder.proposals[key]; exists {
+		if !issuedAt.After(previous.issuedAt) {
+			return errAnnouncementOutdated
+		}
+		return nil
+	}
+
+	if len(finder.proposals) >= maxProposals {
+		finder.removeExpired(now)
+	}
+	if len(finder.proposals) >= maxProposals {
+		return errTooManyProposals
+	}
+	return nil
+}
+
+func (finder *Finder) removeExpired(now time.Time) {
+	for key, announced := range finder.proposals {
+		if now.Sub(announced.announcedAt) > finder.expiry {
+			delete(finder.proposals, key)
+		}
+	}
+}
+
+// proposalKey identifies proposal variant, provider may announce several proposals of the same service
+func proposalKey(proposal market.ServiceProposal) string {
+	return fmt.Sprintf("%s-%s-%d", proposal.ProviderID, proposal.ServiceType, proposal.ID)
+}
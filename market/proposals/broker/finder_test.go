@@ -0,0 +1,215 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	providerID = identity.FromAddress("0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+	proposal   = market.ServiceProposal{
+		ID:                1,
+		Format:            "service-proposal/v2",
+		ServiceType:       "mock_service",
+		ServiceDefinition: mockServiceDefinition{},
+		PaymentMethodType: "mock_payment",
+		PaymentMethod:     mockPaymentMethod{},
+		ProviderID:        providerID.Address,
+		ProviderContacts:  market.ContactList{market.Contact{Type: "mock_contact", Definition: mockContact{}}},
+	}
+	signer = &identity.SignerFake{}
+)
+
+func init() {
+	market.RegisterServiceDefinitionUnserializer("mock_service", func(*json.RawMessage) (market.ServiceDefinition, error) {
+		return mockServiceDefinition{}, nil
+	})
+	market.RegisterPaymentMethodUnserializer("mock_payment", func(*json.RawMessage) (market.PaymentMethod, error) {
+		return mockPaymentMethod{}, nil
+	})
+	market.RegisterContactUnserializer("mock_contact", func(*json.RawMessage) (market.ContactDefinition, error) {
+		return mockContact{}, nil
+	})
+
+	// proposals are accepted only when signed by provider identity
+	keystore := identity.NewKeystoreFilesystem("../../../identity/test_data", true)
+	if err := identity.NewIdentityManager(keystore).Unlock(providerID.Address, ""); err != nil {
+		panic(err)
+	}
+	if err := proposal.Sign(identity.NewSigner(keystore, providerID)); err != nil {
+		panic(err)
+	}
+}
+
+func TestFinder_ListensToAnnouncements(t *testing.T) {
+	receiver := &receiverFake{consumers: make(map[communication.MessageEndpoint]communication.MessageConsumer)}
+	finder := newTestFinder(receiver)
+	assert.NoError(t, finder.Start())
+
+	issuedAt := time.Now()
+	assert.NoError(t, receiver.deliver(endpointRegister, signedMessage(t, proposal, issuedAt)))
+	proposals, err := finder.FindProposals("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposal}, proposals)
+
+	proposals, err = finder.FindProposals("0x1", "")
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 0)
+
+	assert.NoError(t, receiver.deliver(endpointUnregister, signedMessage(t, proposal, issuedAt.Add(time.Second))))
+	proposals, err = finder.FindProposals("", "")
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 0)
+
+	finder.Stop()
+	assert.True(t, receiver.unsubscribed)
+}
+
+func TestFinder_ExpiresProposalsNotAnnouncedAgain(t *testing.T) {
+	now := time.Unix(1000, 0)
+	finder := newTestFinder(nil)
+	finder.timeNow = func() time.Time { return now }
+
+	assert.NoError(t, finder.handleRegister(signedMessage(t, proposal, now)))
+	now = now.Add(2 * time.Minute)
+	proposals, _ := finder.FindProposals("", "")
+	assert.Len(t, proposals, 1)
+
+	now = now.Add(2 * time.Minute)
+	proposals, _ = finder.FindProposals("", "")
+	assert.Len(t, proposals, 0)
+}
+
+func TestFinder_RejectsProposalNotSignedByProvider(t *testing.T) {
+	finder := newTestFinder(nil)
+	message := signedMessage(t, proposal, time.Now())
+	forged := identity.SignatureBytes([]byte("forged"))
+	message.Signature = forged.Base64()
+
+	assert.EqualError(t, finder.handleRegister(message), "proposal is not signed by provider 0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+	proposals, _ := finder.FindProposals("", "")
+	assert.Len(t, proposals, 0)
+}
+
+func TestFinder_IgnoresUnsupportedProposals(t *testing.T) {
+	finder := newTestFinder(nil)
+	unsupported := proposal
+	unsupported.ServiceType = "unknown_service"
+
+	assert.NoError(t, finder.handleRegister(signedMessage(t, unsupported, time.Now())))
+	proposals, _ := finder.FindProposals("", "")
+	assert.Len(t, proposals, 0)
+}
+
+func TestFinder_IgnoresReplayedAnnouncements(t *testing.T) {
+	now := time.Unix(1000, 0)
+	finder := newTestFinder(nil)
+	finder.timeNow = func() time.Time { return now }
+
+	register := signedMessage(t, proposal, now)
+	assert.NoError(t, finder.handleRegister(register))
+	assert.NoError(t, finder.handleUnregister(signedMessage(t, proposal, now.Add(time.Second))))
+
+	assert.Equal(t, errAnnouncementOutdated, finder.handleRegister(register))
+	proposals, _ := finder.FindProposals("", "")
+	assert.Len(t, proposals, 0)
+
+	now = now.Add(5 * time.Minute)
+	assert.Equal(t, errAnnouncementStale, finder.handleRegister(register))
+	proposals, _ = finder.FindProposals("", "")
+	assert.Len(t, proposals, 0)
+}
+
+func TestFinder_RejectsNewProposalsWhenFull(t *testing.T) {
+	now := time.Unix(1000, 0)
+	finder := newTestFinder(nil)
+	finder.timeNow = func() time.Time { return now }
+	for i := 0; i < maxProposals; i++ {
+		finder.proposals[fmt.Sprintf("key-%d", i)] = announcedProposal{announcedAt: now}
+	}
+
+	assert.Equal(t, errTooManyProposals, finder.handleRegister(signedMessage(t, proposal, now)))
+
+	now = now.Add(5 * time.Minute)
+	assert.NoError(t, finder.handleRegister(signedMessage(t, proposal, now)))
+	proposals, _ := finder.FindProposals("", "")
+	assert.Equal(t, []market.ServiceProposal{proposal}, proposals)
+}
+
+func newTestFinder(receiver communication.Receiver) *Finder {
+	finder := NewFinder(receiver, 3*time.Minute)
+	finder.verifierFactory = fakeVerifierFactory
+	return finder
+}
+
+func fakeVerifierFactory(identity.Identity) identity.Verifier {
+	return &identity.VerifierFake{}
+}
+
+func signedMessage(t *testing.T, proposal market.ServiceProposal, issuedAt time.Time) *proposalMessage {
+	message, err := newProposalMessage(proposal, issuedAt, signer)
+	assert.NoError(t, err)
+	return message
+}
+
+type mockServiceDefinition struct{}
+
+func (mockServiceDefinition) GetLocation() market.Location {
+	return market.Location{}
+}
+
+type mockContact struct{}
+
+type mockPaymentMethod struct{}
+
+func (mockPaymentMethod) GetPrice() money.Money {
+	return money.Money{}
+}
+
+type receiverFake struct {
+	consumers    map[communication.MessageEndpoint]communication.MessageConsumer
+	unsubscribed bool
+}
+
+func (receiver *receiverFake) Receive(consumer communication.MessageConsumer) error {
+	receiver.consumers[consumer.GetMessageEndpoint()] = consumer
+	return nil
+}
+
+func (receiver *receiverFake) Respond(consumer communication.RequestConsumer) error {
+	return errors.New("not implemented")
+}
+
+func (receiver *receiverFake) Unsubscribe() {
+	receiver.unsubscribed = true
+}
+
+func (receiver *receiverFake) deliver(endpoint communication.MessageEndpoint, message *proposalMessage) error {
+	return receiver.consumers[endpoint].Consume(message)
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package broker
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// Topic is a well-known broker subject prefix where providers announce their proposals
+const Topic = "proposals"
+
+const (
+	endpointRegister   = communication.MessageEndpoint("register")
+	endpointUnregister = communication.MessageEndpoint("unregister")
+)
+
+// proposalMessage is a proposal announcement signed by its provider
+type proposalMessage struct {
+	Announcement json.RawMessage `json:"announcement"`
+	Signature    string          `json:"signature"`
+}
+
+// proposalAnnouncement is the signed content of the message.
+// Time of issue lets consumers ignore replayed and outdated announcements.
+type proposalAnnouncement struct {
+	Proposal market.ServiceProposal `json:"proposal"`
+	IssuedAt time.Time              `json:"issuedAt"`
+}
+
+func newProposalMessage(proposal market.ServiceProposal, issuedAt time.Time, signer identity.Signer) (*proposalMessage, error) {
+	announcementJSON, err := json.Marshal(proposalAnnouncement{Proposal: proposal, IssuedAt: issuedAt})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.Sign(announcementJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proposalMessage{
+		Announcement: announcementJSON,
+		Signature:    signature.Base64(),
+	}, nil
+}
+
+// unpack decodes announcement and checks that it was signed by the provider of announced proposal
+func (message *proposalMessage) unpack(verifierFactory func(identity.Identity) identity.Verifier) (proposalAnnouncement, error) {
+	var announcement proposalAnnouncement
+	if err := json.Unmarshal(message.Announcement, &announcement); err != nil {
+		return announcement, err
+	}
+
+	providerID := announcement.Proposal.ProviderID
+	verifier := verifierFactory(identity.FromAddress(providerID))
+	if !verifier.Verify(message.Announcement, identity.SignatureBase64(message.Signature)) {
+		return announcement, errors.New("proposal is not signed by provider " + providerID)
+	}
+	return announcement, nil
+}
+
+type messageProducer struct {
+	endpoint communication.MessageEndpoint
+	message  *proposalMessage
+}
+
+func (producer *messageProducer) GetMessageEndpoint() communication.MessageEndpoint {
+	return producer.endpoint
+}
+
+func (producer *messageProducer) Produce() interface{} {
+	return producer.message
+}
+
+type messageConsumer struct {
+	endpoint communication.MessageEndpoint
+	handler  func(*proposalMessage) error
+}
+
+func (consumer *messageConsumer) GetMessageEndpoint() communication.MessageEndpoint {
+	return consumer.endpoint
+}
+
+func (consumer *messageConsumer) NewMessage() interface{} {
+	return &proposalMessage{}
+}
+
+func (consumer *messageConsumer) Consume(messagePtr interface{}) error {
+	return consumer.handler(messagePtr.(*proposalMessage))
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package broker
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/market/proposals/registry"
+)
+
+// Registry announces provider proposals on message broker instead of discovery API
+type Registry struct {
+	sender  communication.Sender
+	timeNow func() time.Time
+}
+
+// NewRegistry creates proposal registry which publishes announcements thru given sender
+func NewRegistry(sender communication.Sender) *Registry {
+	return &Registry{
+		sender:  sender,
+		timeNow: time.Now,
+	}
+}
+
+// RegisterProposal announces proposal to consumers
+func (r *Registry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return r.publish(endpointRegister, proposal, signer)
+}
+
+// PingProposal re-announces proposal, so that consumers do not expire it
+func (r *Registry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return r.publish(endpointRegister, proposal, signer)
+}
+
+// UnregisterProposal announces that proposal is not served anymore
+func (r *Registry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return r.publish(endpointUnregister, proposal, signer)
+}
+
+func (r *Registry) publish(endpoint communication.MessageEndpoint, proposal market.ServiceProposal, signer identity.Signer) error {
+	message, err := newProposalMessage(proposal, r.timeNow(), signer)
+	if err != nil {
+		return err
+	}
+	return r.sender.Send(&messageProducer{endpoint: endpoint, message: message})
+}
+
+var _ registry.ProposalRegistry = &Registry{}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package broker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_AnnouncesSignedProposal(t *testing.T) {
+	sender := &senderFake{}
+	registry := NewRegistry(sender)
+
+	assert.NoError(t, registry.RegisterProposal(proposal, signer))
+	assert.Equal(t, endpointRegister, sender.lastProducer.GetMessageEndpoint())
+	assert.NoError(t, registry.PingProposal(proposal, signer))
+	assert.Equal(t, endpointRegister, sender.lastProducer.GetMessageEndpoint())
+	assert.NoError(t, registry.UnregisterProposal(proposal, signer))
+	assert.Equal(t, endpointUnregister, sender.lastProducer.GetMessageEndpoint())
+
+	message := sender.lastProducer.Produce().(*proposalMessage)
+	unpacked, err := message.unpack(fakeVerifierFactory)
+	assert.NoError(t, err)
+	assert.Equal(t, proposal, unpacked.Proposal)
+	assert.False(t, unpacked.IssuedAt.IsZero())
+}
+
+func TestRegistry_FailsWhenSigningFails(t *testing.T) {
+	registry := NewRegistry(&senderFake{})
+	err := registry.RegisterProposal(proposal, &identity.SignerFake{ErrorMock: errors.New("locked")})
+	assert.EqualError(t, err, "locked")
+}
+
+type senderFake struct {
+	lastProducer communication.MessageProducer
+}
+
+func (sender *senderFake) Send(producer communication.MessageProducer) error {
+	sender.lastProducer = producer
+	return nil
+}
+
+func (sender *senderFake) Request(producer communication.RequestProducer) (interface{}, error) {
+	return nil, errors.New("not implemented")
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// backoff calculates exponentially growing retry delays with random jitter
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	random  func() float64
+
+	lock    sync.Mutex
+	attempt uint
+}
+
+func newBackoff(initial, max time.Duration) *backoff {
+	return &backoff{
+		initial: initial,
+		max:     max,
+		random:  rand.Float64,
+	}
+}
+
+// Next returns delay before next retry. Delay doubles with each attempt until max is reached
+// and is randomized within [delay/2, delay), so that many providers do not retry at the same moment.
+func (b *backoff) Next() time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delay := b.initial << b.attempt
+	if delay >= b.max || delay <= 0 {
+		delay = b.max
+	} else {
+		b.attempt++
+	}
+
+	half := delay / 2
+	return half + time.Duration(b.random()*float64(half))
+}
+
+// Reset starts delays from the beginning
+func (b *backoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.attempt = 0
+}
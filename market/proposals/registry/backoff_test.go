@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_GrowsExponentiallyUpToMax(t *testing.T) {
+	b := newBackoff(time.Second, 5*time.Second)
+	b.random = func() float64 { return 0.999999 }
+
+	assert.InDelta(t, float64(time.Second), float64(b.Next()), float64(time.Millisecond))
+	assert.InDelta(t, float64(2*time.Second), float64(b.Next()), float64(time.Millisecond))
+	assert.InDelta(t, float64(4*time.Second), float64(b.Next()), float64(time.Millisecond))
+	assert.InDelta(t, float64(5*time.Second), float64(b.Next()), float64(time.Millisecond))
+	assert.InDelta(t, float64(5*time.Second), float64(b.Next()), float64(time.Millisecond))
+}
+
+func TestBackoff_AddsJitter(t *testing.T) {
+	b := newBackoff(4*time.Second, time.Minute)
+	b.random = func() float64 { return 0 }
+	assert.Equal(t, 2*time.Second, b.Next())
+
+	b.random = func() float64 { return 0.5 }
+	assert.Equal(t, 6*time.Second, b.Next())
+}
+
+func TestBackoff_Reset(t *testing.T) {
+	b := newBackoff(time.Second, time.Minute)
+	b.random = func() float64 { return 0 }
+	b.Next()
+	b.Next()
+
+	b.Reset()
+	assert.Equal(t, 500*time.Millisecond, b.Next())
+}
@@ -18,13 +18,18 @@
 package registry
 
 import (
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
 	identity_registry "github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
 )
 
+// ErrProposalUnknown is returned by PingProposal when registry does not know pinged proposal
+var ErrProposalUnknown = errors.New("proposal is not registered")
+
 // ProposalRegistry defines methods for proposal lifecycle - registration, keeping up to date, removal
 type ProposalRegistry interface {
 	RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error
@@ -37,32 +42,47 @@ type Discovery struct {
 	identityRegistry            identity_registry.IdentityRegistry
 	ownIdentity                 identity.Identity
 	identityRegistration        identity_registry.RegistrationDataProvider
+	identityRegistrator         identity_registry.IdentityRegistrator
 	proposalRegistry            ProposalRegistry
 	signerCreate                identity.SignerFactory
 	signer                      identity.Signer
 	proposal                    market.ServiceProposal
+	proposalLock                sync.Mutex
 	statusChan                  chan Status
+	pingInterval                time.Duration
+	retryBackoff                *backoff
+	nextPing                    time.Duration
+	lastPingAt                  time.Time
+	lastError                   error
+	lastErrorAt                 time.Time
 	status                      Status
 	proposalAnnouncementStopped *sync.WaitGroup
 	unsubscribe                 func()
 	stop                        func()
+	stopped                     chan struct{}
 
 	sync.RWMutex
 }
 
-// NewService creates new discovery service
+// NewService creates new discovery service.
+// When identityRegistrator is given, unregistered identity is registered by the node itself
 func NewService(
 	identityRegistry identity_registry.IdentityRegistry,
 	identityRegistration identity_registry.RegistrationDataProvider,
+	identityRegistrator identity_registry.IdentityRegistrator,
 	proposalRegistry ProposalRegistry,
 	signerCreate identity.SignerFactory,
 ) *Discovery {
 	return &Discovery{
 		identityRegistry:            identityRegistry,
 		identityRegistration:        identityRegistration,
+		identityRegistrator:         identityRegistrator,
 		proposalRegistry:            proposalRegistry,
 		signerCreate:                signerCreate,
 		statusChan:                  make(chan Status),
+		pingInterval:                time.Minute,
+		retryBackoff:                newBackoff(5*time.Second, 5*time.Minute),
+		nextPing:                    time.Minute,
 		status:                      StatusUndefined,
 		proposalAnnouncementStopped: &sync.WaitGroup{},
 		unsubscribe:                 func() {},
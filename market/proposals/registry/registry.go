@@ -18,6 +18,8 @@
 package registry
 
 import (
+	"errors"
+	"sync"
 	"time"
 
 	log "github.com/cihub/seelog"
@@ -44,20 +46,56 @@ const (
 
 const logPrefix = "[discovery] "
 
+var statusNames = map[Status]string{
+	IdentityUnregistered:     "IdentityUnregistered",
+	WaitingForRegistration:   "WaitingForRegistration",
+	IdentityRegisterFailed:   "IdentityRegisterFailed",
+	RegisterProposal:         "RegisterProposal",
+	PingProposal:             "PingProposal",
+	UnregisterProposal:       "UnregisterProposal",
+	UnregisterProposalFailed: "UnregisterProposalFailed",
+	ProposalUnregistered:     "ProposalUnregistered",
+	StatusUndefined:          "StatusUndefined",
+}
+
+// String returns human readable name of registration stage
+func (status Status) String() string {
+	if name, ok := statusNames[status]; ok {
+		return name
+	}
+	return statusNames[StatusUndefined]
+}
+
+// State describes current discovery progress of a single proposal
+type State struct {
+	Status      Status
+	ProposalID  int
+	ServiceType string
+	LastPingAt  time.Time
+	LastError   error
+	LastErrorAt time.Time
+}
+
 // Start launches discovery service
 func (d *Discovery) Start(ownIdentity identity.Identity, proposal market.ServiceProposal) {
-	d.RLock()
-	defer d.RUnlock()
+	stopLoop := make(chan bool)
+	stopped := make(chan struct{})
+	var stopOnce sync.Once
 
+	d.Lock()
 	d.ownIdentity = ownIdentity
 	d.signer = d.signerCreate(ownIdentity)
-	d.proposal = proposal
-
-	stopLoop := make(chan bool)
+	d.stopped = stopped
 	d.stop = func() {
-		// cancel (stop) discovery loop
+		// interrupt delayed retries and pings, then cancel (stop) discovery loop
+		stopOnce.Do(func() { close(stopped) })
 		stopLoop <- true
 	}
+	d.Unlock()
+
+	d.proposalLock.Lock()
+	d.proposal = proposal
+	d.proposalLock.Unlock()
 
 	d.proposalAnnouncementStopped.Add(1)
 
@@ -134,31 +172,168 @@ func (d *Discovery) registerIdentity() {
 	}()
 }
 
+// Proposal returns currently published proposal
+func (d *Discovery) Proposal() market.ServiceProposal {
+	d.proposalLock.Lock()
+	defer d.proposalLock.Unlock()
+
+	return d.proposal
+}
+
+// UpdateProposal replaces published proposal with modified one, keeping its ID.
+// Proposal is re-published immediately when it is already registered, otherwise it is published on registration.
+func (d *Discovery) UpdateProposal(proposal market.ServiceProposal) error {
+	signer := d.getSigner()
+	if signer == nil {
+		return errors.New("discovery is not started")
+	}
+
+	d.proposalLock.Lock()
+	defer d.proposalLock.Unlock()
+
+	proposal.ID = d.proposal.ID
+	if err := proposal.Sign(signer); err != nil {
+		return err
+	}
+
+	d.RLock()
+	registered := d.status == PingProposal
+	d.RUnlock()
+
+	if registered {
+		if err := d.proposalRegistry.RegisterProposal(proposal, signer); err != nil {
+			return err
+		}
+		log.Info(logPrefix, "Proposal updated: ", proposal.ID)
+	}
+
+	d.proposal = proposal
+	return nil
+}
+
+// State returns current discovery progress of published proposal
+func (d *Discovery) State() State {
+	proposal := d.Proposal()
+
+	d.RLock()
+	defer d.RUnlock()
+
+	return State{
+		Status:      d.status,
+		ProposalID:  proposal.ID,
+		ServiceType: proposal.ServiceType,
+		LastPingAt:  d.lastPingAt,
+		LastError:   d.lastError,
+		LastErrorAt: d.lastErrorAt,
+	}
+}
+
 func (d *Discovery) registerProposal() {
-	err := d.proposalRegistry.RegisterProposal(d.proposal, d.signer)
+	signer := d.getSigner()
+
+	d.proposalLock.Lock()
+	err := d.proposal.Sign(signer)
+	if err == nil {
+		err = d.proposalRegistry.RegisterProposal(d.proposal, signer)
+	}
+	d.proposalLock.Unlock()
+
 	if err != nil {
-		log.Errorf("%s Failed to register proposal, retrying after 1 min. %s", logPrefix, err.Error())
-		time.Sleep(1 * time.Minute)
-		d.changeStatus(RegisterProposal)
+		delay := d.retryBackoff.Next()
+		log.Errorf("%s Failed to register proposal, retrying after %s. %s", logPrefix, delay, err.Error())
+		d.recordError(err)
+		if d.sleep(delay) {
+			d.changeStatusFrom(RegisterProposal, RegisterProposal)
+		}
 		return
 	}
-	d.changeStatus(PingProposal)
+
+	d.retryBackoff.Reset()
+	d.Lock()
+	d.lastPingAt = time.Now()
+	d.nextPing = d.pingInterval
+	d.Unlock()
+	d.changeStatusFrom(RegisterProposal, PingProposal)
 }
 
 func (d *Discovery) pingProposal() {
-	time.Sleep(1 * time.Minute)
-	err := d.proposalRegistry.PingProposal(d.proposal, d.signer)
+	d.RLock()
+	delay := d.nextPing
+	d.RUnlock()
+	if !d.sleep(delay) {
+		return
+	}
+
+	d.RLock()
+	pinging := d.status == PingProposal
+	d.RUnlock()
+	if !pinging {
+		// proposal was unregistered while waiting for ping, it must not be announced again
+		return
+	}
+
+	err := d.proposalRegistry.PingProposal(d.Proposal(), d.getSigner())
+
+	d.Lock()
+	if d.status != PingProposal {
+		// discovery was stopped while waiting for ping
+		d.Unlock()
+		return
+	}
+	if err == ErrProposalUnknown {
+		log.Warn(logPrefix, "Proposal is unknown to registry, registering it again")
+		d.lastError = err
+		d.lastErrorAt = time.Now()
+		d.Unlock()
+		d.changeStatusFrom(PingProposal, RegisterProposal)
+		return
+	}
 	if err != nil {
 		log.Error(logPrefix, "Failed to ping proposal: ", err)
+		d.lastError = err
+		d.lastErrorAt = time.Now()
+		d.nextPing = d.retryBackoff.Next()
+		if d.nextPing > d.pingInterval {
+			d.nextPing = d.pingInterval
+		}
+	} else {
+		d.retryBackoff.Reset()
+		d.lastPingAt = time.Now()
+		d.nextPing = d.pingInterval
+	}
+	d.Unlock()
+	d.changeStatusFrom(PingProposal, PingProposal)
+}
+
+// sleep waits for the given delay, returns false when discovery is stopped meanwhile
+func (d *Discovery) sleep(delay time.Duration) bool {
+	d.RLock()
+	stopped := d.stopped
+	d.RUnlock()
+
+	select {
+	case <-stopped:
+		return false
+	case <-time.After(delay):
+		return true
 	}
-	d.changeStatus(PingProposal)
+}
+
+func (d *Discovery) recordError(err error) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.lastError = err
+	d.lastErrorAt = time.Now()
 }
 
 func (d *Discovery) unregisterProposal() {
-	err := d.proposalRegistry.UnregisterProposal(d.proposal, d.signer)
+	err := d.proposalRegistry.UnregisterProposal(d.Proposal(), d.getSigner())
 	if err != nil {
 		log.Error(logPrefix, "Failed to unregister proposal: ", err)
+		d.recordError(err)
 		d.changeStatus(UnregisterProposalFailed)
+		return
 	}
 	log.Info(logPrefix, "Proposal unregistered")
 	d.changeStatus(ProposalUnregistered)
@@ -174,12 +349,10 @@ func (d *Discovery) checkRegistration() {
 
 	if !registered {
 		// if not registered - wait indefinitely for identity registration event
-		registrationData, err := d.identityRegistration.ProvideRegistrationData(d.ownIdentity)
-		if err != nil {
+		if err := d.submitRegistration(); err != nil {
 			d.changeStatus(IdentityRegisterFailed)
 			return
 		}
-		identity_registry.PrintRegistrationData(registrationData)
 		log.Infof("%s identity %s not registered, delaying proposal registration until identity is registered", logPrefix, d.ownIdentity.Address)
 		d.changeStatus(IdentityUnregistered)
 		return
@@ -187,13 +360,54 @@ func (d *Discovery) checkRegistration() {
 	d.changeStatus(RegisterProposal)
 }
 
+// submitRegistration sends registration transaction when node is able to, otherwise prints data for manual registration
+func (d *Discovery) submitRegistration() error {
+	if d.identityRegistrator != nil {
+		_, err := d.identityRegistrator.RegisterIdentity(d.ownIdentity)
+		if err == nil || err == identity_registry.ErrRegistrationInProgress {
+			log.Info(logPrefix, "identity registration transaction submitted")
+			return nil
+		}
+		log.Warn(logPrefix, "failed to submit identity registration, it has to be registered manually: ", err)
+	}
+
+	registrationData, err := d.identityRegistration.ProvideRegistrationData(d.ownIdentity)
+	if err != nil {
+		return err
+	}
+	identity_registry.PrintRegistrationData(registrationData)
+	return nil
+}
+
 func (d *Discovery) changeStatus(status Status) {
 	d.Lock()
 	defer d.Unlock()
 
+	d.setStatus(status)
+}
+
+// changeStatusFrom moves discovery to the given stage only when it is still in the expected one,
+// so that delayed retries and pings do not resume discovery which was stopped meanwhile
+func (d *Discovery) changeStatusFrom(expected, status Status) {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.status == expected {
+		d.setStatus(status)
+	}
+}
+
+func (d *Discovery) setStatus(status Status) {
 	d.status = status
 
 	go func() {
 		d.statusChan <- status
 	}()
 }
+
+func (d *Discovery) getSigner() identity.Signer {
+	d.RLock()
+	defer d.RUnlock()
+
+	return d.signer
+}
@@ -18,6 +18,7 @@
 package registry
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -44,6 +45,9 @@ func discoveryWithMockedDependencies() *Discovery {
 		},
 		identityRegistration: &identity_registry.FakeRegistrationDataProvider{},
 		proposalRegistry:     &mockedProposalRegistry{},
+		pingInterval:         10 * time.Millisecond,
+		nextPing:             10 * time.Millisecond,
+		retryBackoff:         newBackoff(time.Millisecond, 10*time.Millisecond),
 	}
 }
 
@@ -55,6 +59,7 @@ func TestStartRegistersProposal(t *testing.T) {
 
 	actualStatus := observeStatus(d, PingProposal)
 	assert.Equal(t, PingProposal, actualStatus)
+	assert.NotEmpty(t, d.proposal.Signature)
 }
 
 func TestStartRegistersIdentitySuccessfully(t *testing.T) {
@@ -82,6 +87,30 @@ func TestStartRegisterIdentityCancelled(t *testing.T) {
 	assert.Equal(t, IdentityRegisterFailed, actualStatus)
 }
 
+func TestStartSubmitsIdentityRegistration(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identity_registry.FakeRegistry{RegistrationEventExists: true, Registered: false}
+	registrator := &mockedIdentityRegistrator{}
+	d.identityRegistrator = registrator
+
+	d.Start(providerID, proposal)
+
+	actualStatus := observeStatus(d, PingProposal)
+	assert.Equal(t, PingProposal, actualStatus)
+	assert.Equal(t, providerID, registrator.registeredIdentity())
+}
+
+func TestStartWaitsForRegistrationWhenSubmissionFails(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identity_registry.FakeRegistry{RegistrationEventExists: false, Registered: false}
+	d.identityRegistrator = &mockedIdentityRegistrator{err: errors.New("insufficient funds")}
+
+	d.Start(providerID, proposal)
+
+	actualStatus := observeStatus(d, WaitingForRegistration)
+	assert.Equal(t, WaitingForRegistration, actualStatus)
+}
+
 func TestStartStopUnregisterProposal(t *testing.T) {
 	d := discoveryWithMockedDependencies()
 	d.identityRegistry = &identity_registry.FakeRegistry{RegistrationEventExists: false, Registered: true}
@@ -97,6 +126,127 @@ func TestStartStopUnregisterProposal(t *testing.T) {
 	assert.Equal(t, ProposalUnregistered, actualStatus)
 }
 
+func TestUpdateProposalRepublishesRegisteredProposal(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identity_registry.FakeRegistry{RegistrationEventExists: false, Registered: true}
+
+	d.Start(providerID, market.ServiceProposal{ID: 7, ProviderID: providerID.Address})
+	observeStatus(d, PingProposal)
+
+	updated := market.ServiceProposal{ID: 100, ProviderID: providerID.Address, PaymentMethodType: "PER_TIME"}
+	assert.NoError(t, d.UpdateProposal(updated))
+
+	published := d.Proposal()
+	assert.Equal(t, 7, published.ID)
+	assert.Equal(t, "PER_TIME", published.PaymentMethodType)
+	assert.NotEmpty(t, published.Signature)
+	assert.Equal(t, published, d.proposalRegistry.(*mockedProposalRegistry).lastRegistered())
+}
+
+func TestUpdateProposalFailsWhenNotStarted(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	assert.EqualError(t, d.UpdateProposal(proposal), "discovery is not started")
+}
+
+func TestPingOfUnknownProposalRegistersItAgain(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identity_registry.FakeRegistry{RegistrationEventExists: false, Registered: true}
+	mockedRegistry := d.proposalRegistry.(*mockedProposalRegistry)
+	mockedRegistry.setPingError(ErrProposalUnknown)
+
+	d.Start(providerID, proposal)
+
+	for mockedRegistry.registrations() < 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	mockedRegistry.setPingError(nil)
+
+	state := observeLastPing(d)
+	assert.Equal(t, ErrProposalUnknown, state.LastError)
+	assert.False(t, state.LastErrorAt.IsZero())
+	assert.False(t, state.LastPingAt.IsZero())
+}
+
+func TestStateReportsPingFailure(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identity_registry.FakeRegistry{RegistrationEventExists: false, Registered: true}
+	mockedRegistry := d.proposalRegistry.(*mockedProposalRegistry)
+	mockedRegistry.setPingError(errors.New("registry unavailable"))
+
+	d.Start(providerID, market.ServiceProposal{ID: 3, ProviderID: providerID.Address, ServiceType: "noop"})
+
+	state := d.State()
+	for state.LastError == nil {
+		time.Sleep(10 * time.Millisecond)
+		state = d.State()
+	}
+	assert.Equal(t, 3, state.ProposalID)
+	assert.Equal(t, "noop", state.ServiceType)
+	assert.EqualError(t, state.LastError, "registry unavailable")
+	assert.Equal(t, 1, mockedRegistry.registrations())
+}
+
+func TestStopDuringRegistrationRetryDoesNotRegisterAgain(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identity_registry.FakeRegistry{RegistrationEventExists: false, Registered: true}
+	d.retryBackoff = newBackoff(50*time.Millisecond, 50*time.Millisecond)
+	mockedRegistry := d.proposalRegistry.(*mockedProposalRegistry)
+	mockedRegistry.registerError = errors.New("registry unavailable")
+
+	d.Start(providerID, proposal)
+	for mockedRegistry.registrations() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	d.Stop()
+	observeStatus(d, ProposalUnregistered)
+
+	// retry delay has passed
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, mockedRegistry.registrations())
+	d.RLock()
+	assert.Equal(t, ProposalUnregistered, d.status)
+	d.RUnlock()
+}
+
+func TestStopDuringPingDelayDoesNotPingAgain(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identity_registry.FakeRegistry{RegistrationEventExists: false, Registered: true}
+	d.pingInterval = time.Hour
+	mockedRegistry := d.proposalRegistry.(*mockedProposalRegistry)
+
+	d.Start(providerID, proposal)
+	observeStatus(d, PingProposal)
+	d.Stop()
+	observeStatus(d, ProposalUnregistered)
+
+	done := make(chan struct{})
+	go func() {
+		d.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "discovery is not stopped")
+	}
+	assert.Equal(t, 0, mockedRegistry.pings())
+}
+
+func TestStatusString(t *testing.T) {
+	assert.Equal(t, "PingProposal", PingProposal.String())
+	assert.Equal(t, "StatusUndefined", Status(100).String())
+}
+
+func observeLastPing(d *Discovery) State {
+	for {
+		state := d.State()
+		if state.LastPingAt.After(state.LastErrorAt) {
+			return state
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func observeStatus(d *Discovery, status Status) Status {
 	for {
 		d.RLock()
@@ -110,18 +260,91 @@ func observeStatus(d *Discovery, status Status) Status {
 }
 
 type mockedProposalRegistry struct {
+	lock          sync.Mutex
+	lastRegister  market.ServiceProposal
+	registerCount int
+	registerError error
+	pingCount     int
+	pingError     error
 }
 
-func (mockedProposalRegistry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+func (registry *mockedProposalRegistry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	registry.registerCount++
+	if registry.registerError != nil {
+		return registry.registerError
+	}
+	registry.lastRegister = proposal
 	return nil
 }
 
-func (mockedProposalRegistry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+func (registry *mockedProposalRegistry) registrations() int {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	return registry.registerCount
+}
+
+func (registry *mockedProposalRegistry) setPingError(err error) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	registry.pingError = err
+}
+
+func (registry *mockedProposalRegistry) lastRegistered() market.ServiceProposal {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	return registry.lastRegister
+}
+
+func (registry *mockedProposalRegistry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	registry.pingCount++
+	return registry.pingError
+}
+
+func (registry *mockedProposalRegistry) pings() int {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	return registry.pingCount
 }
 
-func (mockedProposalRegistry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+func (registry *mockedProposalRegistry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
 	return nil
 }
 
 var _ ProposalRegistry = &mockedProposalRegistry{}
+
+type mockedIdentityRegistrator struct {
+	lock       sync.Mutex
+	registered identity.Identity
+	err        error
+}
+
+func (registrator *mockedIdentityRegistrator) RegisterIdentity(id identity.Identity) (identity_registry.RegistrationProgress, error) {
+	registrator.lock.Lock()
+	defer registrator.lock.Unlock()
+
+	registrator.registered = id
+	return identity_registry.RegistrationProgress{Status: identity_registry.RegistrationSubmitted}, registrator.err
+}
+
+func (registrator *mockedIdentityRegistrator) Progress(id identity.Identity) (identity_registry.RegistrationProgress, bool) {
+	return identity_registry.RegistrationProgress{}, false
+}
+
+func (registrator *mockedIdentityRegistrator) registeredIdentity() identity.Identity {
+	registrator.lock.Lock()
+	defer registrator.lock.Unlock()
+
+	return registrator.registered
+}
+
+var _ identity_registry.IdentityRegistrator = &mockedIdentityRegistrator{}
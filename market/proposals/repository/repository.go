@@ -0,0 +1,311 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const logPrefix = "[proposal-repository] "
+const bucketName = "proposals-cache"
+
+// Finder fetches proposals from discovery service
+type Finder interface {
+	FindProposals(providerID string, serviceType string) ([]market.ServiceProposal, error)
+}
+
+// Storage persists cached proposals between node restarts
+type Storage interface {
+	GetAllFrom(bucket string, data interface{}) error
+	storage.Transactor
+}
+
+// Status describes freshness of cached proposals
+type Status struct {
+	// UpdatedAt is the time of last successful refresh from discovery
+	UpdatedAt time.Time
+	// Age is the time passed since last successful refresh
+	Age time.Duration
+	// Stale is true when last refresh has failed and older proposals are served
+	Stale bool
+	// LastError is the error of last failed refresh
+	LastError error
+}
+
+// proposalRecord is a cached proposal as it is kept in storage
+type proposalRecord struct {
+	Key       string `storm:"id"`
+	Proposal  market.ServiceProposal
+	UpdatedAt time.Time
+}
+
+// StoredProposals returns the bucket and the record of cached proposals kept in storage
+func StoredProposals() (bucket string, record interface{}) {
+	return bucketName, proposalRecord{}
+}
+
+// Repository serves proposals from memory and keeps them fresh by refreshing from discovery in the background
+type Repository struct {
+	finder          Finder
+	storage         Storage
+	refreshInterval time.Duration
+	timeNow         func() time.Time
+
+	lock      sync.RWMutex
+	proposals []market.ServiceProposal
+	updatedAt time.Time
+	lastError error
+	stop      chan struct{}
+}
+
+// NewRepository creates proposal repository on top of given finder.
+// Storage is optional, proposals are cached in memory only when it is nil.
+func NewRepository(finder Finder, storage Storage, refreshInterval time.Duration) *Repository {
+	return &Repository{
+		finder:          finder,
+		storage:         storage,
+		refreshInterval: refreshInterval,
+		timeNow:         time.Now,
+	}
+}
+
+// Start loads previously persisted proposals and starts refreshing them in the background
+func (repo *Repository) Start() {
+	repo.loadPersisted()
+
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if repo.stop != nil {
+		return
+	}
+	repo.stop = make(chan struct{})
+
+	go repo.refreshLoop(repo.stop)
+}
+
+// Stop stops background refresh
+func (repo *Repository) Stop() {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if repo.stop != nil {
+		close(repo.stop)
+		repo.stop = nil
+	}
+}
+
+// FindProposals returns cached proposals matching given provider and service type.
+// Discovery is asked directly when cache has no matching proposals, fetched ones are merged into the cache.
+func (repo *Repository) FindProposals(providerID string, serviceType string) ([]market.ServiceProposal, error) {
+	repo.lock.RLock()
+	cached := filterProposals(repo.proposals, providerID, serviceType)
+	fetched := !repo.updatedAt.IsZero()
+	repo.lock.RUnlock()
+
+	if len(cached) > 0 {
+		return cached, nil
+	}
+
+	proposals, err := repo.finder.FindProposals(providerID, serviceType)
+	if err != nil && fetched {
+		log.Warn(logPrefix, "Discovery is unreachable, serving cached proposals. ", err)
+		return cached, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	repo.merge(providerID, serviceType, proposals)
+	return proposals, nil
+}
+
+// Status returns freshness of cached proposals
+func (repo *Repository) Status() Status {
+	repo.lock.RLock()
+	defer repo.lock.RUnlock()
+
+	status := Status{
+		UpdatedAt: repo.updatedAt,
+		Stale:     repo.lastError != nil,
+		LastError: repo.lastError,
+	}
+	if !repo.updatedAt.IsZero() {
+		status.Age = repo.timeNow().Sub(repo.updatedAt)
+	}
+	return status
+}
+
+func (repo *Repository) refreshLoop(stop chan struct{}) {
+	for {
+		repo.refresh()
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(repo.refreshInterval):
+		}
+	}
+}
+
+func (repo *Repository) refresh() {
+	proposals, err := repo.finder.FindProposals("", "")
+	if err != nil {
+		log.Warn(logPrefix, "Failed to refresh proposals: ", err)
+
+		repo.lock.Lock()
+		repo.lastError = err
+		repo.lock.Unlock()
+		return
+	}
+
+	updatedAt := repo.timeNow()
+
+	repo.lock.Lock()
+	repo.proposals = proposals
+	repo.updatedAt = updatedAt
+	repo.lastError = nil
+	repo.lock.Unlock()
+
+	log.Debug(logPrefix, "Proposals refreshed: ", len(proposals))
+	repo.persist(proposals, updatedAt)
+}
+
+// merge replaces cached proposals matching given provider and service type with directly fetched ones
+func (repo *Repository) merge(providerID string, serviceType string, fetched []market.ServiceProposal) {
+	if len(fetched) == 0 {
+		return
+	}
+
+	repo.lock.Lock()
+	merged := make([]market.ServiceProposal, 0, len(repo.proposals)+len(fetched))
+	for _, proposal := range repo.proposals {
+		if !matchProposal(proposal, providerID, serviceType) {
+			merged = append(merged, proposal)
+		}
+	}
+	merged = append(merged, fetched...)
+	repo.proposals = merged
+	updatedAt := repo.updatedAt
+	repo.lock.Unlock()
+
+	repo.persist(merged, updatedAt)
+}
+
+func (repo *Repository) loadPersisted() {
+	if repo.storage == nil {
+		return
+	}
+
+	var records []proposalRecord
+	if err := repo.storage.GetAllFrom(bucketName, &records); err != nil {
+		log.Warn(logPrefix, "Failed to load cached proposals: ", err)
+		return
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	proposals := make([]market.ServiceProposal, 0, len(records))
+	updatedAt := records[0].UpdatedAt
+	for _, record := range records {
+		if !record.Proposal.IsSupported() {
+			continue
+		}
+		proposals = append(proposals, record.Proposal)
+		if record.UpdatedAt.Before(updatedAt) {
+			updatedAt = record.UpdatedAt
+		}
+	}
+
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if repo.updatedAt.IsZero() {
+		repo.proposals = proposals
+		repo.updatedAt = updatedAt
+	}
+}
+
+// persist replaces cached proposals with given ones in a single transaction:
+// proposals are upserted by their keys and only records of vanished proposals are deleted
+func (repo *Repository) persist(proposals []market.ServiceProposal, updatedAt time.Time) {
+	if repo.storage == nil {
+		return
+	}
+
+	err := repo.storage.Transaction(func(tx storage.Tx) error {
+		var records []proposalRecord
+		if err := tx.GetAllFrom(bucketName, &records); err != nil {
+			return err
+		}
+
+		keys := make(map[string]bool, len(proposals))
+		for _, proposal := range proposals {
+			record := &proposalRecord{
+				Key:       proposalKey(proposal),
+				Proposal:  proposal,
+				UpdatedAt: updatedAt,
+			}
+			if err := tx.Store(bucketName, record); err != nil {
+				return err
+			}
+			keys[record.Key] = true
+		}
+
+		for i := range records {
+			if keys[records[i].Key] {
+				continue
+			}
+			if err := tx.Delete(bucketName, &records[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn(logPrefix, "Failed to cache proposals: ", err)
+	}
+}
+
+func proposalKey(proposal market.ServiceProposal) string {
+	return fmt.Sprintf("%s-%s-%d", proposal.ProviderID, proposal.ServiceType, proposal.ID)
+}
+
+func filterProposals(proposals []market.ServiceProposal, providerID string, serviceType string) []market.ServiceProposal {
+	filtered := make([]market.ServiceProposal, 0)
+	for _, proposal := range proposals {
+		if matchProposal(proposal, providerID, serviceType) {
+			filtered = append(filtered, proposal)
+		}
+	}
+	return filtered
+}
+
+func matchProposal(proposal market.ServiceProposal, providerID string, serviceType string) bool {
+	if providerID != "" && proposal.ProviderID != providerID {
+		return false
+	}
+	return serviceType == "" || proposal.ServiceType == serviceType
+}
@@ -0,0 +1,289 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	proposalOpenvpn = market.ServiceProposal{ProviderID: "0x1", ServiceType: "openvpn"}
+	proposalNoop    = market.ServiceProposal{ProviderID: "0x2", ServiceType: "noop"}
+)
+
+func TestRepository_FindProposalsFiltersCache(t *testing.T) {
+	finder := &finderFake{proposals: []market.ServiceProposal{proposalOpenvpn, proposalNoop}}
+	repo := NewRepository(finder, nil, time.Minute)
+	repo.refresh()
+
+	proposals, err := repo.FindProposals("", "noop")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalNoop}, proposals)
+
+	proposals, err = repo.FindProposals("0x1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalOpenvpn}, proposals)
+	assert.Equal(t, 1, finder.calls)
+}
+
+func TestRepository_FindProposalsAsksDiscoveryWhenNotCached(t *testing.T) {
+	finder := &finderFake{proposals: []market.ServiceProposal{proposalOpenvpn}}
+	repo := NewRepository(finder, nil, time.Minute)
+
+	proposals, err := repo.FindProposals("0x1", "openvpn")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalOpenvpn}, proposals)
+	assert.Equal(t, 1, finder.calls)
+
+	finder.err = errors.New("discovery is down")
+	_, err = repo.FindProposals("0x1", "openvpn")
+	assert.EqualError(t, err, "discovery is down")
+}
+
+func TestRepository_ServesStaleProposalsWhenDiscoveryIsDown(t *testing.T) {
+	now := time.Unix(1000, 0)
+	finder := &finderFake{proposals: []market.ServiceProposal{proposalOpenvpn}}
+	repo := NewRepository(finder, nil, time.Minute)
+	repo.timeNow = func() time.Time { return now }
+	repo.refresh()
+
+	finder.err = errors.New("discovery is down")
+	now = now.Add(5 * time.Minute)
+	repo.refresh()
+
+	proposals, err := repo.FindProposals("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalOpenvpn}, proposals)
+
+	status := repo.Status()
+	assert.True(t, status.Stale)
+	assert.Equal(t, 5*time.Minute, status.Age)
+	assert.Equal(t, time.Unix(1000, 0), status.UpdatedAt)
+	assert.EqualError(t, status.LastError, "discovery is down")
+
+	proposals, err = repo.FindProposals("0x3", "")
+	assert.NoError(t, err)
+	assert.Len(t, proposals, 0)
+}
+
+func TestRepository_FindProposalsMergesFetchedIntoCache(t *testing.T) {
+	storage := &storageFake{}
+	finder := &finderFake{proposals: []market.ServiceProposal{proposalOpenvpn}}
+	repo := NewRepository(finder, storage, time.Minute)
+	repo.refresh()
+
+	finder.proposals = []market.ServiceProposal{proposalOpenvpn, proposalNoop}
+	proposals, err := repo.FindProposals("0x2", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalNoop}, proposals)
+	assert.Len(t, storage.records, 2)
+
+	proposals, err = repo.FindProposals("0x2", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{proposalNoop}, proposals)
+	assert.Equal(t, 2, finder.calls)
+}
+
+func TestRepository_PersistsRefreshedProposals(t *testing.T) {
+	storage := &storageFake{}
+	finder := &finderFake{proposals: []market.ServiceProposal{proposalOpenvpn, proposalNoop}}
+	repo := NewRepository(finder, storage, time.Minute)
+	repo.refresh()
+	assert.Len(t, storage.records, 2)
+
+	finder.proposals = []market.ServiceProposal{proposalNoop}
+	repo.refresh()
+	assert.Len(t, storage.records, 1)
+	assert.Equal(t, "0x2-noop-0", storage.records[0].Key)
+}
+
+func TestRepository_KeepsCachedProposalsWhenPersistFails(t *testing.T) {
+	storage := &storageFake{}
+	finder := &finderFake{proposals: []market.ServiceProposal{proposalOpenvpn, proposalNoop}}
+	repo := NewRepository(finder, storage, time.Minute)
+	repo.refresh()
+
+	storage.storeErr = errors.New("disk is full")
+	finder.proposals = []market.ServiceProposal{proposalNoop}
+	repo.refresh()
+	assert.Len(t, storage.records, 2)
+}
+
+func TestRepository_PersistedProposalsSurviveRestart(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+	db, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	first, second := signedProposal(t, 1), signedProposal(t, 2)
+	finder := &finderFake{proposals: []market.ServiceProposal{first, second}}
+	NewRepository(finder, db, time.Minute).refresh()
+
+	finder.proposals = []market.ServiceProposal{second}
+	NewRepository(finder, db, time.Minute).refresh()
+
+	restarted := NewRepository(&finderFake{err: errors.New("discovery is down")}, db, time.Minute)
+	restarted.loadPersisted()
+	proposals, err := restarted.FindProposals("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{second}, proposals)
+	assert.False(t, restarted.Status().UpdatedAt.IsZero())
+}
+
+func TestRepository_StartRefreshesInBackground(t *testing.T) {
+	finder := &finderFake{proposals: []market.ServiceProposal{proposalOpenvpn}}
+	repo := NewRepository(finder, &storageFake{}, time.Minute)
+	repo.Start()
+	defer repo.Stop()
+
+	for i := 0; i < 100 && repo.Status().UpdatedAt.IsZero(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.False(t, repo.Status().UpdatedAt.IsZero())
+}
+
+type finderFake struct {
+	proposals []market.ServiceProposal
+	err       error
+	calls     int
+}
+
+func (finder *finderFake) FindProposals(providerID string, serviceType string) ([]market.ServiceProposal, error) {
+	finder.calls++
+	if finder.err != nil {
+		return nil, finder.err
+	}
+	return filterProposals(finder.proposals, providerID, serviceType), nil
+}
+
+type storageFake struct {
+	records  []proposalRecord
+	storeErr error
+}
+
+func (storage *storageFake) Transaction(fn func(tx storage.Tx) error) error {
+	records := append([]proposalRecord{}, storage.records...)
+	if err := fn(storage); err != nil {
+		storage.records = records
+		return err
+	}
+	return nil
+}
+
+func (storage *storageFake) Store(bucket string, data interface{}) error {
+	if storage.storeErr != nil {
+		return storage.storeErr
+	}
+
+	record := data.(*proposalRecord)
+	for i := range storage.records {
+		if storage.records[i].Key == record.Key {
+			storage.records[i] = *record
+			return nil
+		}
+	}
+	storage.records = append(storage.records, *record)
+	return nil
+}
+
+func (storage *storageFake) Delete(bucket string, data interface{}) error {
+	record := data.(*proposalRecord)
+	for i := range storage.records {
+		if storage.records[i].Key == record.Key {
+			storage.records = append(storage.records[:i], storage.records[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (storage *storageFake) GetAllFrom(bucket string, data interface{}) error {
+	*data.(*[]proposalRecord) = append([]proposalRecord{}, storage.records...)
+	return nil
+}
+
+func (storage *storageFake) Update(bucket string, object interface{}) error {
+	return errors.New("not implemented")
+}
+
+func (storage *storageFake) Find(bucket string, query storage.Query, array interface{}) error {
+	return errors.New("not implemented")
+}
+
+func (storage *storageFake) Count(bucket string, query storage.Query, object interface{}) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+var providerID = identity.FromAddress("0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+
+func init() {
+	market.RegisterServiceDefinitionUnserializer("mock_service", func(*json.RawMessage) (market.ServiceDefinition, error) {
+		return mockServiceDefinition{}, nil
+	})
+	market.RegisterPaymentMethodUnserializer("mock_payment", func(*json.RawMessage) (market.PaymentMethod, error) {
+		return mockPaymentMethod{}, nil
+	})
+	market.RegisterContactUnserializer("mock_contact", func(*json.RawMessage) (market.ContactDefinition, error) {
+		return mockContact{}, nil
+	})
+}
+
+// signedProposal returns proposal which is supported after it is loaded from storage
+func signedProposal(t *testing.T, id int) market.ServiceProposal {
+	keystore := identity.NewKeystoreFilesystem("../../../identity/test_data", true)
+	assert.NoError(t, identity.NewIdentityManager(keystore).Unlock(providerID.Address, ""))
+
+	proposal := market.ServiceProposal{
+		ID:                id,
+		Format:            "service-proposal/v2",
+		ServiceType:       "mock_service",
+		ServiceDefinition: mockServiceDefinition{},
+		PaymentMethodType: "mock_payment",
+		PaymentMethod:     mockPaymentMethod{},
+		ProviderID:        providerID.Address,
+		ProviderContacts:  market.ContactList{market.Contact{Type: "mock_contact", Definition: mockContact{}}},
+	}
+	assert.NoError(t, proposal.Sign(identity.NewSigner(keystore, providerID)))
+	return proposal
+}
+
+type mockServiceDefinition struct{}
+
+func (mockServiceDefinition) GetLocation() market.Location {
+	return market.Location{}
+}
+
+type mockContact struct{}
+
+type mockPaymentMethod struct{}
+
+func (mockPaymentMethod) GetPrice() money.Money {
+	return money.Money{}
+}
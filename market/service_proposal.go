@@ -18,13 +18,14 @@
 package market
 
 import (
+	"bytes"
 	"encoding/json"
 
 	"github.com/mysteriumnetwork/node/identity"
 )
 
 const (
-	proposalFormat = "service-proposal/v1"
+	proposalFormat = "service-proposal/v2"
 )
 
 // ServiceProposal is top level structure which is presented to marketplace by service provider, and looked up by service consumer
@@ -53,6 +54,42 @@ type ServiceProposal struct {
 
 	// Communication methods possible
 	ProviderContacts ContactList `json:"provider_contacts"`
+
+	// Signature of canonical proposal form by provider identity, base64 encoded
+	Signature string `json:"signature,omitempty"`
+
+	// signed keeps the proposal as it was received, when it contains fields unknown to this node
+	signed *signedForm
+}
+
+// signedForm is the proposal as it was signed by provider, so that fields unknown to this node are verified too
+type signedForm struct {
+	// payload is canonical form of the received proposal without signature
+	payload []byte
+	// decoded is canonical form of the proposal as it was decoded, it differs from payload by unknown fields
+	decoded []byte
+}
+
+// proposalJSON is serialized form of the proposal, it does not inherit custom (un)marshalers of ServiceProposal
+type proposalJSON ServiceProposal
+
+// MarshalJSON serializes received proposal as it was signed, so that fields unknown to this node are kept.
+// Proposal is serialized from its fields when it was created or modified locally.
+func (proposal ServiceProposal) MarshalJSON() ([]byte, error) {
+	if payload, received := proposal.receivedPayload(); received {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &object); err != nil {
+			return nil, err
+		}
+		signature, err := json.Marshal(proposal.Signature)
+		if err != nil {
+			return nil, err
+		}
+		object["signature"] = signature
+		return json.Marshal(object)
+	}
+
+	return json.Marshal(proposalJSON(proposal))
 }
 
 // UnmarshalJSON is custom json unmarshaler to dynamically fill in ServiceProposal values
@@ -66,6 +103,7 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 		ServiceDefinition *json.RawMessage `json:"service_definition"`
 		PaymentMethod     *json.RawMessage `json:"payment_method"`
 		ProviderContacts  *json.RawMessage `json:"provider_contacts"`
+		Signature         string           `json:"signature"`
 	}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
 		return err
@@ -76,6 +114,7 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 	proposal.ServiceType = jsonData.ServiceType
 	proposal.ProviderID = jsonData.ProviderID
 	proposal.PaymentMethodType = jsonData.PaymentMethodType
+	proposal.Signature = jsonData.Signature
 
 	// run the service definition implementation from our registry
 	proposal.ServiceDefinition = unserializeServiceDefinition(
@@ -92,18 +131,108 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 	// run contact unserializer
 	proposal.ProviderContacts = unserializeContacts(jsonData.ProviderContacts)
 
+	// keep signed payload when it can not be reproduced from decoded fields
+	proposal.signed = nil
+	if proposal.Signature == "" {
+		return nil
+	}
+	payload, err := canonicalizeJSON(data)
+	if err != nil {
+		return err
+	}
+	decoded, err := proposal.canonicalJSON()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(payload, decoded) {
+		proposal.signed = &signedForm{payload: payload, decoded: decoded}
+	}
 	return nil
 }
 
 // SetProviderContact updates service proposal description with general data
 func (proposal *ServiceProposal) SetProviderContact(providerID identity.Identity, providerContact Contact) {
 	proposal.Format = proposalFormat
-	// TODO This will be generated later
-	proposal.ID = 1
 	proposal.ProviderID = providerID.Address
 	proposal.ProviderContacts = ContactList{providerContact}
 }
 
+// Sign signs canonical form of the proposal by provider identity
+func (proposal *ServiceProposal) Sign(signer identity.Signer) error {
+	message, err := proposal.canonicalJSON()
+	if err != nil {
+		return err
+	}
+
+	signature, err := signer.Sign(message)
+	if err != nil {
+		return err
+	}
+
+	proposal.Signature = signature.Base64()
+	proposal.signed = nil
+	return nil
+}
+
+// IsSigned returns true if the proposal signature recovers to the identity of proposal provider
+func (proposal *ServiceProposal) IsSigned() bool {
+	if proposal.Signature == "" {
+		return false
+	}
+
+	message, received := proposal.receivedPayload()
+	if !received {
+		var err error
+		if message, err = proposal.canonicalJSON(); err != nil {
+			return false
+		}
+	}
+
+	verifier := identity.NewVerifierIdentity(identity.FromAddress(proposal.ProviderID))
+	return verifier.Verify(message, identity.SignatureBase64(proposal.Signature))
+}
+
+// receivedPayload returns canonical form of the proposal as it was received and signed by provider,
+// it is found only when the proposal has fields unknown to this node and it was not modified since it was received
+func (proposal *ServiceProposal) receivedPayload() ([]byte, bool) {
+	if proposal.signed == nil {
+		return nil, false
+	}
+
+	decoded, err := proposal.canonicalJSON()
+	if err != nil || !bytes.Equal(decoded, proposal.signed.decoded) {
+		return nil, false
+	}
+	return proposal.signed.payload, true
+}
+
+// canonicalJSON serializes the proposal without signature, with object keys sorted and without whitespace,
+// so that provider and consumer sign and verify exactly the same bytes
+func (proposal ServiceProposal) canonicalJSON() ([]byte, error) {
+	proposal.Signature = ""
+	data, err := json.Marshal(proposalJSON(proposal))
+	if err != nil {
+		return nil, err
+	}
+	return canonicalizeJSON(data)
+}
+
+// canonicalizeJSON sorts object keys, removes whitespace and signature of the given serialized proposal.
+// Numbers and fields unknown to this node are kept as they are.
+func canonicalizeJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		delete(object, "signature")
+	}
+	return json.Marshal(value)
+}
+
 // IsSupported returns true if this service proposal can be used for connections by service consumer
 // can be used as a filter to filter out all proposals which are unsupported for any reason
 func (proposal *ServiceProposal) IsSupported() bool {
@@ -113,6 +242,9 @@ func (proposal *ServiceProposal) IsSupported() bool {
 	if _, paymentNotSupported := proposal.PaymentMethod.(UnsupportedPaymentMethod); paymentNotSupported {
 		return false
 	}
+	if !proposal.IsSigned() {
+		return false
+	}
 
 	for _, contact := range proposal.ProviderContacts {
 		if _, notSupported := contact.Definition.(UnsupportedContactType); notSupported {
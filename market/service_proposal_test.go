@@ -19,6 +19,7 @@ package market
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/mysteriumnetwork/node/identity"
@@ -42,7 +43,7 @@ func Test_ServiceProposal_SetProviderContact(t *testing.T) {
 	assert.Exactly(
 		t,
 		ServiceProposal{
-			ID:               1,
+			ID:               123,
 			Format:           proposalFormat,
 			ProviderID:       providerID.Address,
 			ProviderContacts: ContactList{providerContact},
@@ -149,7 +150,116 @@ func Test_ServiceProposal_Unserialize(t *testing.T) {
 		},
 	}
 	assert.Equal(t, expected, actual)
+	assert.False(t, actual.IsSupported(), "unsigned proposal must not be supported")
+}
+
+func Test_ServiceProposal_SignAndVerify(t *testing.T) {
+	proposal := signedProposal(t)
+	assert.NotEmpty(t, proposal.Signature)
+	assert.True(t, proposal.IsSigned())
+	assert.True(t, proposal.IsSupported())
+
+	jsonBytes, err := json.Marshal(proposal)
+	assert.NoError(t, err)
+
+	var actual ServiceProposal
+	assert.NoError(t, json.Unmarshal(jsonBytes, &actual))
+	assert.Equal(t, proposal.Signature, actual.Signature)
+	assert.True(t, actual.IsSupported())
+}
+
+func Test_ServiceProposal_TamperedProposalIsNotSupported(t *testing.T) {
+	proposal := signedProposal(t)
+	proposal.ProviderContacts = ContactList{Contact{Type: "mock_contact", Definition: rogueContact{Topic: "rogue"}}}
+	assert.False(t, proposal.IsSigned())
+	assert.False(t, proposal.IsSupported())
+}
+
+func Test_ServiceProposal_ProposalSignedByOtherIdentityIsNotSupported(t *testing.T) {
+	proposal := signedProposal(t)
+	proposal.ProviderID = "0x1e35193c8cadaa15b43b05ae3d882c91f49bb0aa"
+	assert.False(t, proposal.IsSigned())
+}
+
+func Test_ServiceProposal_ProposalWithUnknownFieldsIsVerifiedAsReceived(t *testing.T) {
+	keystore := identity.NewKeystoreFilesystem("../identity/test_data", true)
+	assert.NoError(t, identity.NewIdentityManager(keystore).Unlock(signerID.Address, ""))
+
+	// proposal announced by a newer provider, with a field and a contact type unknown to this node
+	var payload map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(`{
+		"id": 1,
+		"format": "service-proposal/v2",
+		"service_type": "mock_service",
+		"service_definition": {},
+		"payment_method_type": "mock_payment",
+		"payment_method": {},
+		"provider_id": "0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68",
+		"provider_contacts": [
+			{"type": "future_contact", "definition": {"relay": "relay.example.com"}},
+			{"type": "mock_contact", "definition": {}}
+		],
+		"access_policies": [{"id": "verified-traffic"}]
+	}`), &payload))
+	message, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	message, err = canonicalizeJSON(message)
+	assert.NoError(t, err)
+	signature, err := identity.NewSigner(keystore, signerID).Sign(message)
+	assert.NoError(t, err)
+	payload["signature"] = signature.Base64()
+	jsonData, err := json.Marshal(payload)
+	assert.NoError(t, err)
+
+	var actual ServiceProposal
+	assert.NoError(t, json.Unmarshal(jsonData, &actual))
+	assert.Equal(t, UnsupportedContactType{}, actual.ProviderContacts[0].Definition)
+	assert.True(t, actual.IsSigned())
 	assert.True(t, actual.IsSupported())
+
+	// proposal is forwarded and cached as it was signed
+	jsonBytes, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	assert.Contains(t, string(jsonBytes), `"access_policies"`)
+	var forwarded ServiceProposal
+	assert.NoError(t, json.Unmarshal(jsonBytes, &forwarded))
+	assert.True(t, forwarded.IsSupported())
+
+	// proposal modified after it was received does not match its signature
+	actual.ProviderContacts = actual.ProviderContacts[1:]
+	assert.False(t, actual.IsSigned())
+	assert.False(t, actual.IsSupported())
+}
+
+func Test_ServiceProposal_SignFails(t *testing.T) {
+	proposal := ServiceProposal{ProviderID: signerID.Address}
+	err := proposal.Sign(&identity.SignerFake{ErrorMock: errors.New("identity is locked")})
+	assert.EqualError(t, err, "identity is locked")
+	assert.Empty(t, proposal.Signature)
+}
+
+var signerID = identity.FromAddress("0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+
+func signedProposal(t *testing.T) ServiceProposal {
+	keystore := identity.NewKeystoreFilesystem("../identity/test_data", true)
+	assert.NoError(t, identity.NewIdentityManager(keystore).Unlock(signerID.Address, ""))
+
+	proposal := ServiceProposal{
+		ID:                1,
+		Format:            proposalFormat,
+		ServiceType:       "mock_service",
+		ServiceDefinition: serviceDefinition,
+		PaymentMethodType: "mock_payment",
+		PaymentMethod:     paymentMethod,
+		ProviderID:        signerID.Address,
+		ProviderContacts:  ContactList{Contact{Type: "mock_contact", Definition: mockContact{}}},
+	}
+	assert.NoError(t, proposal.Sign(identity.NewSigner(keystore, signerID)))
+	return proposal
+}
+
+type rogueContact struct {
+	Topic string `json:"topic"`
 }
 
 func Test_ServiceProposal_UnserializeUnknownService(t *testing.T) {
@@ -24,7 +24,7 @@ import (
 // NetworkDefinition structure holds all parameters which describe particular network
 type NetworkDefinition struct {
 	DiscoveryAPIAddress     string
-	BrokerAddress           string
+	BrokerAddresses         []string
 	EtherClientRPC          string
 	QualityOracle           string
 	PaymentsContractAddress common.Address
@@ -33,7 +33,7 @@ type NetworkDefinition struct {
 // TestnetDefinition defines parameters for test network (currently default network)
 var TestnetDefinition = NetworkDefinition{
 	"https://testnet-api.mysterium.network/v1",
-	"nats://testnet-broker.mysterium.network",
+	[]string{"nats://testnet-broker.mysterium.network"},
 	"https://ropsten.infura.io",
 	"https://testnet-morqa.mysterium.network/api/v1",
 	common.HexToAddress("0xbe5F9CCea12Df756bF4a5Baf4c29A10c3ee7C83B"),
@@ -42,7 +42,7 @@ var TestnetDefinition = NetworkDefinition{
 // LocalnetDefinition defines parameters for local network (expects discovery and broker services on localhost)
 var LocalnetDefinition = NetworkDefinition{
 	"http://localhost/v1",
-	"localhost",
+	[]string{"localhost"},
 	"http://localhost:8545",
 	"http://localhost:8080",
 	common.HexToAddress("0x1955141ba8e77a5B56efBa8522034352c94f77Ea"),
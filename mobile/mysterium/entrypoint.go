@@ -19,6 +19,7 @@ package mysterium
 
 import (
 	"path/filepath"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/mysteriumnetwork/node/cmd"
@@ -68,7 +69,8 @@ func NewNode(appPath string, optionsNetwork *MobileNetworkOptions) (*MobileNode,
 		},
 
 		Location: node.OptionsLocation{
-			IpifyUrl: "https://api.ipify.org/",
+			IpifyUrl:  "https://api.ipify.org/",
+			IPSources: "https://checkip.amazonaws.com/,stun:stun.l.google.com:19302",
 		},
 
 		OptionsNetwork: node.OptionsNetwork(*optionsNetwork),
@@ -87,7 +89,7 @@ func DefaultNetworkOptions() *MobileNetworkOptions {
 		ExperimentIdentityCheck: false,
 		ExperimentPayments:      false,
 		DiscoveryAPIAddress:     metadata.TestnetDefinition.DiscoveryAPIAddress,
-		BrokerAddress:           metadata.TestnetDefinition.BrokerAddress,
+		BrokerAddress:           strings.Join(metadata.TestnetDefinition.BrokerAddresses, ","),
 		EtherClientRPC:          metadata.TestnetDefinition.EtherClientRPC,
 		EtherPaymentsAddress:    metadata.DefaultNetwork.PaymentsContractAddress.String(),
 	}
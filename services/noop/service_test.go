@@ -41,7 +41,7 @@ func Test_GetProposal(t *testing.T) {
 		market.ServiceProposal{
 			ServiceType: "noop",
 			ServiceDefinition: ServiceDefinition{
-				Location: market.Location{Country: country},
+				Location: market.Location{Country: country, City: "Vilnius", ASN: "AS8764"},
 			},
 
 			PaymentMethodType: "NOOP",
@@ -52,7 +52,7 @@ func Test_GetProposal(t *testing.T) {
 				},
 			},
 		},
-		GetProposal(country),
+		GetProposal(market.Location{Country: country, City: "Vilnius", ASN: "AS8764"}),
 	)
 }
 
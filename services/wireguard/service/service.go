@@ -116,12 +116,12 @@ func (manager *Manager) Serve(providerID identity.Identity) error {
 }
 
 // GetProposal returns the proposal for wireguard service
-func GetProposal(country string) market.ServiceProposal {
+func GetProposal(location market.Location) market.ServiceProposal {
 	return market.ServiceProposal{
 		ServiceType: wg.ServiceType,
 		ServiceDefinition: wg.ServiceDefinition{
-			Location:          market.Location{Country: country},
-			LocationOriginate: market.Location{Country: country},
+			Location:          location,
+			LocationOriginate: location,
 		},
 		PaymentMethodType: wg.PaymentMethod,
 		PaymentMethod: wg.Payment{
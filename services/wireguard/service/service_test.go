@@ -46,8 +46,8 @@ func Test_GetProposal(t *testing.T) {
 		market.ServiceProposal{
 			ServiceType: "wireguard",
 			ServiceDefinition: wg.ServiceDefinition{
-				Location:          market.Location{Country: country},
-				LocationOriginate: market.Location{Country: country},
+				Location:          market.Location{Country: country, City: "Vilnius", ASN: "AS8764"},
+				LocationOriginate: market.Location{Country: country, City: "Vilnius", ASN: "AS8764"},
 			},
 			PaymentMethodType: "WG",
 			PaymentMethod: wg.Payment{
@@ -57,7 +57,7 @@ func Test_GetProposal(t *testing.T) {
 				},
 			},
 		},
-		GetProposal(country),
+		GetProposal(market.Location{Country: country, City: "Vilnius", ASN: "AS8764"}),
 	)
 }
 
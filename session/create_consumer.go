@@ -28,6 +28,7 @@ import (
 type createConsumer struct {
 	sessionCreator Creator
 	peerID         identity.Identity
+	protocol       communication.ProtocolInfo
 	configProvider ConfigProvider
 }
 
@@ -51,6 +52,15 @@ func (consumer *createConsumer) NewRequest() (requestPtr interface{}) {
 func (consumer *createConsumer) Consume(requestPtr interface{}) (response interface{}, err error) {
 	request := requestPtr.(*CreateRequest)
 
+	var consumerProtocol *communication.ProtocolInfo
+	if request.ConsumerInfo != nil {
+		consumerProtocol = request.ConsumerInfo.Protocol
+	}
+	protocol, err := consumer.protocol.Negotiate(communication.ProtocolInfoOrLegacy(consumerProtocol))
+	if err != nil {
+		return responseIncompatibleProtocol(err, consumer.protocol), nil
+	}
+
 	config, destroyCallback, err := consumer.configProvider(request.Config)
 	if err != nil {
 		return responseInternalError, err
@@ -70,7 +80,11 @@ func (consumer *createConsumer) Consume(requestPtr interface{}) (response interf
 				destroyCallback()
 			}()
 		}
-		return responseWithSession(sessionInstance, config, nil), nil
+		response := responseWithSession(sessionInstance, config, nil)
+		if response.Success {
+			response.Protocol = &protocol
+		}
+		return response, nil
 	case ErrorInvalidProposal:
 		return responseInvalidProposal, nil
 	default:
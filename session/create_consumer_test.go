@@ -22,6 +22,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/stretchr/testify/assert"
 )
@@ -43,6 +44,7 @@ func TestConsumer_Success(t *testing.T) {
 	consumer := createConsumer{
 		sessionCreator: mockManager,
 		peerID:         identity.FromAddress("peer-id"),
+		protocol:       communication.NewProtocolInfo(),
 		configProvider: mockConsumer,
 	}
 
@@ -61,17 +63,50 @@ func TestConsumer_Success(t *testing.T) {
 				ID:     "new-id",
 				Config: config,
 			},
+			Protocol: &communication.ProtocolInfo{Version: 1, MinVersion: 1},
 		},
 		sessionResponse,
 	)
 }
 
+func TestConsumer_ErrorIncompatibleProtocol(t *testing.T) {
+	mockManager := &managerFake{}
+	providerProtocol := communication.NewProtocolInfo(communication.CapabilityPayments).Require(communication.CapabilityPayments)
+	consumer := createConsumer{
+		sessionCreator: mockManager,
+		peerID:         identity.FromAddress("peer-id"),
+		protocol:       providerProtocol,
+		configProvider: mockConsumer,
+	}
+
+	consumerProtocol := communication.NewProtocolInfo(communication.CapabilityCodecJSON)
+	request := consumer.NewRequest().(*CreateRequest)
+	request.ProposalID = 101
+	request.ConsumerInfo = &ConsumerInfo{
+		Protocol: &consumerProtocol,
+	}
+	sessionResponse, err := consumer.Consume(request)
+
+	assert.NoError(t, err)
+	assert.Exactly(
+		t,
+		CreateResponse{
+			Success:  false,
+			Message:  "incompatible protocol: peer does not support payments",
+			Protocol: &providerProtocol,
+		},
+		sessionResponse,
+	)
+	assert.Exactly(t, 0, mockManager.lastProposalID)
+}
+
 func TestConsumer_ErrorInvalidProposal(t *testing.T) {
 	mockManager := &managerFake{
 		returnError: ErrorInvalidProposal,
 	}
 	consumer := createConsumer{
 		sessionCreator: mockManager,
+		protocol:       communication.NewProtocolInfo(),
 		configProvider: mockConsumer,
 	}
 
@@ -88,6 +123,7 @@ func TestConsumer_ErrorFatal(t *testing.T) {
 	}
 	consumer := createConsumer{
 		sessionCreator: mockManager,
+		protocol:       communication.NewProtocolInfo(),
 		configProvider: mockConsumer,
 	}
 
@@ -108,6 +144,7 @@ func TestConsumer_UsesIssuerID(t *testing.T) {
 	consumer := createConsumer{
 		sessionCreator: mockManager,
 		peerID:         identity.FromAddress("peer-id"),
+		protocol:       communication.NewProtocolInfo(),
 		configProvider: mockConsumer,
 	}
 
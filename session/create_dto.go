@@ -31,6 +31,10 @@ var (
 	responseInternalError   = CreateResponse{Success: false, Message: "Internal Error"}
 )
 
+func responseIncompatibleProtocol(err error, protocol communication.ProtocolInfo) CreateResponse {
+	return CreateResponse{Success: false, Message: err.Error(), Protocol: &protocol}
+}
+
 // CreateRequest structure represents message from service consumer to initiate session for given proposal id
 type CreateRequest struct {
 	ProposalID   int             `json:"proposal_id"`
@@ -58,6 +62,8 @@ type CreateResponse struct {
 	Session SessionDto `json:"session"`
 	// Keeping this as a pointer for maximum backwards compatibility
 	PaymentInfo *PaymentInfo `json:"paymentInfo,omitempty"`
+	// Protocol is the protocol negotiated for the session, legacy providers do not send it
+	Protocol *communication.ProtocolInfo `json:"protocol,omitempty"`
 }
 
 // SessionDto structure represents session information data within session creation response (session id and configuration options for underlying service type)
@@ -68,6 +74,7 @@ type SessionDto struct {
 
 // ConsumerInfo represents the consumer related information
 type ConsumerInfo struct {
-	MystClientVersion string            `json:"mystClientVersion"`
-	IssuerID          identity.Identity `json:"issuerID"`
+	MystClientVersion string                      `json:"mystClientVersion"`
+	IssuerID          identity.Identity           `json:"issuerID"`
+	Protocol          *communication.ProtocolInfo `json:"protocol,omitempty"`
 }
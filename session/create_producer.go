@@ -68,6 +68,13 @@ func RequestSessionCreate(sender communication.Sender, proposalID int, config in
 		return
 	}
 
+	if ci.Protocol != nil && response.Protocol != nil {
+		if _, err = ci.Protocol.Negotiate(*response.Protocol); err != nil {
+			err = errors.New("Session create failed. " + err.Error())
+			return
+		}
+	}
+
 	sessionID = response.Session.ID
 	sessionConfig = response.Session.Config
 	return
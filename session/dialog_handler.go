@@ -18,10 +18,15 @@
 package session
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/identity"
 )
 
+// sessionSetupTimeout defines how long dialog is kept without any session created in it
+const sessionSetupTimeout = 5 * time.Minute
+
 // ManagerFactory initiates session Manager instance during runtime
 type ManagerFactory func(dialog communication.Dialog) *Manager
 
@@ -30,12 +35,14 @@ func NewDialogHandler(sessionManagerFactory ManagerFactory, configProvider Confi
 	return &handler{
 		sessionManagerFactory: sessionManagerFactory,
 		configProvider:        configProvider,
+		sessionSetupTimeout:   sessionSetupTimeout,
 	}
 }
 
 type handler struct {
 	sessionManagerFactory ManagerFactory
 	configProvider        ConfigProvider
+	sessionSetupTimeout   time.Duration
 }
 
 // Handle starts serving services in given Dialog instance
@@ -44,19 +51,22 @@ func (handler *handler) Handle(dialog communication.Dialog) error {
 }
 
 func (handler *handler) subscribeSessionRequests(dialog communication.Dialog) error {
+	lifetime := newDialogLifetime(handler.sessionManagerFactory(dialog), dialog.Unsubscribe, handler.sessionSetupTimeout)
 	err := dialog.Respond(
 		&createConsumer{
-			sessionCreator: handler.sessionManagerFactory(dialog),
+			sessionCreator: lifetime,
 			peerID:         dialog.PeerID(),
+			protocol:       dialog.Protocol(),
 			configProvider: handler.configProvider,
 		},
 	)
 
 	if err != nil {
+		lifetime.Stop()
 		return err
 	}
 
-	return dialog.Respond(
+	err = dialog.Respond(
 		&destroyConsumer{
 			SessionDestroyer: &sessionDestroyer{
 				destroyer:   handler.sessionManagerFactory(dialog),
@@ -65,6 +75,10 @@ func (handler *handler) subscribeSessionRequests(dialog communication.Dialog) er
 			PeerID: dialog.PeerID(),
 		},
 	)
+	if err != nil {
+		lifetime.Stop()
+	}
+	return err
 }
 
 type sessionDestroyer struct {
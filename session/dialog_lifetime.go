@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const dialogLifetimeLogPrefix = "[session-dialog] "
+
+// dialogLifetime releases dialog once its consumer is gone:
+// when no session is created in time after dialog is established, or when the last session of the dialog ends
+type dialogLifetime struct {
+	creator     Creator
+	unsubscribe func()
+	setupTimer  *time.Timer
+
+	lock     sync.Mutex
+	sessions int
+}
+
+func newDialogLifetime(creator Creator, unsubscribe func(), setupTimeout time.Duration) *dialogLifetime {
+	lifetime := &dialogLifetime{
+		creator:     creator,
+		unsubscribe: unsubscribe,
+	}
+	lifetime.setupTimer = time.AfterFunc(setupTimeout, func() {
+		log.Info(dialogLifetimeLogPrefix, "no session created in ", setupTimeout, ", releasing dialog")
+		unsubscribe()
+	})
+	return lifetime
+}
+
+// Create creates session and ties dialog to its lifetime
+func (lifetime *dialogLifetime) Create(consumerID, issuerID identity.Identity, proposalID int) (Session, error) {
+	sessionInstance, err := lifetime.creator.Create(consumerID, issuerID, proposalID)
+	if err != nil {
+		return sessionInstance, err
+	}
+
+	lifetime.lock.Lock()
+	lifetime.setupTimer.Stop()
+	lifetime.sessions++
+	lifetime.lock.Unlock()
+
+	go func() {
+		<-sessionInstance.Done
+		if lifetime.sessionEnded() {
+			lifetime.unsubscribe()
+		}
+	}()
+	return sessionInstance, nil
+}
+
+// sessionEnded tells whether the ended session was the last live session of the dialog
+func (lifetime *dialogLifetime) sessionEnded() bool {
+	lifetime.lock.Lock()
+	defer lifetime.lock.Unlock()
+
+	lifetime.sessions--
+	return lifetime.sessions == 0
+}
+
+// Stop cancels pending release of the dialog
+func (lifetime *dialogLifetime) Stop() {
+	lifetime.setupTimer.Stop()
+}
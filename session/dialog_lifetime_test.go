@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialogLifetime_ReleasesDialogWithoutSession(t *testing.T) {
+	released := make(chan struct{}, 1)
+	newDialogLifetime(&managerFake{}, func() { released <- struct{}{} }, time.Millisecond)
+
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("dialog was not released")
+	}
+}
+
+func TestDialogLifetime_ReleasesDialogWhenSessionEnds(t *testing.T) {
+	done := make(chan struct{})
+	released := make(chan struct{}, 1)
+	lifetime := newDialogLifetime(
+		&managerFake{returnSession: Session{ID: "new-id", Done: done}},
+		func() { released <- struct{}{} },
+		10*time.Millisecond,
+	)
+
+	_, err := lifetime.Create(identity.FromAddress("consumer"), identity.FromAddress("consumer"), 1)
+	assert.NoError(t, err)
+
+	select {
+	case <-released:
+		t.Fatal("dialog was released while session is running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(done)
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("dialog was not released")
+	}
+}
+
+func TestDialogLifetime_ReleasesDialogWhenLastSessionEnds(t *testing.T) {
+	firstDone := make(chan struct{})
+	secondDone := make(chan struct{})
+	released := make(chan struct{}, 1)
+	manager := &managerFake{returnSession: Session{ID: "first", Done: firstDone}}
+	lifetime := newDialogLifetime(manager, func() { released <- struct{}{} }, 10*time.Millisecond)
+
+	_, err := lifetime.Create(identity.FromAddress("consumer"), identity.FromAddress("consumer"), 1)
+	assert.NoError(t, err)
+	manager.returnSession = Session{ID: "second", Done: secondDone}
+	_, err = lifetime.Create(identity.FromAddress("consumer"), identity.FromAddress("consumer"), 1)
+	assert.NoError(t, err)
+
+	close(firstDone)
+	select {
+	case <-released:
+		t.Fatal("dialog was released while another session is running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(secondDone)
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("dialog was not released")
+	}
+}
+
+func TestDialogLifetime_KeepsWaitingForSessionAfterFailedCreate(t *testing.T) {
+	released := make(chan struct{}, 1)
+	lifetime := newDialogLifetime(
+		&managerFake{returnError: errors.New("no proposal")},
+		func() { released <- struct{}{} },
+		10*time.Millisecond,
+	)
+
+	_, err := lifetime.Create(identity.FromAddress("consumer"), identity.FromAddress("consumer"), 1)
+	assert.EqualError(t, err, "no proposal")
+
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("dialog was not released")
+	}
+}
@@ -67,18 +67,21 @@ type Storage interface {
 	Remove(id ID)
 }
 
+// ProposalLookup finds proposal currently published by provider
+type ProposalLookup func(proposalID int) (proposal market.ServiceProposal, found bool)
+
 // BalanceTrackerFactory returns a new instance of balance tracker
 type BalanceTrackerFactory func(consumer, provider, issuer identity.Identity) (BalanceTracker, error)
 
 // NewManager returns new session Manager
 func NewManager(
-	currentProposal market.ServiceProposal,
+	proposalLookup ProposalLookup,
 	idGenerator IDGenerator,
 	sessionStorage Storage,
 	balanceTrackerFactory BalanceTrackerFactory,
 ) *Manager {
 	return &Manager{
-		currentProposal:       currentProposal,
+		proposalLookup:        proposalLookup,
 		generateID:            idGenerator,
 		sessionStorage:        sessionStorage,
 		balanceTrackerFactory: balanceTrackerFactory,
@@ -89,7 +92,7 @@ func NewManager(
 
 // Manager knows how to start and provision session
 type Manager struct {
-	currentProposal       market.ServiceProposal
+	proposalLookup        ProposalLookup
 	generateID            IDGenerator
 	sessionStorage        Storage
 	balanceTrackerFactory BalanceTrackerFactory
@@ -102,7 +105,8 @@ func (manager *Manager) Create(consumerID identity.Identity, issuerID identity.I
 	manager.creationLock.Lock()
 	defer manager.creationLock.Unlock()
 
-	if manager.currentProposal.ID != proposalID {
+	proposal, found := manager.proposalLookup(proposalID)
+	if !found {
 		err = ErrorInvalidProposal
 		return
 	}
@@ -114,7 +118,7 @@ func (manager *Manager) Create(consumerID identity.Identity, issuerID identity.I
 	sessionInstance.ConsumerID = consumerID
 	sessionInstance.Done = make(chan struct{})
 
-	balanceTracker, err := manager.balanceTrackerFactory(consumerID, identity.FromAddress(manager.currentProposal.ProviderID), issuerID)
+	balanceTracker, err := manager.balanceTrackerFactory(consumerID, identity.FromAddress(proposal.ProviderID), issuerID)
 	if err != nil {
 		return
 	}
@@ -39,6 +39,10 @@ var (
 	}
 )
 
+func currentProposalLookup(proposalID int) (market.ServiceProposal, bool) {
+	return currentProposal, proposalID == currentProposalID
+}
+
 func generateSessionID() (ID, error) {
 	return expectedID, nil
 }
@@ -63,7 +67,7 @@ func TestManager_Create_StoresSession(t *testing.T) {
 	expectedResult := expectedSession
 
 	sessionStore := NewStorageMemory()
-	manager := NewManager(currentProposal, generateSessionID, sessionStore, mockBalanceTrackerFactory)
+	manager := NewManager(currentProposalLookup, generateSessionID, sessionStore, mockBalanceTrackerFactory)
 
 	sessionInstance, err := manager.Create(consumerID, consumerID, currentProposalID)
 	expectedResult.Done = sessionInstance.Done
@@ -73,9 +77,36 @@ func TestManager_Create_StoresSession(t *testing.T) {
 
 func TestManager_Create_RejectsUnknownProposal(t *testing.T) {
 	sessionStore := NewStorageMemory()
-	manager := NewManager(currentProposal, generateSessionID, sessionStore, mockBalanceTrackerFactory)
+	manager := NewManager(currentProposalLookup, generateSessionID, sessionStore, mockBalanceTrackerFactory)
 
 	sessionInstance, err := manager.Create(consumerID, consumerID, 69)
 	assert.Exactly(t, err, ErrorInvalidProposal)
 	assert.Exactly(t, Session{}, sessionInstance)
 }
+
+func TestManager_Create_AcceptsAnyPublishedProposal(t *testing.T) {
+	published := map[int]market.ServiceProposal{
+		1: {ID: 1, ProviderID: "0x1"},
+		2: {ID: 2, ProviderID: "0x1"},
+	}
+	proposalLookup := func(proposalID int) (market.ServiceProposal, bool) {
+		proposal, found := published[proposalID]
+		return proposal, found
+	}
+
+	var trackedProvider identity.Identity
+	balanceTrackerFactory := func(consumer, provider, issuer identity.Identity) (BalanceTracker, error) {
+		trackedProvider = provider
+		return &mockBalanceTracker{}, nil
+	}
+
+	manager := NewManager(proposalLookup, generateSessionID, NewStorageMemory(), balanceTrackerFactory)
+
+	_, err := manager.Create(consumerID, consumerID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, identity.FromAddress("0x1"), trackedProvider)
+
+	delete(published, 2)
+	_, err = manager.Create(consumerID, consumerID, 2)
+	assert.Exactly(t, ErrorInvalidProposal, err)
+}
@@ -60,6 +60,10 @@ func paymentIssuerFactory(signerFactory identity.SignerFactory) func(
 		messageChan chan balance.Message,
 		dialog communication.Dialog,
 		consumer, provider identity.Identity) (connection.PaymentIssuer, error) {
+		// provider which does not support payments won't send any balance messages
+		if !dialog.Protocol().Supports(communication.CapabilityPayments) {
+			return noopPaymentIssuerFactory(initialState, messageChan, dialog, consumer, provider)
+		}
 
 		bl := balance.NewListener(messageChan)
 		ps := promise.NewSender(dialog)
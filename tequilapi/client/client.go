@@ -18,9 +18,13 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
+	"time"
 
 	"github.com/mysteriumnetwork/node/tequilapi/endpoints"
 )
@@ -72,6 +76,118 @@ func (client *Client) NewIdentity(passphrase string) (id IdentityDTO, err error)
 	return id, err
 }
 
+// ExportIdentity returns encrypted keystore JSON of identity, re-encrypted with newPassphrase
+func (client *Client) ExportIdentity(address, passphrase, newPassphrase string) ([]byte, error) {
+	payload := struct {
+		Passphrase    string `json:"passphrase"`
+		NewPassphrase string `json:"newPassphrase"`
+	}{
+		passphrase,
+		newPassphrase,
+	}
+	response, err := client.http.Post("identities/"+address+"/export", payload)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return ioutil.ReadAll(response.Body)
+}
+
+// ImportIdentity imports identity from exported keystore JSON and encrypts it with newPassphrase
+func (client *Client) ImportIdentity(keystore []byte, passphrase, newPassphrase string) (id IdentityDTO, err error) {
+	payload := struct {
+		Keystore      json.RawMessage `json:"keystore"`
+		Passphrase    string          `json:"passphrase"`
+		NewPassphrase string          `json:"newPassphrase"`
+	}{
+		keystore,
+		passphrase,
+		newPassphrase,
+	}
+	response, err := client.http.Post("identities-import", payload)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &id)
+	return id, err
+}
+
+// ChangePassphrase re-encrypts identity with newPassphrase
+func (client *Client) ChangePassphrase(address, passphrase, newPassphrase string) error {
+	payload := struct {
+		OldPassphrase string `json:"oldPassphrase"`
+		NewPassphrase string `json:"newPassphrase"`
+	}{
+		passphrase,
+		newPassphrase,
+	}
+	response, err := client.http.Put("identities/"+address+"/passphrase", payload)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// DeleteIdentity removes identity from keystore
+func (client *Client) DeleteIdentity(address, passphrase string) error {
+	payload := struct {
+		Passphrase string `json:"passphrase"`
+	}{
+		passphrase,
+	}
+	response, err := client.http.Delete("identities/"+address, payload)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// SignMessage signs arbitrary message with unlocked identity and returns signature in base64 format
+func (client *Client) SignMessage(address, message string) (string, error) {
+	payload := struct {
+		Identity string `json:"identity"`
+		Message  string `json:"message"`
+	}{
+		address,
+		message,
+	}
+	response, err := client.http.Post("signatures", payload)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var signature MessageSignatureDTO
+	err = parseResponseJSON(response, &signature)
+	return signature.Signature, err
+}
+
+// VerifySignature returns identity which signed the message
+func (client *Client) VerifySignature(message, signature string) (id IdentityDTO, err error) {
+	payload := struct {
+		Message   string `json:"message"`
+		Signature string `json:"signature"`
+	}{
+		message,
+		signature,
+	}
+	response, err := client.http.Post("signatures/verify", payload)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &id)
+	return id, err
+}
+
 // IdentityRegistrationStatus returns information of identity needed to register it on blockchain
 func (client *Client) IdentityRegistrationStatus(address string) (RegistrationDataDTO, error) {
 	response, err := client.http.Get("identities/"+address+"/registration", url.Values{})
@@ -85,6 +201,32 @@ func (client *Client) IdentityRegistrationStatus(address string) (RegistrationDa
 	return status, err
 }
 
+// IdentityBalance returns MYST balance of identity kept in payments contract
+func (client *Client) IdentityBalance(address string) (IdentityBalanceDTO, error) {
+	response, err := client.http.Get("identities/"+address+"/balance", url.Values{})
+	if err != nil {
+		return IdentityBalanceDTO{}, err
+	}
+	defer response.Body.Close()
+
+	balance := IdentityBalanceDTO{}
+	err = parseResponseJSON(response, &balance)
+	return balance, err
+}
+
+// RegisterIdentity makes node submit registration transaction of identity
+func (client *Client) RegisterIdentity(address string) (RegistrationProgressDTO, error) {
+	response, err := client.http.Post("identities/"+address+"/registration", nil)
+	if err != nil {
+		return RegistrationProgressDTO{}, err
+	}
+	defer response.Body.Close()
+
+	progress := RegistrationProgressDTO{}
+	err = parseResponseJSON(response, &progress)
+	return progress, err
+}
+
 // Connect initiates a new connection to a host identified by providerID
 func (client *Client) Connect(consumerID, providerID, serviceType string, options endpoints.ConnectOptions) (status StatusDTO, err error) {
 	payload := struct {
@@ -196,6 +338,19 @@ func (client *Client) Proposals() ([]ProposalDTO, error) {
 	return proposals.Proposals, err
 }
 
+// ProposalsByQuery returns proposals filtered, sorted and paginated by given query
+func (client *Client) ProposalsByQuery(query ProposalsQuery) (ProposalList, error) {
+	var proposals ProposalList
+	response, err := client.http.Get("proposals", query.toURLValues())
+	if err != nil {
+		return proposals, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &proposals)
+	return proposals, err
+}
+
 // GetIP returns public ip
 func (client *Client) GetIP() (string, error) {
 	response, err := client.http.Get("connection/ip", url.Values{})
@@ -213,11 +368,18 @@ func (client *Client) GetIP() (string, error) {
 
 // Unlock allows using identity in following commands
 func (client *Client) Unlock(identity, passphrase string) error {
+	return client.UnlockWithTimeout(identity, passphrase, 0)
+}
+
+// UnlockWithTimeout unlocks the identity until timeout passes, zero timeout keeps it unlocked until it gets idle
+func (client *Client) UnlockWithTimeout(identity, passphrase string, timeout time.Duration) error {
 	path := fmt.Sprintf("identities/%s/unlock", identity)
 	payload := struct {
 		Passphrase string `json:"passphrase"`
+		Timeout    int64  `json:"timeout,omitempty"`
 	}{
 		passphrase,
+		int64(timeout / time.Second),
 	}
 
 	response, err := client.http.Put(path, payload)
@@ -229,6 +391,55 @@ func (client *Client) Unlock(identity, passphrase string) error {
 	return nil
 }
 
+// Lock locks the identity
+func (client *Client) Lock(identity string) error {
+	path := fmt.Sprintf("identities/%s/lock", identity)
+	response, err := client.http.Put(path, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ProviderPreferences returns providers of the favorite or blocked list
+func (client *Client) ProviderPreferences(list string) ([]ProviderPreferenceDTO, error) {
+	response, err := client.http.Get("preferences/providers/"+list, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var preferences ProviderPreferenceList
+	err = parseResponseJSON(response, &preferences)
+	return preferences.Providers, err
+}
+
+// AddProviderPreference adds provider to the favorite or blocked list
+func (client *Client) AddProviderPreference(list, providerID string) error {
+	path := fmt.Sprintf("preferences/providers/%s/%s", list, providerID)
+	response, err := client.http.Put(path, struct{}{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// RemoveProviderPreference removes provider from the favorite or blocked list
+func (client *Client) RemoveProviderPreference(list, providerID string) error {
+	path := fmt.Sprintf("preferences/providers/%s/%s", list, providerID)
+	response, err := client.http.Delete(path, struct{}{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
 // Stop kills mysterium client
 func (client *Client) Stop() error {
 	emptyPayload := struct{}{}
@@ -241,10 +452,27 @@ func (client *Client) Stop() error {
 	return nil
 }
 
+// StorageBackup writes a consistent snapshot of node database to the given writer
+func (client *Client) StorageBackup(w io.Writer) error {
+	response, err := client.http.Get("storage/backup", url.Values{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	_, err = io.Copy(w, response.Body)
+	return err
+}
+
 // GetSessions returns all sessions from history
 func (client *Client) GetSessions() (endpoints.SessionsDTO, error) {
+	return client.QuerySessions(url.Values{})
+}
+
+// QuerySessions returns sessions from history matching the given query parameters
+func (client *Client) QuerySessions(query url.Values) (endpoints.SessionsDTO, error) {
 	sessions := endpoints.SessionsDTO{}
-	response, err := client.http.Get("sessions", url.Values{})
+	response, err := client.http.Get("sessions", query)
 	if err != nil {
 		return sessions, err
 	}
@@ -254,42 +482,12 @@ func (client *Client) GetSessions() (endpoints.SessionsDTO, error) {
 	return sessions, err
 }
 
-// filterSessionsByType removes all sessions of irrelevant types
-func filterSessionsByType(serviceType string, sessions endpoints.SessionsDTO) endpoints.SessionsDTO {
-	matches := 0
-	for _, s := range sessions.Sessions {
-		if s.ServiceType == serviceType {
-			sessions.Sessions[matches] = s
-			matches++
-		}
-	}
-	sessions.Sessions = sessions.Sessions[:matches]
-	return sessions
-}
-
-// filterSessionsByStatus removes all sessions with non matching status
-func filterSessionsByStatus(status string, sessions endpoints.SessionsDTO) endpoints.SessionsDTO {
-	matches := 0
-	for _, s := range sessions.Sessions {
-		if s.Status == status {
-			sessions.Sessions[matches] = s
-			matches++
-		}
-	}
-	sessions.Sessions = sessions.Sessions[:matches]
-	return sessions
-}
-
 // GetSessionsByType returns sessions from history filtered by type
 func (client *Client) GetSessionsByType(serviceType string) (endpoints.SessionsDTO, error) {
-	sessions, err := client.GetSessions()
-	sessions = filterSessionsByType(serviceType, sessions)
-	return sessions, err
+	return client.QuerySessions(url.Values{"serviceType": []string{serviceType}})
 }
 
 // GetSessionsByStatus returns sessions from history filtered by their status
 func (client *Client) GetSessionsByStatus(status string) (endpoints.SessionsDTO, error) {
-	sessions, err := client.GetSessions()
-	sessions = filterSessionsByStatus(status, sessions)
-	return sessions, err
+	return client.QuerySessions(url.Values{"status": []string{status}})
 }
@@ -17,13 +17,32 @@
 
 package client
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
 
 // StatusDTO holds connection status and session id
 type StatusDTO struct {
-	Status    string      `json:"status"`
-	SessionID string      `json:"sessionId"`
-	Proposal  ProposalDTO `json:"proposal"`
+	Status    string        `json:"status"`
+	SessionID string        `json:"sessionId"`
+	Proposal  ProposalDTO   `json:"proposal"`
+	LeakCheck *LeakCheckDTO `json:"leakCheck"`
+}
+
+// LeakCheckDTO holds result of the check whether connection traffic exits via provider
+type LeakCheckDTO struct {
+	CheckedAt       string `json:"checkedAt"`
+	OriginalIP      string `json:"originalIp"`
+	ExitIP          string `json:"exitIp"`
+	ExitCountry     string `json:"exitCountry"`
+	ExpectedCountry string `json:"expectedCountry"`
+	IPLeak          bool   `json:"ipLeak"`
+	CountryMismatch bool   `json:"countryMismatch"`
+	DNSChecked      bool   `json:"dnsChecked"`
+	DNSLeak         bool   `json:"dnsLeak"`
+	Error           string `json:"error"`
 }
 
 // StatisticsDTO holds statistics about connection
@@ -36,13 +55,93 @@ type StatisticsDTO struct {
 // ProposalList describes list of proposals
 type ProposalList struct {
 	Proposals []ProposalDTO `json:"proposals"`
+	Page      int           `json:"page"`
+	Limit     int           `json:"limit"`
+	Total     int           `json:"total"`
+}
+
+// ProposalsQuery holds filtering, sorting and pagination options for proposals listing.
+// Zero values are not sent to the server.
+type ProposalsQuery struct {
+	ProviderID         string
+	ServiceType        string
+	Country            string
+	City               string
+	ASN                string
+	PaymentMethod      string
+	MaxPrice           *uint64
+	MinQuality         *float64
+	Sort               string
+	Page               int
+	Limit              int
+	FetchConnectCounts bool
+}
+
+func (query ProposalsQuery) toURLValues() url.Values {
+	values := url.Values{}
+	setIfNotEmpty := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	setIfNotEmpty("providerId", query.ProviderID)
+	setIfNotEmpty("serviceType", query.ServiceType)
+	setIfNotEmpty("country", query.Country)
+	setIfNotEmpty("city", query.City)
+	setIfNotEmpty("asn", query.ASN)
+	setIfNotEmpty("paymentMethod", query.PaymentMethod)
+	setIfNotEmpty("sort", query.Sort)
+	if query.MaxPrice != nil {
+		values.Set("maxPrice", strconv.FormatUint(*query.MaxPrice, 10))
+	}
+	if query.MinQuality != nil {
+		values.Set("minQuality", strconv.FormatFloat(*query.MinQuality, 'f', -1, 64))
+	}
+	if query.Page > 0 {
+		values.Set("page", strconv.Itoa(query.Page))
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+	if query.FetchConnectCounts {
+		values.Set("fetchConnectCounts", "true")
+	}
+	return values
 }
 
 // ProposalDTO describes service proposal
 type ProposalDTO struct {
 	ID                int                  `json:"id"`
 	ProviderID        string               `json:"providerId"`
+	ServiceType       string               `json:"serviceType"`
 	ServiceDefinition ServiceDefinitionDTO `json:"serviceDefinition"`
+	PaymentMethod     *PaymentMethodDTO    `json:"paymentMethod"`
+	LocalScore        *LocalScoreDTO       `json:"localScore"`
+	Favorite          bool                 `json:"favorite"`
+}
+
+// LocalScoreDTO describes provider score based on local connection history
+type LocalScoreDTO struct {
+	Value          float64 `json:"value"`
+	Attempts       int     `json:"attempts"`
+	Failures       int     `json:"failures"`
+	Drops          int     `json:"drops"`
+	Sessions       int     `json:"sessions"`
+	AvgConnectTime float64 `json:"avgConnectTime"`
+	AvgThroughput  uint64  `json:"avgThroughput"`
+}
+
+// PaymentMethodDTO describes payment method of proposal
+type PaymentMethodDTO struct {
+	Type  string   `json:"type"`
+	Price MoneyDTO `json:"price"`
+}
+
+// MoneyDTO describes amount of money in given currency
+type MoneyDTO struct {
+	Amount   uint64 `json:"amount"`
+	Currency string `json:"currency"`
 }
 
 func (p ProposalDTO) String() string {
@@ -56,7 +155,20 @@ type ServiceDefinitionDTO struct {
 
 // LocationDTO describes location
 type LocationDTO struct {
+	ASN     string `json:"asn"`
 	Country string `json:"country"`
+	City    string `json:"city"`
+}
+
+// ProviderPreferenceDTO describes provider added to favorite or blocked list
+type ProviderPreferenceDTO struct {
+	ProviderID string `json:"providerId"`
+	AddedAt    string `json:"addedAt"`
+}
+
+// ProviderPreferenceList holds providers of favorite or blocked list
+type ProviderPreferenceList struct {
+	Providers []ProviderPreferenceDTO `json:"providers"`
 }
 
 // IdentityDTO holds identity address
@@ -64,6 +176,11 @@ type IdentityDTO struct {
 	Address string `json:"id"`
 }
 
+// MessageSignatureDTO holds signature of a message in base64 format
+type MessageSignatureDTO struct {
+	Signature string `json:"signature"`
+}
+
 // IdentityList holds returned list of identities
 type IdentityList struct {
 	Identities []IdentityDTO `json:"identities"`
@@ -71,10 +188,19 @@ type IdentityList struct {
 
 // HealthcheckDTO holds returned healthcheck response
 type HealthcheckDTO struct {
-	Uptime    string       `json:"uptime"`
-	Process   int          `json:"process"`
-	Version   string       `json:"version"`
-	BuildInfo BuildInfoDTO `json:"buildInfo"`
+	Uptime    string            `json:"uptime"`
+	Process   int               `json:"process"`
+	Version   string            `json:"version"`
+	BuildInfo BuildInfoDTO      `json:"buildInfo"`
+	Brokers   []BrokerStatusDTO `json:"brokers"`
+}
+
+// BrokerStatusDTO holds connection state of message brokers
+type BrokerStatusDTO struct {
+	Topic           string   `json:"topic"`
+	Servers         []string `json:"servers"`
+	State           string   `json:"state"`
+	ConnectedServer string   `json:"connectedServer"`
 }
 
 // BuildInfoDTO holds info about build
@@ -86,9 +212,26 @@ type BuildInfoDTO struct {
 
 // RegistrationDataDTO holds input data required to register new myst identity on blockchain smart contract
 type RegistrationDataDTO struct {
-	Registered bool              `json:"registered"`
-	PublicKey  PublicKeyPartsDTO `json:"publicKey"`
-	Signature  SignatureDTO      `json:"signature"`
+	Registered bool                     `json:"registered"`
+	PublicKey  PublicKeyPartsDTO        `json:"publicKey"`
+	Signature  SignatureDTO             `json:"signature"`
+	Progress   *RegistrationProgressDTO `json:"progress"`
+}
+
+// IdentityBalanceDTO holds MYST balance of identity in payments contract
+type IdentityBalanceDTO struct {
+	Amount    uint64 `json:"amount"`
+	Currency  string `json:"currency"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// RegistrationProgressDTO holds progress of registration transaction submitted by node
+type RegistrationProgressDTO struct {
+	Status      string `json:"status"`
+	Payer       string `json:"payer"`
+	TxHash      string `json:"txHash"`
+	SubmittedAt string `json:"submittedAt"`
+	Error       string `json:"error"`
 }
 
 // PublicKeyPartsDTO holds public key parts in hex, split into 32 byte blocks
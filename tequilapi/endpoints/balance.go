@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/balance"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+// swagger:model IdentityBalanceDTO
+type identityBalanceDto struct {
+	// balance in smallest MYST units, 1 MYST equals 100000000 units
+	// example: 150000000
+	Amount uint64 `json:"amount"`
+
+	// example: MYST
+	Currency string `json:"currency"`
+
+	// time when balance was read from payments contract in RFC3339 format
+	// example: 2018-11-01T10:00:00Z
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// BalanceProvider provides balance of identity kept in payments contract
+type BalanceProvider interface {
+	Get(identity.Identity) (balance.Balance, error)
+}
+
+type balanceEndpoint struct {
+	balances BalanceProvider
+}
+
+// NewBalanceEndpoint creates and returns identity balance endpoint
+func NewBalanceEndpoint(balances BalanceProvider) *balanceEndpoint {
+	return &balanceEndpoint{
+		balances: balances,
+	}
+}
+
+// swagger:operation GET /identities/{id}/balance Identity identityBalance
+// ---
+// summary: Returns identity balance
+// description: Returns MYST balance of identity kept in payments contract
+// parameters:
+// - in: path
+//   name: id
+//   description: hex address of identity
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Identity balance
+//     schema:
+//       "$ref": "#/definitions/IdentityBalanceDTO"
+//   400:
+//     description: Invalid identity address
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *balanceEndpoint) Balance(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	address := params.ByName("id")
+	if !common.IsHexAddress(address) {
+		utils.SendErrorMessage(resp, "Invalid identity address", http.StatusBadRequest)
+		return
+	}
+
+	identityBalance, err := endpoint.balances.Get(identity.FromAddress(address))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(identityBalanceDto{
+		Amount:    identityBalance.Amount.Amount,
+		Currency:  string(identityBalance.Amount.Currency),
+		UpdatedAt: identityBalance.UpdatedAt.UTC().Format(time.RFC3339),
+	}, resp)
+}
+
+// AddRoutesForBalance attaches identity balance endpoint to router
+func AddRoutesForBalance(router *httprouter.Router, balances BalanceProvider) {
+	endpoint := NewBalanceEndpoint(balances)
+	router.GET("/identities/:id/balance", endpoint.Balance)
+}
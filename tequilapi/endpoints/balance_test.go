@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/balance"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeBalanceProvider struct {
+	balance      balance.Balance
+	err          error
+	lastIdentity identity.Identity
+}
+
+func (provider *fakeBalanceProvider) Get(id identity.Identity) (balance.Balance, error) {
+	provider.lastIdentity = id
+	return provider.balance, provider.err
+}
+
+func TestBalanceEndpointReturnsBalance(t *testing.T) {
+	provider := &fakeBalanceProvider{
+		balance: balance.Balance{
+			Amount:    money.Money{Amount: 150000000, Currency: money.CURRENCY_MYST},
+			UpdatedAt: time.Date(2018, 11, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	router := httprouter.New()
+	AddRoutesForBalance(router, provider)
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/0x000000000000000000000000000000000000000A/balance", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"amount": 150000000,
+			"currency": "MYST",
+			"updatedAt": "2018-11-01T10:00:00Z"
+		}`,
+		resp.Body.String(),
+	)
+	assert.Equal(t, identity.FromAddress("0x000000000000000000000000000000000000000a"), provider.lastIdentity)
+}
+
+func TestBalanceEndpointRejectsInvalidAddress(t *testing.T) {
+	provider := &fakeBalanceProvider{}
+	router := httprouter.New()
+	AddRoutesForBalance(router, provider)
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/not-an-address/balance", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.JSONEq(t, `{"message": "Invalid identity address"}`, resp.Body.String())
+}
+
+func TestBalanceEndpointReturnsProviderError(t *testing.T) {
+	provider := &fakeBalanceProvider{err: errors.New("connection refused")}
+	router := httprouter.New()
+	AddRoutesForBalance(router, provider)
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/0x000000000000000000000000000000000000000A/balance", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"message": "connection refused"}`, resp.Body.String())
+}
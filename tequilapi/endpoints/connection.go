@@ -26,9 +26,12 @@ import (
 	log "github.com/cihub/seelog"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/consumer/leak"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 	"github.com/mysteriumnetwork/node/tequilapi/validation"
 )
@@ -53,8 +56,8 @@ type connectionRequest struct {
 	// example: 0x0000000000000000000000000000000000000001
 	ConsumerID string `json:"consumerId"`
 
-	// provider identity
-	// required: true
+	// provider identity, required unless criteria are given
+	// required: false
 	// example: 0x0000000000000000000000000000000000000002
 	ProviderID string `json:"providerId"`
 
@@ -64,9 +67,37 @@ type connectionRequest struct {
 	// example: openvpn
 	ServiceType string `json:"serviceType"`
 
+	// proposal ID, when provider offers several proposals of the service. First found proposal is used when not set
+	// required: false
+	// example: 1560000000
+	ProposalID int `json:"proposalId,omitempty"`
+
 	// connect options
 	// required: false
 	ConnectOptions ConnectOptions `json:"connectOptions,omitempty"`
+
+	// criteria of provider selection, used when providerId is not given
+	// required: false
+	Criteria *connectionCriteria `json:"criteria,omitempty"`
+}
+
+// swagger:model ConnectionCriteriaDTO
+type connectionCriteria struct {
+	// example: DE
+	Country string `json:"country,omitempty"`
+
+	// example: Berlin
+	City string `json:"city,omitempty"`
+
+	// example: AS3320
+	ASN string `json:"asn,omitempty"`
+
+	// example: PER_TIME
+	PaymentMethod string `json:"paymentMethod,omitempty"`
+
+	// maximum price amount
+	// example: 100000
+	MaxPrice *uint64 `json:"maxPrice,omitempty"`
 }
 
 // swagger:model ConnectionStatusDTO
@@ -79,6 +110,50 @@ type statusResponse struct {
 
 	// example: {"id":1,"providerId":"0x71ccbdee7f6afe85a5bc7106323518518cd23b94","serviceType":"openvpn","serviceDefinition":{"locationOriginate":{"asn":"","country":"CA"}}}
 	Proposal *proposalRes `json:"proposal,omitempty"`
+
+	// result of the check whether traffic exits via provider, missing until the check finishes
+	LeakCheck *leakCheckResponse `json:"leakCheck,omitempty"`
+}
+
+// swagger:model LeakCheckDTO
+type leakCheckResponse struct {
+	// example: 2019-06-06T11:04:43Z
+	CheckedAt string `json:"checkedAt"`
+
+	// public IP without connection
+	// example: 1.1.1.1
+	OriginalIP string `json:"originalIp"`
+
+	// public IP through connection
+	// example: 2.2.2.2
+	ExitIP string `json:"exitIp"`
+
+	// example: DE
+	ExitCountry string `json:"exitCountry"`
+
+	// country advertised by provider
+	// example: DE
+	ExpectedCountry string `json:"expectedCountry"`
+
+	// traffic exits with the same IP as without connection
+	// example: false
+	IPLeak bool `json:"ipLeak"`
+
+	// traffic exits in a different country than advertised by provider
+	// example: false
+	CountryMismatch bool `json:"countryMismatch"`
+
+	// DNS resolvers were checked
+	// example: true
+	DNSChecked bool `json:"dnsChecked"`
+
+	// DNS queries are resolved by the same resolvers as without connection
+	// example: false
+	DNSLeak bool `json:"dnsLeak"`
+
+	// set when exit location could not be detected
+	// example: failed to detect exit location
+	Error string `json:"error,omitempty"`
 }
 
 // swagger:model IPDTO
@@ -107,6 +182,11 @@ type SessionStatisticsTracker interface {
 	GetSessionDuration() time.Duration
 }
 
+// LeakCheckResults provides results of the connection leak checks
+type LeakCheckResults interface {
+	Result(sessionID session.ID) (leak.Result, bool)
+}
+
 // ConnectionEndpoint struct represents /connection resource and it's subresources
 type ConnectionEndpoint struct {
 	manager           connection.Manager
@@ -114,17 +194,28 @@ type ConnectionEndpoint struct {
 	statisticsTracker SessionStatisticsTracker
 	//TODO connection should use concrete proposal from connection params and avoid going to marketplace
 	proposalProvider ProposalProvider
+	providerFilter   ProviderFilter
+	leakChecks       LeakCheckResults
 }
 
 const connectionLogPrefix = "[Connection] "
 
-// NewConnectionEndpoint creates and returns connection endpoint
-func NewConnectionEndpoint(manager connection.Manager, ipResolver ip.Resolver, statsKeeper SessionStatisticsTracker, proposalProvider ProposalProvider) *ConnectionEndpoint {
+// NewConnectionEndpoint creates and returns connection endpoint, providerFilter and leakChecks are optional
+func NewConnectionEndpoint(
+	manager connection.Manager,
+	ipResolver ip.Resolver,
+	statsKeeper SessionStatisticsTracker,
+	proposalProvider ProposalProvider,
+	providerFilter ProviderFilter,
+	leakChecks LeakCheckResults,
+) *ConnectionEndpoint {
 	return &ConnectionEndpoint{
 		manager:           manager,
 		ipResolver:        ipResolver,
 		statisticsTracker: statsKeeper,
 		proposalProvider:  proposalProvider,
+		providerFilter:    providerFilter,
+		leakChecks:        leakChecks,
 	}
 }
 
@@ -132,7 +223,7 @@ func NewConnectionEndpoint(manager connection.Manager, ipResolver ip.Resolver, s
 // swagger:operation GET /connection Connection connectionStatus
 // ---
 // summary: Returns connection status
-// description: Returns status of current connection
+// description: Returns status of current connection. Result of the leak check is included once it finishes after connecting
 // responses:
 //   200:
 //     description: Status
@@ -143,7 +234,13 @@ func NewConnectionEndpoint(manager connection.Manager, ipResolver ip.Resolver, s
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
 func (ce *ConnectionEndpoint) Status(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-	statusResponse := toStatusResponse(ce.manager.Status())
+	status := ce.manager.Status()
+	statusResponse := toStatusResponse(status)
+	if ce.leakChecks != nil && status.State == connection.Connected {
+		if result, ok := ce.leakChecks.Result(status.SessionID); ok {
+			statusResponse.LeakCheck = toLeakCheckResponse(result)
+		}
+	}
 	utils.WriteAsJSON(statusResponse, resp)
 }
 
@@ -151,11 +248,12 @@ func (ce *ConnectionEndpoint) Status(resp http.ResponseWriter, _ *http.Request,
 // swagger:operation PUT /connection Connection createConnection
 // ---
 // summary: Starts new connection
-// description: Consumer opens connection to provider
+// description: Consumer opens connection to provider. When providerId is not given, provider is selected by criteria:
+//   proposals of favorite providers are preferred and proposals of blocked providers are never selected.
 // parameters:
 //   - in: body
 //     name: body
-//     description: Parameters in body (consumerId, providerId, serviceType) required for creating new connection
+//     description: Parameters in body (consumerId, providerId or criteria, serviceType) required for creating new connection
 //     schema:
 //       $ref: "#/definitions/ConnectionRequestDTO"
 // responses:
@@ -191,6 +289,16 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 	}
 
 	errorMap := validateConnectionRequest(cr)
+	if cr.ProviderID != "" && ce.providerFilter != nil {
+		blocked, err := ce.providerFilter.IsBlocked(cr.ProviderID)
+		if err != nil {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+		if blocked {
+			errorMap.ForField("providerId").AddError("blocked", "Provider is blocked")
+		}
+	}
 	if errorMap.HasErrors() {
 		utils.SendValidationErrorMessage(resp, errorMap)
 		return
@@ -201,13 +309,24 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
 	}
-	if len(proposals) == 0 {
+
+	var proposal market.ServiceProposal
+	var found bool
+	if cr.ProviderID == "" {
+		proposal, found, err = ce.selectProposalByCriteria(proposals, *cr.Criteria)
+		if err != nil {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			utils.SendError(resp, errors.New("no service proposals match the criteria"), http.StatusBadRequest)
+			return
+		}
+	} else if proposal, found = selectProposal(proposals, cr.ProposalID); !found {
 		utils.SendError(resp, errors.New("provider has no service proposals"), http.StatusBadRequest)
 		return
 	}
 
-	proposal := proposals[0]
-
 	connectOptions := getConnectOptions(cr)
 	err = ce.manager.Connect(identity.FromAddress(cr.ConsumerID), proposal, connectOptions)
 
@@ -319,8 +438,9 @@ func (ce *ConnectionEndpoint) GetStatistics(writer http.ResponseWriter, request
 
 // AddRoutesForConnection adds connections routes to given router
 func AddRoutesForConnection(router *httprouter.Router, manager connection.Manager, ipResolver ip.Resolver,
-	statsKeeper SessionStatisticsTracker, proposalProvider ProposalProvider) {
-	connectionEndpoint := NewConnectionEndpoint(manager, ipResolver, statsKeeper, proposalProvider)
+	statsKeeper SessionStatisticsTracker, proposalProvider ProposalProvider, providerFilter ProviderFilter,
+	leakChecks LeakCheckResults) {
+	connectionEndpoint := NewConnectionEndpoint(manager, ipResolver, statsKeeper, proposalProvider, providerFilter, leakChecks)
 	router.GET("/connection", connectionEndpoint.Status)
 	router.PUT("/connection", connectionEndpoint.Create)
 	router.DELETE("/connection", connectionEndpoint.Kill)
@@ -341,6 +461,58 @@ func toConnectionRequest(req *http.Request) (*connectionRequest, error) {
 	return &connectionRequest, nil
 }
 
+// selectProposal picks proposal with given ID or the first one when ID is not given
+func selectProposal(proposals []market.ServiceProposal, proposalID int) (market.ServiceProposal, bool) {
+	for _, proposal := range proposals {
+		if proposalID == 0 || proposal.ID == proposalID {
+			return proposal, true
+		}
+	}
+	return market.ServiceProposal{}, false
+}
+
+// selectProposalByCriteria picks the first proposal matching given criteria.
+// Proposals of favorite providers are preferred, proposals of blocked providers are skipped.
+func (ce *ConnectionEndpoint) selectProposalByCriteria(proposals []market.ServiceProposal, criteria connectionCriteria) (market.ServiceProposal, bool, error) {
+	query := proposalsQuery{
+		Country:       criteria.Country,
+		City:          criteria.City,
+		ASN:           criteria.ASN,
+		PaymentMethod: criteria.PaymentMethod,
+		MaxPrice:      criteria.MaxPrice,
+	}
+
+	var selected market.ServiceProposal
+	var found bool
+	for _, proposal := range proposals {
+		if !query.matches(proposal, nil) {
+			continue
+		}
+		if ce.providerFilter == nil {
+			return proposal, true, nil
+		}
+
+		blocked, err := ce.providerFilter.IsBlocked(proposal.ProviderID)
+		if err != nil {
+			return selected, false, err
+		}
+		if blocked {
+			continue
+		}
+		favorite, err := ce.providerFilter.IsFavorite(proposal.ProviderID)
+		if err != nil {
+			return selected, false, err
+		}
+		if favorite {
+			return proposal, true, nil
+		}
+		if !found {
+			selected, found = proposal, true
+		}
+	}
+	return selected, found, nil
+}
+
 func getConnectOptions(cr *connectionRequest) connection.ConnectParams {
 	return connection.ConnectParams{DisableKillSwitch: cr.ConnectOptions.DisableKillSwitch}
 }
@@ -350,7 +522,7 @@ func validateConnectionRequest(cr *connectionRequest) *validation.FieldErrorMap
 	if len(cr.ConsumerID) == 0 {
 		errors.ForField("consumerId").AddError("required", "Field is required")
 	}
-	if len(cr.ProviderID) == 0 {
+	if len(cr.ProviderID) == 0 && cr.Criteria == nil {
 		errors.ForField("providerId").AddError("required", "Field is required")
 	}
 	return errors
@@ -368,3 +540,18 @@ func toStatusResponse(status connection.Status) statusResponse {
 	}
 	return response
 }
+
+func toLeakCheckResponse(result leak.Result) *leakCheckResponse {
+	return &leakCheckResponse{
+		CheckedAt:       result.CheckedAt.UTC().Format(time.RFC3339),
+		OriginalIP:      result.OriginalIP,
+		ExitIP:          result.ExitIP,
+		ExitCountry:     result.ExitCountry,
+		ExpectedCountry: result.ExpectedCountry,
+		IPLeak:          result.IPLeak,
+		CountryMismatch: result.CountryMismatch,
+		DNSChecked:      result.DNSChecked,
+		DNSLeak:         result.DNSLeak,
+		Error:           result.Error,
+	}
+}
@@ -27,10 +27,12 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/consumer"
+	"github.com/mysteriumnetwork/node/consumer/leak"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,12 +44,14 @@ type fakeManager struct {
 	requestedConsumerID  identity.Identity
 	requestedProvider    identity.Identity
 	requestedServiceType string
+	requestedProposalID  int
 }
 
 func (fm *fakeManager) Connect(consumerID identity.Identity, proposal market.ServiceProposal, options connection.ConnectParams) error {
 	fm.requestedConsumerID = consumerID
 	fm.requestedProvider = identity.FromAddress(proposal.ProviderID)
 	fm.requestedServiceType = proposal.ServiceType
+	fm.requestedProposalID = proposal.ID
 	return fm.onConnectReturn
 }
 
@@ -100,7 +104,7 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 	ipResolver := ip.NewResolverFake("123.123.123.123")
 
 	mockedProposalProvider := getMockProposalProviderWithSpecifiedProposal("node1", "noop")
-	AddRoutesForConnection(router, &fakeManager, ipResolver, statsKeeper, mockedProposalProvider)
+	AddRoutesForConnection(router, &fakeManager, ipResolver, statsKeeper, mockedProposalProvider, nil, nil)
 
 	tests := []struct {
 		method         string
@@ -155,7 +159,7 @@ func TestDisconnectingState(t *testing.T) {
 		SessionID: "",
 	}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -170,6 +174,66 @@ func TestDisconnectingState(t *testing.T) {
 		resp.Body.String())
 }
 
+type fakeLeakChecks struct {
+	result leak.Result
+}
+
+func (checks *fakeLeakChecks) Result(sessionID session.ID) (leak.Result, bool) {
+	return checks.result, checks.result.SessionID == sessionID
+}
+
+func TestConnectedStateIncludesLeakCheckResult(t *testing.T) {
+	var fakeManager = fakeManager{}
+	fakeManager.onStatusReturn = connection.Status{
+		State:     connection.Connected,
+		SessionID: "My-super-session",
+	}
+	leakChecks := &fakeLeakChecks{
+		result: leak.Result{
+			SessionID:       "My-super-session",
+			CheckedAt:       time.Date(2019, 6, 6, 11, 4, 43, 0, time.UTC),
+			OriginalIP:      "1.1.1.1",
+			ExitIP:          "1.1.1.1",
+			ExitCountry:     "LT",
+			ExpectedCountry: "DE",
+			IPLeak:          true,
+			CountryMismatch: true,
+		},
+	}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil, leakChecks)
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Status(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"status": "Connected",
+			"sessionId": "My-super-session",
+			"leakCheck": {
+				"checkedAt": "2019-06-06T11:04:43Z",
+				"originalIp": "1.1.1.1",
+				"exitIp": "1.1.1.1",
+				"exitCountry": "LT",
+				"expectedCountry": "DE",
+				"ipLeak": true,
+				"countryMismatch": true,
+				"dnsChecked": false,
+				"dnsLeak": false
+			}
+		}`,
+		resp.Body.String(),
+	)
+
+	leakChecks.result.SessionID = "previous-session"
+	resp = httptest.NewRecorder()
+	connEndpoint.Status(resp, req, httprouter.Params{})
+	assert.JSONEq(t, `{"status": "Connected", "sessionId": "My-super-session"}`, resp.Body.String())
+}
+
 func TestNotConnectedStateIsReturnedWhenNoConnection(t *testing.T) {
 	var fakeManager = fakeManager{}
 	fakeManager.onStatusReturn = connection.Status{
@@ -177,7 +241,7 @@ func TestNotConnectedStateIsReturnedWhenNoConnection(t *testing.T) {
 		SessionID: "",
 	}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -199,7 +263,7 @@ func TestStateConnectingIsReturnedWhenIsConnectionInProgress(t *testing.T) {
 		State: connection.Connecting,
 	}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -222,7 +286,7 @@ func TestConnectedStateAndSessionIdIsReturnedWhenIsConnected(t *testing.T) {
 		SessionID: "My-super-session",
 	}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -242,7 +306,7 @@ func TestConnectedStateAndSessionIdIsReturnedWhenIsConnected(t *testing.T) {
 func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	fakeManager := fakeManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil, nil)
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("a"))
 	resp := httptest.NewRecorder()
 
@@ -261,7 +325,7 @@ func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 func TestPutReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
 	fakeManager := fakeManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil, nil)
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("{}"))
 	resp := httptest.NewRecorder()
 
@@ -284,7 +348,7 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	fakeManager := fakeManager{}
 
 	proposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, proposalProvider)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, proposalProvider, nil, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -304,11 +368,59 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	assert.Equal(t, "openvpn", fakeManager.requestedServiceType)
 }
 
+func TestPutWithProposalIDSelectsProposal(t *testing.T) {
+	fakeManager := fakeManager{}
+
+	proposalProvider := &mockProposalProvider{
+		proposals: []market.ServiceProposal{
+			{ID: 1, ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}, ProviderID: "required-node"},
+			{ID: 2, ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}, ProviderID: "required-node"},
+		},
+	}
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, proposalProvider, nil, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"proposalId": 2
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, 2, fakeManager.requestedProposalID)
+}
+
+func TestPutWithUnknownProposalIDReturnsError(t *testing.T) {
+	fakeManager := fakeManager{}
+
+	proposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, proposalProvider, nil, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node",
+				"proposalId": 5
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
 func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 	fakeManager := fakeManager{}
 
 	mystAPI := getMockProposalProviderWithSpecifiedProposal("required-node", "noop")
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, mystAPI)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, mystAPI, nil, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -332,7 +444,7 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 func TestDeleteCallsDisconnect(t *testing.T) {
 	fakeManager := fakeManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil, &mockProposalProvider{}, nil, nil)
 	req := httptest.NewRequest(http.MethodDelete, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -346,7 +458,7 @@ func TestDeleteCallsDisconnect(t *testing.T) {
 func TestGetIPEndpointSucceeds(t *testing.T) {
 	manager := fakeManager{}
 	ipResolver := ip.NewResolverFake("123.123.123.123")
-	connEndpoint := NewConnectionEndpoint(&manager, ipResolver, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&manager, ipResolver, nil, &mockProposalProvider{}, nil, nil)
 	resp := httptest.NewRecorder()
 
 	connEndpoint.GetIP(resp, nil, nil)
@@ -364,7 +476,7 @@ func TestGetIPEndpointSucceeds(t *testing.T) {
 func TestGetIPEndpointReturnsErrorWhenIPDetectionFails(t *testing.T) {
 	manager := fakeManager{}
 	ipResolver := ip.NewResolverFakeFailing(errors.New("fake error"))
-	connEndpoint := NewConnectionEndpoint(&manager, ipResolver, nil, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&manager, ipResolver, nil, &mockProposalProvider{}, nil, nil)
 	resp := httptest.NewRecorder()
 
 	connEndpoint.GetIP(resp, nil, nil)
@@ -386,7 +498,7 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	}
 
 	manager := fakeManager{}
-	connEndpoint := NewConnectionEndpoint(&manager, nil, statsKeeper, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&manager, nil, statsKeeper, &mockProposalProvider{}, nil, nil)
 
 	resp := httptest.NewRecorder()
 	connEndpoint.GetStatistics(resp, nil, nil)
@@ -407,7 +519,7 @@ func TestGetStatisticsEndpointReturnsStatisticsWhenSessionIsNotStarted(t *testin
 	}
 
 	manager := fakeManager{}
-	connEndpoint := NewConnectionEndpoint(&manager, nil, statsKeeper, &mockProposalProvider{})
+	connEndpoint := NewConnectionEndpoint(&manager, nil, statsKeeper, &mockProposalProvider{}, nil, nil)
 
 	resp := httptest.NewRecorder()
 	connEndpoint.GetStatistics(resp, nil, nil)
@@ -427,7 +539,7 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	manager.onConnectReturn = connection.ErrAlreadyExists
 
 	mystAPI := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mystAPI)
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mystAPI, nil, nil)
 
 	req := httptest.NewRequest(
 		http.MethodPut,
@@ -455,7 +567,7 @@ func TestDisconnectReturnsConflictStatusIfConnectionDoesNotExist(t *testing.T) {
 	manager := fakeManager{}
 	manager.onDisconnectReturn = connection.ErrNoConnection
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, &mockProposalProvider{})
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, &mockProposalProvider{}, nil, nil)
 
 	req := httptest.NewRequest(
 		http.MethodDelete,
@@ -481,7 +593,7 @@ func TestConnectReturnsConnectCancelledStatusWhenErrConnectionCancelledIsEncount
 	manager.onConnectReturn = connection.ErrConnectionCancelled
 
 	mockProposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider)
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, mockProposalProvider, nil, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -508,7 +620,7 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 	manager := fakeManager{}
 	manager.onConnectReturn = connection.ErrConnectionCancelled
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, &mockProposalProvider{proposals: make([]market.ServiceProposal, 0)})
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, &mockProposalProvider{proposals: make([]market.ServiceProposal, 0)}, nil, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -530,3 +642,112 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 		resp.Body.String(),
 	)
 }
+
+func TestConnectReturnsValidationErrorForBlockedProvider(t *testing.T) {
+	manager := fakeManager{}
+	proposalProvider := getMockProposalProviderWithSpecifiedProposal("required-node", "openvpn")
+	filter := &providerFilterFake{blocked: map[string]bool{"required-node": true}}
+
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, proposalProvider, filter, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"providerId" : "required-node"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message" : "validation_error",
+			"errors" : {
+				"providerId" : [ {"code" : "blocked" , "message" : "Provider is blocked" } ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestConnectByCriteriaSkipsBlockedAndPrefersFavoriteProviders(t *testing.T) {
+	manager := fakeManager{}
+	proposalProvider := &mockProposalProvider{
+		proposals: []market.ServiceProposal{
+			{ID: 1, ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}, ProviderID: "blocked-node"},
+			{ID: 2, ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}, ProviderID: "other-node"},
+			{ID: 3, ServiceType: "openvpn", ServiceDefinition: TestServiceDefinition{}, ProviderID: "favorite-node"},
+		},
+	}
+	filter := &providerFilterFake{
+		blocked:  map[string]bool{"blocked-node": true},
+		favorite: map[string]bool{"favorite-node": true},
+	}
+
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, proposalProvider, filter, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"criteria" : { "country" : "Lithuania" }
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "", proposalProvider.recordedProviderId)
+	assert.Equal(t, identity.FromAddress("favorite-node"), manager.requestedProvider)
+
+	filter.favorite = nil
+	manager = fakeManager{}
+	connectionEndpoint = NewConnectionEndpoint(&manager, nil, nil, proposalProvider, filter, nil)
+	req = httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"criteria" : { "country" : "Lithuania" }
+			}`))
+	resp = httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, identity.FromAddress("other-node"), manager.requestedProvider)
+}
+
+func TestConnectByCriteriaReturnsErrorWhenOnlyBlockedProvidersMatch(t *testing.T) {
+	manager := fakeManager{}
+	proposalProvider := getMockProposalProviderWithSpecifiedProposal("blocked-node", "openvpn")
+	filter := &providerFilterFake{blocked: map[string]bool{"blocked-node": true}}
+
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, nil, proposalProvider, filter, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumerId" : "my-identity",
+				"criteria" : {}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message" : "no service proposals match the criteria"
+		}`,
+		resp.Body.String(),
+	)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+// DialogStatsProvider returns counters of rejected dialog requests grouped by service type
+type DialogStatsProvider func() map[string]communication.DialogWaiterStats
+
+// swagger:model DialogStatsListDTO
+type dialogStatsList struct {
+	Services []dialogStats `json:"services"`
+}
+
+// swagger:model DialogStatsDTO
+type dialogStats struct {
+	// example: openvpn
+	ServiceType string `json:"serviceType"`
+
+	// example: 3
+	RejectedInvalidIdentity uint64 `json:"rejectedInvalidIdentity"`
+
+	// example: 12
+	RejectedRateLimited uint64 `json:"rejectedRateLimited"`
+
+	// example: 0
+	RejectedCapacity uint64 `json:"rejectedCapacity"`
+
+	// example: 1
+	RejectedIncompatible uint64 `json:"rejectedIncompatible"`
+
+	// example: 0
+	RejectedInternalError uint64 `json:"rejectedInternalError"`
+}
+
+type dialogsEndpoint struct {
+	statsProvider DialogStatsProvider
+}
+
+// NewDialogsEndpoint creates and returns dialog statistics endpoint
+func NewDialogsEndpoint(statsProvider DialogStatsProvider) *dialogsEndpoint {
+	return &dialogsEndpoint{statsProvider: statsProvider}
+}
+
+// swagger:operation GET /dialogs Dialogs dialogStats
+// ---
+// summary: Returns statistics of incoming dialog requests
+// description: Returns amount of dialog requests rejected by each running service, grouped by rejection reason
+// responses:
+//   200:
+//     description: Dialog statistics of running services
+//     schema:
+//       "$ref": "#/definitions/DialogStatsListDTO"
+func (endpoint *dialogsEndpoint) Stats(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	result := dialogStatsList{Services: []dialogStats{}}
+	for serviceType, stats := range endpoint.statsProvider() {
+		result.Services = append(result.Services, dialogStats{
+			ServiceType:             serviceType,
+			RejectedInvalidIdentity: stats.RejectedInvalidIdentity,
+			RejectedRateLimited:     stats.RejectedRateLimited,
+			RejectedCapacity:        stats.RejectedCapacity,
+			RejectedIncompatible:    stats.RejectedIncompatible,
+			RejectedInternalError:   stats.RejectedInternalError,
+		})
+	}
+	sort.Slice(result.Services, func(i, j int) bool {
+		return result.Services[i].ServiceType < result.Services[j].ServiceType
+	})
+	utils.WriteAsJSON(result, resp)
+}
+
+// AddRoutesForDialogs attaches dialog statistics endpoint to router
+func AddRoutesForDialogs(router *httprouter.Router, statsProvider DialogStatsProvider) {
+	endpoint := NewDialogsEndpoint(statsProvider)
+	router.GET("/dialogs", endpoint.Stats)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/communication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialogStatsReturnsStatsOfAllServices(t *testing.T) {
+	provider := func() map[string]communication.DialogWaiterStats {
+		return map[string]communication.DialogWaiterStats{
+			"openvpn": {RejectedRateLimited: 12, RejectedCapacity: 2},
+			"noop":    {RejectedInvalidIdentity: 1},
+		}
+	}
+
+	router := httprouter.New()
+	AddRoutesForDialogs(router, provider)
+
+	req := httptest.NewRequest(http.MethodGet, "/dialogs", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"services": [
+				{
+					"serviceType": "noop",
+					"rejectedInvalidIdentity": 1,
+					"rejectedRateLimited": 0,
+					"rejectedCapacity": 0,
+					"rejectedIncompatible": 0,
+					"rejectedInternalError": 0
+				},
+				{
+					"serviceType": "openvpn",
+					"rejectedInvalidIdentity": 0,
+					"rejectedRateLimited": 12,
+					"rejectedCapacity": 2,
+					"rejectedIncompatible": 0,
+					"rejectedInternalError": 0
+				}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestDialogStatsReturnsEmptyListWithoutServices(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForDialogs(router, func() map[string]communication.DialogWaiterStats { return nil })
+
+	req := httptest.NewRequest(http.MethodGet, "/dialogs", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.JSONEq(t, `{"services": []}`, resp.Body.String())
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/market/proposals/registry"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+// DiscoveryStatusProvider returns discovery progress of published proposals grouped by service type
+type DiscoveryStatusProvider func() map[string][]registry.State
+
+// swagger:model DiscoveryStatusListDTO
+type discoveryStatusList struct {
+	Services []discoveryStatus `json:"services"`
+}
+
+// swagger:model DiscoveryStatusDTO
+type discoveryStatus struct {
+	// example: openvpn
+	ServiceType string `json:"serviceType"`
+
+	// example: 1
+	ProposalID int `json:"proposalId"`
+
+	// example: PingProposal
+	Status string `json:"status"`
+
+	// example: 2019-06-06T11:04:43Z
+	LastPingAt string `json:"lastPingAt,omitempty"`
+
+	// example: server response invalid: 500 Internal Server Error
+	LastError string `json:"lastError,omitempty"`
+
+	// example: 2019-06-06T11:03:43Z
+	LastErrorAt string `json:"lastErrorAt,omitempty"`
+}
+
+type discoveryEndpoint struct {
+	statusProvider DiscoveryStatusProvider
+}
+
+// NewDiscoveryEndpoint creates and returns discovery status endpoint
+func NewDiscoveryEndpoint(statusProvider DiscoveryStatusProvider) *discoveryEndpoint {
+	return &discoveryEndpoint{statusProvider: statusProvider}
+}
+
+// swagger:operation GET /discovery Discovery discoveryStatus
+// ---
+// summary: Returns discovery status of published proposals
+// description: Returns current registration stage, last successful ping and last error of each published proposal
+// responses:
+//   200:
+//     description: Discovery status of published proposals
+//     schema:
+//       "$ref": "#/definitions/DiscoveryStatusListDTO"
+func (endpoint *discoveryEndpoint) Status(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	result := discoveryStatusList{Services: []discoveryStatus{}}
+	for serviceType, states := range endpoint.statusProvider() {
+		for _, state := range states {
+			result.Services = append(result.Services, mapDiscoveryState(serviceType, state))
+		}
+	}
+	sort.Slice(result.Services, func(i, j int) bool {
+		if result.Services[i].ServiceType != result.Services[j].ServiceType {
+			return result.Services[i].ServiceType < result.Services[j].ServiceType
+		}
+		return result.Services[i].ProposalID < result.Services[j].ProposalID
+	})
+	utils.WriteAsJSON(result, resp)
+}
+
+func mapDiscoveryState(serviceType string, state registry.State) discoveryStatus {
+	status := discoveryStatus{
+		ServiceType: serviceType,
+		ProposalID:  state.ProposalID,
+		Status:      state.Status.String(),
+	}
+	if !state.LastPingAt.IsZero() {
+		status.LastPingAt = state.LastPingAt.UTC().Format(time.RFC3339)
+	}
+	if state.LastError != nil {
+		status.LastError = state.LastError.Error()
+		status.LastErrorAt = state.LastErrorAt.UTC().Format(time.RFC3339)
+	}
+	return status
+}
+
+// AddRoutesForDiscovery attaches discovery status endpoint to router
+func AddRoutesForDiscovery(router *httprouter.Router, statusProvider DiscoveryStatusProvider) {
+	endpoint := NewDiscoveryEndpoint(statusProvider)
+	router.GET("/discovery", endpoint.Status)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/market/proposals/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveryStatusReturnsStatesOfAllServices(t *testing.T) {
+	pingedAt := time.Date(2019, 6, 6, 11, 4, 43, 0, time.UTC)
+	failedAt := time.Date(2019, 6, 6, 11, 3, 43, 0, time.UTC)
+	provider := func() map[string][]registry.State {
+		return map[string][]registry.State{
+			"openvpn": {
+				{Status: registry.PingProposal, ProposalID: 2, LastPingAt: pingedAt},
+				{Status: registry.RegisterProposal, ProposalID: 1, LastError: errors.New("registry unavailable"), LastErrorAt: failedAt},
+			},
+			"noop": {
+				{Status: registry.WaitingForRegistration, ProposalID: 3},
+			},
+		}
+	}
+
+	router := httprouter.New()
+	AddRoutesForDiscovery(router, provider)
+
+	req := httptest.NewRequest(http.MethodGet, "/discovery", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"services": [
+				{"serviceType": "noop", "proposalId": 3, "status": "WaitingForRegistration"},
+				{
+					"serviceType": "openvpn",
+					"proposalId": 1,
+					"status": "RegisterProposal",
+					"lastError": "registry unavailable",
+					"lastErrorAt": "2019-06-06T11:03:43Z"
+				},
+				{
+					"serviceType": "openvpn",
+					"proposalId": 2,
+					"status": "PingProposal",
+					"lastPingAt": "2019-06-06T11:04:43Z"
+				}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestDiscoveryStatusReturnsEmptyListWithoutServices(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForDiscovery(router, func() map[string][]registry.State { return nil })
+
+	req := httptest.NewRequest(http.MethodGet, "/discovery", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.JSONEq(t, `{"services": []}`, resp.Body.String())
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/communication/nats/discovery"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
@@ -37,6 +38,28 @@ type healthCheckData struct {
 	// example: 0.0.6
 	Version   string    `json:"version"`
 	BuildInfo buildInfo `json:"buildInfo"`
+
+	Brokers []brokerStatus `json:"brokers,omitempty"`
+}
+
+// swagger:model BrokerStatusDTO
+type brokerStatus struct {
+	// example: 0x0000000000000000000000000000000000000001.openvpn
+	Topic string `json:"topic"`
+
+	// example: ["nats://broker1.mysterium.network:4222","nats://broker2.mysterium.network:4222"]
+	Servers []string `json:"servers"`
+
+	// example: Connected
+	State string `json:"state"`
+
+	// example: nats://broker1.mysterium.network:4222
+	ConnectedServer string `json:"connectedServer,omitempty"`
+}
+
+// BrokerStatusProvider provides connection states of message brokers used by node
+type BrokerStatusProvider interface {
+	Status() []discovery.BrokerStatus
 }
 
 // swagger:model BuildInfoDTO
@@ -55,18 +78,20 @@ type healthCheckEndpoint struct {
 	startTime       time.Time
 	currentTimeFunc func() time.Time
 	processNumber   int
+	brokerStatus    BrokerStatusProvider
 }
 
 /*
 HealthCheckEndpointFactory creates a structure with single HealthCheck method for healthcheck serving as http,
-currentTimeFunc is injected for easier testing
+currentTimeFunc is injected for easier testing, brokerStatus is optional
 */
-func HealthCheckEndpointFactory(currentTimeFunc func() time.Time, procID func() int) *healthCheckEndpoint {
+func HealthCheckEndpointFactory(currentTimeFunc func() time.Time, procID func() int, brokerStatus BrokerStatusProvider) *healthCheckEndpoint {
 	startTime := currentTimeFunc()
 	return &healthCheckEndpoint{
 		startTime,
 		currentTimeFunc,
 		procID(),
+		brokerStatus,
 	}
 }
 
@@ -94,5 +119,15 @@ func (hce *healthCheckEndpoint) HealthCheck(writer http.ResponseWriter, request
 			metadata.BuildNumber,
 		},
 	}
+	if hce.brokerStatus != nil {
+		for _, broker := range hce.brokerStatus.Status() {
+			status.Brokers = append(status.Brokers, brokerStatus{
+				Topic:           broker.Topic,
+				Servers:         broker.Servers,
+				State:           string(broker.State),
+				ConnectedServer: broker.ConnectedServer,
+			})
+		}
+	}
 	utils.WriteAsJSON(status, writer)
 }
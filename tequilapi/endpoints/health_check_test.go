@@ -18,11 +18,13 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/communication/nats/discovery"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/stretchr/testify/assert"
 )
@@ -42,6 +44,7 @@ func TestHealthCheckReturnsExpectedJSONObject(t *testing.T) {
 	handlerFunc := HealthCheckEndpointFactory(
 		newMockTimer([]time.Time{tick1, tick2}).Now,
 		func() int { return 1 },
+		nil,
 	).HealthCheck
 	handlerFunc(resp, req, httprouter.Params{})
 
@@ -60,6 +63,47 @@ func TestHealthCheckReturnsExpectedJSONObject(t *testing.T) {
 		resp.Body.String())
 }
 
+func TestHealthCheckReturnsBrokerStatus(t *testing.T) {
+	req := httptest.NewRequest("GET", "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	brokers := &mockBrokerStatusProvider{
+		statuses: []discovery.BrokerStatus{
+			{
+				Topic:           "provider1.openvpn",
+				Servers:         []string{"nats://broker1:4222", "nats://broker2:4222"},
+				State:           discovery.BrokerStateConnected,
+				ConnectedServer: "nats://broker2:4222",
+			},
+		},
+	}
+	handlerFunc := HealthCheckEndpointFactory(time.Now, func() int { return 1 }, brokers).HealthCheck
+	handlerFunc(resp, req, httprouter.Params{})
+
+	parsedResponse := healthCheckData{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsedResponse))
+	assert.Equal(
+		t,
+		[]brokerStatus{
+			{
+				Topic:           "provider1.openvpn",
+				Servers:         []string{"nats://broker1:4222", "nats://broker2:4222"},
+				State:           "Connected",
+				ConnectedServer: "nats://broker2:4222",
+			},
+		},
+		parsedResponse.Brokers,
+	)
+}
+
+type mockBrokerStatusProvider struct {
+	statuses []discovery.BrokerStatus
+}
+
+func (provider *mockBrokerStatusProvider) Status() []discovery.BrokerStatus {
+	return provider.statuses
+}
+
 type mockTimer struct {
 	values  []time.Time
 	current int
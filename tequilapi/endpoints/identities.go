@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"net/http"
+	"time"
 
 	"encoding/json"
 
@@ -48,12 +49,60 @@ type identityCreationDto struct {
 
 // swagger:model IdentityUnlockingDTO
 type identityUnlockingDto struct {
+	// required: true
+	Passphrase *string `json:"passphrase"`
+
+	// seconds after which identity is locked again, zero keeps it unlocked until it gets idle
+	// required: false
+	// example: 3600
+	Timeout int64 `json:"timeout"`
+}
+
+// swagger:model IdentityExportDTO
+type identityExportDto struct {
+	// current passphrase of identity
+	// required: true
+	Passphrase *string `json:"passphrase"`
+
+	// passphrase to encrypt exported keystore with, defaults to passphrase
+	// required: false
+	NewPassphrase *string `json:"newPassphrase"`
+}
+
+// swagger:model IdentityImportDTO
+type identityImportDto struct {
+	// exported keystore JSON, either as an object or as a string
+	// required: true
+	Keystore json.RawMessage `json:"keystore"`
+
+	// passphrase the keystore was exported with
+	// required: true
+	Passphrase *string `json:"passphrase"`
+
+	// passphrase to encrypt imported identity with, defaults to passphrase
+	// required: false
+	NewPassphrase *string `json:"newPassphrase"`
+}
+
+// swagger:model IdentityPassphraseChangeDTO
+type identityPassphraseChangeDto struct {
+	// required: true
+	OldPassphrase *string `json:"oldPassphrase"`
+
+	// required: true
+	NewPassphrase *string `json:"newPassphrase"`
+}
+
+// swagger:model IdentityDeletionDTO
+type identityDeletionDto struct {
+	// required: true
 	Passphrase *string `json:"passphrase"`
 }
 
 type identitiesAPI struct {
 	idm           identity.Manager
 	signerFactory identity.SignerFactory
+	cache         identity.IdentityCacheInterface
 }
 
 func idToDto(id identity.Identity) identityDto {
@@ -68,9 +117,17 @@ func mapIdentities(idArry []identity.Identity, f func(identity.Identity) identit
 	return
 }
 
-//NewIdentitiesEndpoint creates identities api controller used by tequilapi service
-func NewIdentitiesEndpoint(idm identity.Manager, signerFactory identity.SignerFactory) *identitiesAPI {
-	return &identitiesAPI{idm, signerFactory}
+//NewIdentitiesEndpoint creates identities api controller used by tequilapi service, cache is optional
+func NewIdentitiesEndpoint(
+	idm identity.Manager,
+	signerFactory identity.SignerFactory,
+	cache identity.IdentityCacheInterface,
+) *identitiesAPI {
+	return &identitiesAPI{
+		idm:           idm,
+		signerFactory: signerFactory,
+		cache:         cache,
+	}
 }
 
 // swagger:operation GET /identities Identity listIdentities
@@ -146,7 +203,7 @@ func (endpoint *identitiesAPI) Create(resp http.ResponseWriter, request *http.Re
 // swagger:operation PUT /identities/{id}/unlock Identity unlockIdentity
 // ---
 // summary: Unlocks identity
-// description: Uses passphrase to decrypt identity stored in keystore
+// description: Uses passphrase to decrypt identity stored in keystore. Identity is locked again after optional timeout
 // parameters:
 // - in: path
 //   name: id
@@ -187,7 +244,8 @@ func (endpoint *identitiesAPI) Unlock(resp http.ResponseWriter, request *http.Re
 		return
 	}
 
-	err = endpoint.idm.Unlock(id, *unlockReq.Passphrase)
+	timeout := time.Duration(unlockReq.Timeout) * time.Second
+	err = endpoint.idm.UnlockWithTimeout(id, *unlockReq.Passphrase, timeout)
 	if err != nil {
 		utils.SendError(resp, err, http.StatusForbidden)
 		return
@@ -195,6 +253,357 @@ func (endpoint *identitiesAPI) Unlock(resp http.ResponseWriter, request *http.Re
 	resp.WriteHeader(http.StatusAccepted)
 }
 
+// swagger:operation PUT /identities/{id}/lock Identity lockIdentity
+// ---
+// summary: Locks identity
+// description: Locks unlocked identity. Identity used by active connection or running service can not be locked
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Identity locked
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Identity is in use
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesAPI) Lock(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := params.ByName("id")
+	if !endpoint.idm.HasIdentity(id) {
+		utils.SendErrorMessage(resp, "Identity not found", http.StatusNotFound)
+		return
+	}
+
+	err := endpoint.idm.Lock(id)
+	if err == identity.ErrIdentityInUse {
+		utils.SendError(resp, err, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// swagger:operation POST /identities/{id}/export Identity exportIdentity
+// ---
+// summary: Exports identity
+// description: Returns encrypted keystore JSON of identity, re-encrypted with new passphrase
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Parameters in body (passphrase, newPassphrase) required for exporting identity
+//   schema:
+//     $ref: "#/definitions/IdentityExportDTO"
+// responses:
+//   200:
+//     description: Encrypted keystore JSON
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Invalid passphrase
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesAPI) Export(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := params.ByName("id")
+	exportReq := identityExportDto{}
+	if err := json.NewDecoder(request.Body).Decode(&exportReq); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	if exportReq.Passphrase == nil {
+		errorMap := validation.NewErrorMap()
+		errorMap.ForField("passphrase").AddError("required", "Field is required")
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+	passphrase := *exportReq.Passphrase
+	newPassphrase := passphrase
+	if exportReq.NewPassphrase != nil {
+		newPassphrase = *exportReq.NewPassphrase
+	}
+
+	if !endpoint.idm.HasIdentity(id) {
+		utils.SendErrorMessage(resp, "Identity not found", http.StatusNotFound)
+		return
+	}
+
+	keyJSON, err := endpoint.idm.ExportIdentity(id, passphrase, newPassphrase)
+	if err == identity.ErrInvalidPassphrase {
+		utils.SendError(resp, err, http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Write(keyJSON)
+}
+
+// swagger:operation POST /identities-import Identity importIdentity
+// ---
+// summary: Imports identity
+// description: Decrypts exported keystore with passphrase and stores identity in keystore encrypted with new passphrase
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (keystore, passphrase, newPassphrase) required for importing identity
+//     schema:
+//       $ref: "#/definitions/IdentityImportDTO"
+// responses:
+//   200:
+//     description: Identity imported
+//     schema:
+//       "$ref": "#/definitions/IdentityDTO"
+//   400:
+//     description: Bad Request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Invalid passphrase
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Identity already exists
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesAPI) Import(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	importReq := identityImportDto{}
+	if err := json.NewDecoder(request.Body).Decode(&importReq); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	keyJSON, errorMap := validateImportRequest(importReq)
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	newPassphrase := *importReq.Passphrase
+	if importReq.NewPassphrase != nil {
+		newPassphrase = *importReq.NewPassphrase
+	}
+
+	id, err := endpoint.idm.ImportIdentity(keyJSON, *importReq.Passphrase, newPassphrase)
+	switch err {
+	case nil:
+	case identity.ErrInvalidPassphrase:
+		utils.SendError(resp, err, http.StatusForbidden)
+		return
+	case identity.ErrIdentityExists:
+		utils.SendError(resp, err, http.StatusConflict)
+		return
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(idToDto(id), resp)
+}
+
+// swagger:operation PUT /identities/{id}/passphrase Identity changeIdentityPassphrase
+// ---
+// summary: Changes identity passphrase
+// description: Re-encrypts identity stored in keystore with new passphrase
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Parameters in body (oldPassphrase, newPassphrase) required for changing passphrase
+//   schema:
+//     $ref: "#/definitions/IdentityPassphraseChangeDTO"
+// responses:
+//   202:
+//     description: Passphrase changed
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Invalid passphrase
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesAPI) ChangePassphrase(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := params.ByName("id")
+	changeReq := identityPassphraseChangeDto{}
+	if err := json.NewDecoder(request.Body).Decode(&changeReq); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	errorMap := validation.NewErrorMap()
+	if changeReq.OldPassphrase == nil {
+		errorMap.ForField("oldPassphrase").AddError("required", "Field is required")
+	}
+	if changeReq.NewPassphrase == nil {
+		errorMap.ForField("newPassphrase").AddError("required", "Field is required")
+	}
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	if !endpoint.idm.HasIdentity(id) {
+		utils.SendErrorMessage(resp, "Identity not found", http.StatusNotFound)
+		return
+	}
+
+	err := endpoint.idm.ChangePassphrase(id, *changeReq.OldPassphrase, *changeReq.NewPassphrase)
+	if err == identity.ErrInvalidPassphrase {
+		utils.SendError(resp, err, http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// swagger:operation DELETE /identities/{id} Identity deleteIdentity
+// ---
+// summary: Deletes identity
+// description: Removes identity from keystore. Identity used by active connection or running service can not be deleted
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Parameter in body (passphrase) required for deleting identity
+//   schema:
+//     $ref: "#/definitions/IdentityDeletionDTO"
+// responses:
+//   202:
+//     description: Identity deleted
+//   400:
+//     description: Body parsing error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Invalid passphrase
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Identity is in use
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *identitiesAPI) Delete(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	id := params.ByName("id")
+	deleteReq := identityDeletionDto{}
+	if err := json.NewDecoder(request.Body).Decode(&deleteReq); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	if deleteReq.Passphrase == nil {
+		errorMap := validation.NewErrorMap()
+		errorMap.ForField("passphrase").AddError("required", "Field is required")
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	if !endpoint.idm.HasIdentity(id) {
+		utils.SendErrorMessage(resp, "Identity not found", http.StatusNotFound)
+		return
+	}
+
+	err := endpoint.idm.DeleteIdentity(id, *deleteReq.Passphrase)
+	if err == identity.ErrIdentityInUse {
+		utils.SendErrorMessage(resp, "Identity is in use by connection or service", http.StatusConflict)
+		return
+	}
+	if err == identity.ErrInvalidPassphrase {
+		utils.SendError(resp, err, http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	if endpoint.cache != nil {
+		if err := endpoint.cache.ForgetIdentity(identity.FromAddress(id)); err != nil {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
 func toCreateRequest(req *http.Request) (*identityCreationDto, error) {
 	var identityCreationReq = &identityCreationDto{}
 	err := json.NewDecoder(req.Body).Decode(&identityCreationReq)
@@ -215,6 +624,31 @@ func validateUnlockRequest(unlockReq identityUnlockingDto) (errors *validation.F
 	if unlockReq.Passphrase == nil {
 		errors.ForField("passphrase").AddError("required", "Field is required")
 	}
+	if unlockReq.Timeout < 0 {
+		errors.ForField("timeout").AddError("invalid", "Timeout can not be negative")
+	}
+	return
+}
+
+// validateImportRequest returns keystore JSON, unwrapped when it was sent as a string
+func validateImportRequest(importReq identityImportDto) (keyJSON []byte, errors *validation.FieldErrorMap) {
+	errors = validation.NewErrorMap()
+	if importReq.Passphrase == nil {
+		errors.ForField("passphrase").AddError("required", "Field is required")
+	}
+
+	var keyString string
+	switch {
+	case len(importReq.Keystore) == 0 || string(importReq.Keystore) == "null":
+		errors.ForField("keystore").AddError("required", "Field is required")
+	case json.Unmarshal(importReq.Keystore, &keyString) == nil:
+		keyJSON = []byte(keyString)
+	default:
+		keyJSON = importReq.Keystore
+	}
+	if keyJSON != nil && !json.Valid(keyJSON) {
+		errors.ForField("keystore").AddError("invalid", "Keystore is not valid JSON")
+	}
 	return
 }
 
@@ -231,9 +665,15 @@ func AddRoutesForIdentities(
 	router *httprouter.Router,
 	idm identity.Manager,
 	signerFactory identity.SignerFactory,
+	cache identity.IdentityCacheInterface,
 ) {
-	idmEnd := NewIdentitiesEndpoint(idm, signerFactory)
+	idmEnd := NewIdentitiesEndpoint(idm, signerFactory, cache)
 	router.GET("/identities", idmEnd.List)
 	router.POST("/identities", idmEnd.Create)
 	router.PUT("/identities/:id/unlock", idmEnd.Unlock)
+	router.PUT("/identities/:id/lock", idmEnd.Lock)
+	router.POST("/identities/:id/export", idmEnd.Export)
+	router.POST("/identities-import", idmEnd.Import)
+	router.PUT("/identities/:id/passphrase", idmEnd.ChangePassphrase)
+	router.DELETE("/identities/:id", idmEnd.Delete)
 }
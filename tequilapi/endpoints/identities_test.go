@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/identity"
@@ -50,7 +51,7 @@ func TestUnlockIdentitySuccess(t *testing.T) {
 	params := httprouter.Params{{"id", "1234abcd"}}
 	assert.Nil(t, err)
 
-	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory).Unlock
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Unlock
 	handlerFunc(resp, req, params)
 
 	assert.Equal(t, http.StatusAccepted, resp.Code)
@@ -70,7 +71,7 @@ func TestUnlockIdentityWithInvalidJSON(t *testing.T) {
 	params := httprouter.Params{{"id", "1234abcd"}}
 	assert.Nil(t, err)
 
-	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory).Unlock
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Unlock
 	handlerFunc(resp, req, params)
 
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
@@ -86,7 +87,7 @@ func TestUnlockIdentityWithNoPassphrase(t *testing.T) {
 	assert.NoError(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory).Unlock
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Unlock
 	handlerFunc(resp, req, nil)
 
 	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
@@ -115,7 +116,7 @@ func TestUnlockFailure(t *testing.T) {
 
 	mockIdm.MarkUnlockToFail()
 
-	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory).Unlock
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Unlock
 	handlerFunc(resp, req, params)
 
 	assert.Equal(t, http.StatusForbidden, resp.Code)
@@ -124,6 +125,83 @@ func TestUnlockFailure(t *testing.T) {
 	assert.Equal(t, "mypassphrase", mockIdm.LastUnlockPassphrase)
 }
 
+func TestUnlockIdentityWithTimeout(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(
+		http.MethodPut,
+		identityUrl,
+		bytes.NewBufferString(`{"passphrase": "mypassphrase", "timeout": 600}`),
+	)
+	params := httprouter.Params{{"id", "1234abcd"}}
+	assert.Nil(t, err)
+
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Unlock
+	handlerFunc(resp, req, params)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, "1234abcd", mockIdm.LastUnlockAddress)
+	assert.Equal(t, 10*time.Minute, mockIdm.LastUnlockTimeout)
+}
+
+func TestUnlockIdentityWithNegativeTimeout(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(
+		http.MethodPut,
+		identityUrl,
+		bytes.NewBufferString(`{"passphrase": "mypassphrase", "timeout": -1}`),
+	)
+	params := httprouter.Params{{"id", "1234abcd"}}
+	assert.Nil(t, err)
+
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Unlock
+	handlerFunc(resp, req, params)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors" : {
+				"timeout": [ {"code" : "invalid" , "message" : "Timeout can not be negative" } ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+	assert.Equal(t, "", mockIdm.LastUnlockAddress)
+}
+
+func TestLockIdentitySuccess(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPut, identityUrl, nil)
+	params := httprouter.Params{{"id", "0x000000000000000000000000000000000000000a"}}
+	assert.Nil(t, err)
+
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Lock
+	handlerFunc(resp, req, params)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, "0x000000000000000000000000000000000000000a", mockIdm.LastLockAddress)
+}
+
+func TestLockIdentityInUse(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	mockIdm.MarkIdentityInUse()
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPut, identityUrl, nil)
+	params := httprouter.Params{{"id", "0x000000000000000000000000000000000000000a"}}
+	assert.Nil(t, err)
+
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Lock
+	handlerFunc(resp, req, params)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"message": "identity is in use"}`, resp.Body.String())
+	assert.Equal(t, "", mockIdm.LastLockAddress)
+}
+
 func TestCreateNewIdentityEmptyPassphrase(t *testing.T) {
 	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
 	req, err := http.NewRequest(
@@ -135,7 +213,7 @@ func TestCreateNewIdentityEmptyPassphrase(t *testing.T) {
 	assert.Nil(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory).Create
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Create
 	handlerFunc(resp, req, nil)
 
 	assert.Equal(t, http.StatusOK, resp.Code)
@@ -152,7 +230,7 @@ func TestCreateNewIdentityNoPassphrase(t *testing.T) {
 	assert.Nil(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory).Create
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Create
 	handlerFunc(resp, req, nil)
 
 	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
@@ -179,7 +257,7 @@ func TestCreateNewIdentity(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 
-	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory).Create
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Create
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -196,7 +274,7 @@ func TestListIdentities(t *testing.T) {
 	req := httptest.NewRequest("GET", "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
-	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory).List
+	handlerFunc := NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -210,3 +288,155 @@ func TestListIdentities(t *testing.T) {
 		resp.Body.String(),
 	)
 }
+
+func TestExportIdentity(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/identities/0x000000000000000000000000000000000000000a/export",
+		bytes.NewBufferString(`{"passphrase": "old", "newPassphrase": "new"}`),
+	)
+	params := httprouter.Params{{"id", "0x000000000000000000000000000000000000000a"}}
+	resp := httptest.NewRecorder()
+
+	NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Export(resp, req, params)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"address": "0x000000000000000000000000000000000000000a"}`, resp.Body.String())
+}
+
+func TestExportIdentityWithNoPassphrase(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/identities/0x000000000000000000000000000000000000000a/export",
+		bytes.NewBufferString(`{"newPassphrase": "new"}`),
+	)
+	params := httprouter.Params{{"id", "0x000000000000000000000000000000000000000a"}}
+	resp := httptest.NewRecorder()
+
+	NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Export(resp, req, params)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"passphrase": [{"code": "required", "message": "Field is required"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestImportIdentity(t *testing.T) {
+	keystores := []string{
+		`{"address": "000000000000000000000000000000000000aaac", "crypto": {}}`,
+		`"{\"address\": \"000000000000000000000000000000000000aaac\", \"crypto\": {}}"`,
+	}
+
+	for _, keystore := range keystores {
+		mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/identities-import",
+			bytes.NewBufferString(`{"keystore": `+keystore+`, "passphrase": "old", "newPassphrase": "new"}`),
+		)
+		resp := httptest.NewRecorder()
+
+		NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Import(resp, req, nil)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{"id": "0x000000000000000000000000000000000000aaac"}`, resp.Body.String())
+	}
+}
+
+func TestImportIdentityWithNoKeystore(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	req := httptest.NewRequest(http.MethodPost, "/identities-import", bytes.NewBufferString(`{"passphrase": "old"}`))
+	resp := httptest.NewRecorder()
+
+	NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Import(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"keystore": [{"code": "required", "message": "Field is required"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestChangeIdentityPassphrase(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/identities/0x000000000000000000000000000000000000000a/passphrase",
+		bytes.NewBufferString(`{"oldPassphrase": "old", "newPassphrase": "new"}`),
+	)
+	params := httprouter.Params{{"id", "0x000000000000000000000000000000000000000a"}}
+	resp := httptest.NewRecorder()
+
+	NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).ChangePassphrase(resp, req, params)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+}
+
+func TestChangeIdentityPassphraseWithInvalidPassphrase(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	mockIdm.MarkUnlockToFail()
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/identities/0x000000000000000000000000000000000000000a/passphrase",
+		bytes.NewBufferString(`{"oldPassphrase": "wrong", "newPassphrase": "new"}`),
+	)
+	params := httprouter.Params{{"id", "0x000000000000000000000000000000000000000a"}}
+	resp := httptest.NewRecorder()
+
+	NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).ChangePassphrase(resp, req, params)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestDeleteIdentity(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	cache := identity.NewIdentityCacheFake()
+	cache.StoreIdentity(existingIdentities[0])
+	req := httptest.NewRequest(
+		http.MethodDelete,
+		"/identities/0x000000000000000000000000000000000000000a",
+		bytes.NewBufferString(`{"passphrase": "passphrase"}`),
+	)
+	params := httprouter.Params{{"id", "0x000000000000000000000000000000000000000a"}}
+	resp := httptest.NewRecorder()
+
+	NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, cache).Delete(resp, req, params)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, "0x000000000000000000000000000000000000000a", mockIdm.LastDeletedAddress)
+	cached, err := cache.GetIdentity()
+	assert.NoError(t, err)
+	assert.Equal(t, identity.Identity{}, cached)
+}
+
+func TestDeleteIdentityInUse(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	mockIdm.MarkIdentityInUse()
+	req := httptest.NewRequest(
+		http.MethodDelete,
+		"/identities/0x000000000000000000000000000000000000000a",
+		bytes.NewBufferString(`{"passphrase": "passphrase"}`),
+	)
+	params := httprouter.Params{{"id", "0x000000000000000000000000000000000000000a"}}
+	resp := httptest.NewRecorder()
+
+	NewIdentitiesEndpoint(mockIdm, fakeSignerFactory, nil).Delete(resp, req, params)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Empty(t, mockIdm.LastDeletedAddress)
+}
@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/connection"
@@ -53,7 +54,9 @@ func NewLocationEndpoint(manager connection.Manager, locationDetector location.D
 // swagger:operation GET /location Location getLocation
 // ---
 // summary: Returns location
-// description: Returns original and current locations
+// description: Returns original and current locations.
+//   City and ASN are resolved only when City and ASN databases are given with --location.database flag,
+//   built-in database resolves country only.
 // responses:
 //   200:
 //     description: Original and current locations
@@ -90,6 +93,59 @@ func (le *LocationEndpoint) GetLocation(writer http.ResponseWriter, request *htt
 	utils.WriteAsJSON(response, writer)
 }
 
+// swagger:model LocationDatabaseDTO
+type locationDatabaseResponse struct {
+	// example: GeoLite2-Country
+	Type string `json:"type"`
+
+	// example: 2019-02-05T00:00:00Z
+	BuildDate string `json:"buildDate"`
+}
+
+// swagger:model LocationDatabaseList
+type locationDatabaseList struct {
+	Databases []locationDatabaseResponse `json:"databases"`
+}
+
+// LocationDatabaseEndpoint struct represents /location/databases resource
+type LocationDatabaseEndpoint struct {
+	resolver location.Resolver
+}
+
+// NewLocationDatabaseEndpoint creates and returns location databases endpoint
+func NewLocationDatabaseEndpoint(resolver location.Resolver) *LocationDatabaseEndpoint {
+	return &LocationDatabaseEndpoint{resolver: resolver}
+}
+
+// GetDatabases responds with geolocation databases used to resolve location
+// swagger:operation GET /location/databases Location getLocationDatabases
+// ---
+// summary: Returns location databases
+// description: Returns type and build date of geolocation databases used to resolve location. List is empty when location is not resolved from databases.
+// responses:
+//   200:
+//     description: Location databases
+//     schema:
+//       "$ref": "#/definitions/LocationDatabaseList"
+func (lde *LocationDatabaseEndpoint) GetDatabases(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	response := locationDatabaseList{Databases: []locationDatabaseResponse{}}
+	if reporter, ok := lde.resolver.(location.DatabaseReporter); ok {
+		for _, database := range reporter.Databases() {
+			response.Databases = append(response.Databases, locationDatabaseResponse{
+				Type:      database.Type,
+				BuildDate: database.BuildDate.UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	utils.WriteAsJSON(response, writer)
+}
+
+// AddRoutesForLocationDatabases adds location databases routes to given router
+func AddRoutesForLocationDatabases(router *httprouter.Router, resolver location.Resolver) {
+	endpoint := NewLocationDatabaseEndpoint(resolver)
+	router.GET("/location/databases", endpoint.GetDatabases)
+}
+
 // AddRoutesForLocation adds location routes to given router
 func AddRoutesForLocation(router *httprouter.Router, manager connection.Manager,
 	locationDetector location.Detector, locationCache location.Cache) {
@@ -22,6 +22,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/connection"
@@ -155,3 +156,41 @@ func TestGetLocationWhenNotConnected(t *testing.T) {
 		)
 	}
 }
+
+type fakeDatabaseResolver struct {
+	location.StaticResolver
+	databases []location.DatabaseInfo
+}
+
+func (fdr *fakeDatabaseResolver) Databases() []location.DatabaseInfo {
+	return fdr.databases
+}
+
+func TestGetDatabasesReturnsDatabasesOfResolver(t *testing.T) {
+	resolver := &fakeDatabaseResolver{
+		databases: []location.DatabaseInfo{
+			{Type: "GeoLite2-City", BuildDate: time.Date(2019, 2, 5, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	endpoint := NewLocationDatabaseEndpoint(resolver)
+	resp := httptest.NewRecorder()
+
+	endpoint.GetDatabases(resp, nil, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{"databases": [{"type": "GeoLite2-City", "buildDate": "2019-02-05T00:00:00Z"}]}`,
+		resp.Body.String(),
+	)
+}
+
+func TestGetDatabasesReturnsEmptyListWithoutDatabaseResolver(t *testing.T) {
+	endpoint := NewLocationDatabaseEndpoint(location.NewStaticResolver("LT"))
+	resp := httptest.NewRecorder()
+
+	endpoint.GetDatabases(resp, nil, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"databases": []}`, resp.Body.String())
+}
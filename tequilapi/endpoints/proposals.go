@@ -20,16 +20,45 @@ package endpoints
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
+	log "github.com/cihub/seelog"
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/quality"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/market/metrics"
+	"github.com/mysteriumnetwork/node/market/proposals/repository"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
 // swagger:model ProposalsList
 type proposalsRes struct {
 	Proposals []proposalRes `json:"proposals"`
+
+	// current page number, set when limit is given
+	// example: 1
+	Page int `json:"page,omitempty"`
+
+	// proposals per page, set when limit is given
+	// example: 20
+	Limit int `json:"limit,omitempty"`
+
+	// count of all proposals matching the query, set when limit is given
+	// example: 150
+	Total int `json:"total,omitempty"`
+
+	// time of last successful proposals refresh from discovery
+	// example: 2019-06-06T11:04:43.910035Z
+	UpdatedAt string `json:"updatedAt,omitempty"`
+
+	// seconds passed since last successful proposals refresh
+	// example: 60
+	Age int `json:"age,omitempty"`
+
+	// true when discovery is unreachable and cached proposals are served
+	// example: false
+	Stale bool `json:"stale,omitempty"`
 }
 
 // swagger:model ServiceLocationDTO
@@ -45,6 +74,43 @@ type locationRes struct {
 	City string `json:"city,omitempty"`
 }
 
+// swagger:model PaymentMethodDTO
+type paymentMethodRes struct {
+	// example: PER_TIME
+	Type string `json:"type"`
+
+	// example: {"amount": 12500000, "currency": "MYST"}
+	Price money.Money `json:"price"`
+}
+
+// swagger:model LocalScoreDTO
+type localScoreRes struct {
+	// overall provider score based on local connection history, from 0 (worst) to 1 (best)
+	// example: 0.82
+	Value float64 `json:"value"`
+
+	// example: 10
+	Attempts int `json:"attempts"`
+
+	// example: 1
+	Failures int `json:"failures"`
+
+	// count of connections lost without consumer request
+	// example: 0
+	Drops int `json:"drops"`
+
+	// example: 9
+	Sessions int `json:"sessions"`
+
+	// average seconds to establish connection
+	// example: 3.5
+	AvgConnectTime float64 `json:"avgConnectTime"`
+
+	// average traffic of completed sessions in bytes per second
+	// example: 262144
+	AvgThroughput uint64 `json:"avgThroughput"`
+}
+
 // swagger:model ServiceDefinitionDTO
 type serviceDefinitionRes struct {
 	LocationOriginate locationRes `json:"locationOriginate"`
@@ -67,12 +133,22 @@ type proposalRes struct {
 	// qualitative service definition
 	ServiceDefinition serviceDefinitionRes `json:"serviceDefinition"`
 
+	// payment method of the service, omitted when it is not supported by the node
+	PaymentMethod *paymentMethodRes `json:"paymentMethod,omitempty"`
+
 	// Metrics of the service
 	Metrics json.RawMessage `json:"metrics,omitempty"`
+
+	// provider score based on local connection history, omitted when there is no history
+	LocalScore *localScoreRes `json:"localScore,omitempty"`
+
+	// true when provider is marked as favorite by consumer
+	// example: false
+	Favorite bool `json:"favorite,omitempty"`
 }
 
 func proposalToRes(p market.ServiceProposal) proposalRes {
-	return proposalRes{
+	res := proposalRes{
 		ID:          p.ID,
 		ProviderID:  p.ProviderID,
 		ServiceType: p.ServiceType,
@@ -84,6 +160,13 @@ func proposalToRes(p market.ServiceProposal) proposalRes {
 			},
 		},
 	}
+	if _, ok := proposalPrice(p); ok {
+		res.PaymentMethod = &paymentMethodRes{
+			Type:  p.PaymentMethodType,
+			Price: p.PaymentMethod.GetPrice(),
+		}
+	}
+	return res
 }
 
 func mapProposalsToRes(
@@ -103,20 +186,37 @@ type ProposalProvider interface {
 	FindProposals(providerID string, serviceType string) ([]market.ServiceProposal, error)
 }
 
+// ProviderScores provides provider scores calculated from local connection history
+type ProviderScores interface {
+	Scores() (map[string]quality.Score, error)
+}
+
+// proposalCache is implemented by proposal providers which serve cached proposals
+type proposalCache interface {
+	Status() repository.Status
+}
+
 type proposalsEndpoint struct {
 	proposalProvider     ProposalProvider
 	mysteriumMorqaClient metrics.QualityOracle
+	providerScores       ProviderScores
+	providerFilter       ProviderFilter
 }
 
-// NewProposalsEndpoint creates and returns proposal creation endpoint
-func NewProposalsEndpoint(proposalProvider ProposalProvider, morqaClient metrics.QualityOracle) *proposalsEndpoint {
-	return &proposalsEndpoint{proposalProvider, morqaClient}
+// NewProposalsEndpoint creates and returns proposal creation endpoint, providerScores and providerFilter are optional
+func NewProposalsEndpoint(
+	proposalProvider ProposalProvider,
+	morqaClient metrics.QualityOracle,
+	providerScores ProviderScores,
+	providerFilter ProviderFilter,
+) *proposalsEndpoint {
+	return &proposalsEndpoint{proposalProvider, morqaClient, providerScores, providerFilter}
 }
 
 // swagger:operation GET /proposals Proposal listProposals
 // ---
 // summary: Returns proposals
-// description: Returns list of proposals filtered by provider id
+// description: Returns list of proposals filtered, sorted and paginated by given parameters
 // parameters:
 //   - in: query
 //     name: providerId
@@ -127,6 +227,46 @@ func NewProposalsEndpoint(proposalProvider ProposalProvider, morqaClient metrics
 //     description: the service type of the proposal
 //     type: string
 //   - in: query
+//     name: country
+//     description: country code of the proposal location
+//     type: string
+//   - in: query
+//     name: city
+//     description: city of the proposal location
+//     type: string
+//   - in: query
+//     name: asn
+//     description: autonomous system number of the proposal location
+//     type: string
+//   - in: query
+//     name: paymentMethod
+//     description: the payment method type of the proposal
+//     type: string
+//   - in: query
+//     name: maxPrice
+//     description: maximum price amount of the proposal
+//     type: integer
+//   - in: query
+//     name: minQuality
+//     description: minimum ratio of successful connections to the proposal, from 0 to 1
+//     type: number
+//   - in: query
+//     name: favorite
+//     description: if set to true, returns proposals of favorite providers only. Proposals of blocked providers are never returned.
+//     type: boolean
+//   - in: query
+//     name: sort
+//     description: sort order of proposals, one of price, quality, localScore, country
+//     type: string
+//   - in: query
+//     name: page
+//     description: page number, starting from 1
+//     type: integer
+//   - in: query
+//     name: limit
+//     description: proposals per page (at most 1000), all proposals are returned when not set
+//     type: integer
+//   - in: query
 //     name: fetchConnectCounts
 //     description: if set to true, fetches the connection success metrics for nodes. False by default.
 //     type: boolean
@@ -135,53 +275,149 @@ func NewProposalsEndpoint(proposalProvider ProposalProvider, morqaClient metrics
 //     description: List of proposals
 //     schema:
 //       "$ref": "#/definitions/ProposalsList"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
 func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
-	providerID := req.URL.Query().Get("providerId")
-	serviceType := req.URL.Query().Get("serviceType")
-	fetchConnectCounts := req.URL.Query().Get("fetchConnectCounts")
-	proposals, err := pe.proposalProvider.FindProposals(providerID, serviceType)
+	query, errorMap := parseProposalsQuery(req.URL.Query())
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	proposals, err := pe.proposalProvider.FindProposals(query.ProviderID, query.ServiceType)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	proposals, favorites, err := pe.applyPreferences(proposals, query.Favorite)
 	if err != nil {
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
 	}
 
+	var metrics proposalsMetrics
+	if query.needsMetrics() {
+		metrics = fetchMetrics(pe.mysteriumMorqaClient)
+	}
+	scores := pe.fetchScores()
+	proposals, total := query.apply(proposals, metrics, scores)
+
 	addMetricsToRes := noMetrics
-	if fetchConnectCounts == "true" {
-		addMetricsToRes = addMetrics(pe.mysteriumMorqaClient)
+	if query.FetchConnectCounts {
+		addMetricsToRes = addMetrics(metrics)
 	}
 
-	proposalsRes := proposalsRes{mapProposalsToRes(proposals, proposalToRes, addMetricsToRes)}
+	proposalsRes := proposalsRes{Proposals: mapProposalsToRes(proposals, proposalToRes, addMetricsToRes)}
+	for i := range proposalsRes.Proposals {
+		proposalsRes.Proposals[i].LocalScore = scores.res(proposalsRes.Proposals[i].ProviderID)
+		proposalsRes.Proposals[i].Favorite = favorites[proposalsRes.Proposals[i].ProviderID]
+	}
+	if query.Limit > 0 {
+		proposalsRes.Page = query.Page
+		proposalsRes.Limit = query.Limit
+		proposalsRes.Total = total
+	}
+	if cache, ok := pe.proposalProvider.(proposalCache); ok {
+		status := cache.Status()
+		if !status.UpdatedAt.IsZero() {
+			proposalsRes.UpdatedAt = status.UpdatedAt.UTC().Format(time.RFC3339)
+			proposalsRes.Age = int(status.Age.Seconds())
+		}
+		proposalsRes.Stale = status.Stale
+	}
 	utils.WriteAsJSON(proposalsRes, resp)
 }
 
+// applyPreferences drops proposals of blocked providers and, if asked, keeps favorite providers only.
+// It also returns which of the remaining providers are favorite.
+func (pe *proposalsEndpoint) applyPreferences(proposals []market.ServiceProposal, favoriteOnly bool) ([]market.ServiceProposal, map[string]bool, error) {
+	favorites := make(map[string]bool)
+	if pe.providerFilter == nil {
+		if favoriteOnly {
+			return []market.ServiceProposal{}, favorites, nil
+		}
+		return proposals, favorites, nil
+	}
+
+	result := make([]market.ServiceProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		blocked, err := pe.providerFilter.IsBlocked(proposal.ProviderID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if blocked {
+			continue
+		}
+		favorite, err := pe.providerFilter.IsFavorite(proposal.ProviderID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if favoriteOnly && !favorite {
+			continue
+		}
+		favorites[proposal.ProviderID] = favorite
+		result = append(result, proposal)
+	}
+	return result, favorites, nil
+}
+
+func (pe *proposalsEndpoint) fetchScores() proposalsScores {
+	if pe.providerScores == nil {
+		return nil
+	}
+
+	scores, err := pe.providerScores.Scores()
+	if err != nil {
+		log.Warn("failed to calculate local provider scores: ", err)
+		return nil
+	}
+	return scores
+}
+
 // AddRoutesForProposals attaches proposals endpoints to router
-func AddRoutesForProposals(router *httprouter.Router, proposalProvider ProposalProvider, morqaClient metrics.QualityOracle) {
-	pe := NewProposalsEndpoint(proposalProvider, morqaClient)
+func AddRoutesForProposals(
+	router *httprouter.Router,
+	proposalProvider ProposalProvider,
+	morqaClient metrics.QualityOracle,
+	providerScores ProviderScores,
+	providerFilter ProviderFilter,
+) {
+	pe := NewProposalsEndpoint(proposalProvider, morqaClient, providerScores, providerFilter)
 	router.GET("/proposals", pe.List)
 }
 
 func noMetrics(p proposalRes) proposalRes { return p }
 
-func addMetrics(mc metrics.QualityOracle) func(p proposalRes) proposalRes {
+func fetchMetrics(mc metrics.QualityOracle) proposalsMetrics {
 	receivedMetrics := mc.ProposalsMetrics()
-	proposalsMetrics := make(map[string]json.RawMessage, len(receivedMetrics))
+	result := make(proposalsMetrics, len(receivedMetrics))
 	var proposal struct{ ProposalID proposalRes }
 
 	for _, m := range receivedMetrics {
 		json, err := metrics.Parse(m, &proposal)
 		if err != nil {
-			return noMetrics
+			return nil
 		}
 		p := proposal.ProposalID
-		proposalsMetrics[p.ProviderID+"-"+p.ServiceType] = json
+		result[proposalMetricsKey(p.ProviderID, p.ServiceType)] = json
+	}
+	return result
+}
+
+func addMetrics(received proposalsMetrics) func(p proposalRes) proposalRes {
+	if received == nil {
+		return noMetrics
 	}
 
 	return func(p proposalRes) proposalRes {
-		if metrics, ok := proposalsMetrics[p.ProviderID+"-"+p.ServiceType]; ok {
+		if metrics, ok := received[proposalMetricsKey(p.ProviderID, p.ServiceType)]; ok {
 			p.Metrics = metrics
 			return p
 		}
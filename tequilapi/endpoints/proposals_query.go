@@ -0,0 +1,272 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/consumer/quality"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+const (
+	sortByPrice      = "price"
+	sortByQuality    = "quality"
+	sortByLocalScore = "localScore"
+	sortByCountry    = "country"
+)
+
+// proposalsQuery holds filtering, sorting and pagination options of proposals listing
+type proposalsQuery struct {
+	ProviderID         string
+	ServiceType        string
+	Country            string
+	City               string
+	ASN                string
+	PaymentMethod      string
+	MaxPrice           *uint64
+	MinQuality         *float64
+	SortBy             string
+	Page               int
+	Limit              int
+	Favorite           bool
+	FetchConnectCounts bool
+}
+
+func parseProposalsQuery(values url.Values) (proposalsQuery, *validation.FieldErrorMap) {
+	errors := validation.NewErrorMap()
+	query := proposalsQuery{
+		ProviderID:         values.Get("providerId"),
+		ServiceType:        values.Get("serviceType"),
+		Country:            values.Get("country"),
+		City:               values.Get("city"),
+		ASN:                values.Get("asn"),
+		PaymentMethod:      values.Get("paymentMethod"),
+		SortBy:             values.Get("sort"),
+		Page:               1,
+		Favorite:           values.Get("favorite") == "true",
+		FetchConnectCounts: values.Get("fetchConnectCounts") == "true",
+	}
+
+	if value := values.Get("maxPrice"); value != "" {
+		maxPrice, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			errors.ForField("maxPrice").AddError("invalid", "Must be a non negative integer")
+		}
+		query.MaxPrice = &maxPrice
+	}
+	if value := values.Get("minQuality"); value != "" {
+		minQuality, err := strconv.ParseFloat(value, 64)
+		if err != nil || minQuality < 0 || minQuality > 1 {
+			errors.ForField("minQuality").AddError("invalid", "Must be a number between 0 and 1")
+		}
+		query.MinQuality = &minQuality
+	}
+	switch query.SortBy {
+	case "", sortByPrice, sortByQuality, sortByLocalScore, sortByCountry:
+	default:
+		errors.ForField("sort").AddError("invalid", "Must be one of: price, quality, localScore, country")
+	}
+	if value := values.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			errors.ForField("page").AddError("invalid", "Must be a positive integer")
+		}
+		query.Page = page
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxQueryLimit {
+			errors.ForField("limit").AddError("invalid", fmt.Sprintf("Must be an integer between 1 and %d", maxQueryLimit))
+		}
+		query.Limit = limit
+	}
+
+	return query, errors
+}
+
+// needsMetrics tells whether quality metrics have to be fetched to answer the query
+func (query proposalsQuery) needsMetrics() bool {
+	return query.FetchConnectCounts || query.MinQuality != nil || query.SortBy == sortByQuality
+}
+
+func (query proposalsQuery) matches(proposal market.ServiceProposal, metrics proposalsMetrics) bool {
+	location := proposalLocation(proposal)
+	if query.Country != "" && !strings.EqualFold(location.Country, query.Country) {
+		return false
+	}
+	if query.City != "" && !strings.EqualFold(location.City, query.City) {
+		return false
+	}
+	if query.ASN != "" && !strings.EqualFold(location.ASN, query.ASN) {
+		return false
+	}
+	if query.PaymentMethod != "" && proposal.PaymentMethodType != query.PaymentMethod {
+		return false
+	}
+	if query.MaxPrice != nil {
+		price, ok := proposalPrice(proposal)
+		if !ok || price > *query.MaxPrice {
+			return false
+		}
+	}
+	if query.MinQuality != nil {
+		quality, ok := metrics.quality(proposal)
+		if !ok || quality < *query.MinQuality {
+			return false
+		}
+	}
+	return true
+}
+
+// apply filters, sorts and paginates given proposals. Total count of matching proposals is returned as well.
+func (query proposalsQuery) apply(proposals []market.ServiceProposal, metrics proposalsMetrics, scores proposalsScores) ([]market.ServiceProposal, int) {
+	filtered := make([]market.ServiceProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		if query.matches(proposal, metrics) {
+			filtered = append(filtered, proposal)
+		}
+	}
+
+	switch query.SortBy {
+	case sortByPrice:
+		sort.SliceStable(filtered, func(i, j int) bool {
+			priceI, okI := proposalPrice(filtered[i])
+			priceJ, okJ := proposalPrice(filtered[j])
+			if okI != okJ {
+				return okI
+			}
+			return priceI < priceJ
+		})
+	case sortByQuality:
+		sort.SliceStable(filtered, func(i, j int) bool {
+			qualityI, okI := metrics.quality(filtered[i])
+			qualityJ, okJ := metrics.quality(filtered[j])
+			if okI != okJ {
+				return okI
+			}
+			return qualityI > qualityJ
+		})
+	case sortByLocalScore:
+		sort.SliceStable(filtered, func(i, j int) bool {
+			scoreI, okI := scores[filtered[i].ProviderID]
+			scoreJ, okJ := scores[filtered[j].ProviderID]
+			if okI != okJ {
+				return okI
+			}
+			return scoreI.Value > scoreJ.Value
+		})
+	case sortByCountry:
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return proposalLocation(filtered[i]).Country < proposalLocation(filtered[j]).Country
+		})
+	}
+
+	total := len(filtered)
+	if query.Limit == 0 {
+		return filtered, total
+	}
+
+	// page is checked against the last one before multiplying, so that huge pages do not overflow
+	if total == 0 || query.Page-1 > (total-1)/query.Limit {
+		return []market.ServiceProposal{}, total
+	}
+	start := (query.Page - 1) * query.Limit
+	end := total
+	if query.Limit < total-start {
+		end = start + query.Limit
+	}
+	return filtered[start:end], total
+}
+
+func proposalLocation(proposal market.ServiceProposal) market.Location {
+	if proposal.ServiceDefinition == nil {
+		return market.Location{}
+	}
+	return proposal.ServiceDefinition.GetLocation()
+}
+
+func proposalPrice(proposal market.ServiceProposal) (uint64, bool) {
+	switch proposal.PaymentMethod.(type) {
+	case nil, market.UnsupportedPaymentMethod:
+		return 0, false
+	}
+	return proposal.PaymentMethod.GetPrice().Amount, true
+}
+
+// proposalsMetrics holds quality metrics of proposals keyed by provider and service type
+type proposalsMetrics map[string]json.RawMessage
+
+func proposalMetricsKey(providerID, serviceType string) string {
+	return providerID + "-" + serviceType
+}
+
+func (metrics proposalsMetrics) get(proposal market.ServiceProposal) (json.RawMessage, bool) {
+	m, ok := metrics[proposalMetricsKey(proposal.ProviderID, proposal.ServiceType)]
+	return m, ok
+}
+
+// quality returns ratio of successful connections to the proposal
+func (metrics proposalsMetrics) quality(proposal market.ServiceProposal) (float64, bool) {
+	m, ok := metrics.get(proposal)
+	if !ok {
+		return 0, false
+	}
+
+	var counts struct {
+		ConnectCount struct {
+			Success int `json:"success"`
+			Fail    int `json:"fail"`
+			Timeout int `json:"timeout"`
+		} `json:"connectCount"`
+	}
+	if err := json.Unmarshal(m, &counts); err != nil {
+		return 0, false
+	}
+
+	total := counts.ConnectCount.Success + counts.ConnectCount.Fail + counts.ConnectCount.Timeout
+	if total == 0 {
+		return 0, false
+	}
+	return float64(counts.ConnectCount.Success) / float64(total), true
+}
+
+// proposalsScores holds local provider scores keyed by provider ID
+type proposalsScores map[string]quality.Score
+
+func (scores proposalsScores) res(providerID string) *localScoreRes {
+	score, ok := scores[providerID]
+	if !ok {
+		return nil
+	}
+	return &localScoreRes{
+		Value:          score.Value,
+		Attempts:       score.Attempts,
+		Failures:       score.Failures,
+		Drops:          score.Drops,
+		Sessions:       score.Sessions,
+		AvgConnectTime: score.AvgConnectTime.Seconds(),
+		AvgThroughput:  score.AvgThroughput,
+	}
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/quality"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+type testLocationDefinition struct {
+	location market.Location
+}
+
+func (service testLocationDefinition) GetLocation() market.Location {
+	return service.location
+}
+
+type testPaymentMethod struct {
+	amount uint64
+}
+
+func (method testPaymentMethod) GetPrice() money.Money {
+	return money.Money{Amount: method.amount, Currency: money.CURRENCY_MYST}
+}
+
+var (
+	proposalNL = market.ServiceProposal{
+		ProviderID:        "0x1",
+		ServiceType:       "openvpn",
+		ServiceDefinition: testLocationDefinition{market.Location{Country: "NL", City: "Amsterdam", ASN: "AS1"}},
+		PaymentMethodType: "PER_TIME",
+		PaymentMethod:     testPaymentMethod{300},
+	}
+	proposalLT = market.ServiceProposal{
+		ProviderID:        "0x2",
+		ServiceType:       "openvpn",
+		ServiceDefinition: testLocationDefinition{market.Location{Country: "LT", City: "Vilnius", ASN: "AS2"}},
+		PaymentMethodType: "PER_TIME",
+		PaymentMethod:     testPaymentMethod{100},
+	}
+	proposalUS = market.ServiceProposal{
+		ProviderID:        "0x3",
+		ServiceType:       "openvpn",
+		ServiceDefinition: testLocationDefinition{market.Location{Country: "US", City: "Chicago", ASN: "AS3"}},
+		PaymentMethodType: "PER_BYTES",
+		PaymentMethod:     market.UnsupportedPaymentMethod{},
+	}
+	queryProposals = []market.ServiceProposal{proposalNL, proposalLT, proposalUS}
+	queryMetrics   = proposalsMetrics{
+		"0x1-openvpn": json.RawMessage(`{"connectCount": {"success": 9, "fail": 1, "timeout": 0}}`),
+		"0x2-openvpn": json.RawMessage(`{"connectCount": {"success": 1, "fail": 1, "timeout": 2}}`),
+	}
+)
+
+func TestParseProposalsQuery(t *testing.T) {
+	query, errors := parseProposalsQuery(url.Values{
+		"country":    []string{"NL"},
+		"maxPrice":   []string{"200"},
+		"minQuality": []string{"0.5"},
+		"sort":       []string{"price"},
+		"page":       []string{"2"},
+		"limit":      []string{"10"},
+	})
+
+	assert.False(t, errors.HasErrors())
+	assert.Equal(t, "NL", query.Country)
+	assert.Equal(t, uint64(200), *query.MaxPrice)
+	assert.Equal(t, 0.5, *query.MinQuality)
+	assert.Equal(t, sortByPrice, query.SortBy)
+	assert.Equal(t, 2, query.Page)
+	assert.Equal(t, 10, query.Limit)
+	assert.True(t, query.needsMetrics())
+}
+
+func TestParseProposalsQueryValidatesValues(t *testing.T) {
+	_, errors := parseProposalsQuery(url.Values{
+		"maxPrice":   []string{"-1"},
+		"minQuality": []string{"2"},
+		"sort":       []string{"name"},
+		"page":       []string{"0"},
+		"limit":      []string{"1001"},
+	})
+
+	assert.JSONEq(
+		t,
+		`{
+			"maxPrice": [{"code": "invalid", "message": "Must be a non negative integer"}],
+			"minQuality": [{"code": "invalid", "message": "Must be a number between 0 and 1"}],
+			"sort": [{"code": "invalid", "message": "Must be one of: price, quality, localScore, country"}],
+			"page": [{"code": "invalid", "message": "Must be a positive integer"}],
+			"limit": [{"code": "invalid", "message": "Must be an integer between 1 and 1000"}]
+		}`,
+		toJSON(t, errors),
+	)
+}
+
+func TestProposalsQueryFiltersByLocation(t *testing.T) {
+	query := proposalsQuery{Country: "lt", Page: 1}
+	proposals, total := query.apply(queryProposals, nil, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalLT}, proposals)
+	assert.Equal(t, 1, total)
+
+	query = proposalsQuery{City: "Chicago", ASN: "AS3", Page: 1}
+	proposals, _ = query.apply(queryProposals, nil, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalUS}, proposals)
+}
+
+func TestProposalsQueryFiltersByPayment(t *testing.T) {
+	maxPrice := uint64(200)
+	query := proposalsQuery{MaxPrice: &maxPrice, Page: 1}
+	proposals, _ := query.apply(queryProposals, nil, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalLT}, proposals)
+
+	query = proposalsQuery{PaymentMethod: "PER_BYTES", Page: 1}
+	proposals, _ = query.apply(queryProposals, nil, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalUS}, proposals)
+}
+
+func TestProposalsQueryFiltersByQuality(t *testing.T) {
+	minQuality := 0.5
+	query := proposalsQuery{MinQuality: &minQuality, Page: 1}
+	proposals, _ := query.apply(queryProposals, queryMetrics, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalNL}, proposals)
+}
+
+func TestProposalsQuerySorts(t *testing.T) {
+	query := proposalsQuery{SortBy: sortByPrice, Page: 1}
+	proposals, _ := query.apply(queryProposals, nil, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalLT, proposalNL, proposalUS}, proposals)
+
+	query = proposalsQuery{SortBy: sortByQuality, Page: 1}
+	proposals, _ = query.apply([]market.ServiceProposal{proposalUS, proposalLT, proposalNL}, queryMetrics, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalNL, proposalLT, proposalUS}, proposals)
+
+	query = proposalsQuery{SortBy: sortByCountry, Page: 1}
+	proposals, _ = query.apply(queryProposals, nil, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalLT, proposalNL, proposalUS}, proposals)
+}
+
+func TestProposalsQuerySortsByLocalScore(t *testing.T) {
+	scores := proposalsScores{
+		"0x1": {Value: 0.4},
+		"0x3": {Value: 0.9},
+	}
+	query := proposalsQuery{SortBy: sortByLocalScore, Page: 1}
+	proposals, _ := query.apply([]market.ServiceProposal{proposalLT, proposalNL, proposalUS}, nil, scores)
+	assert.Equal(t, []market.ServiceProposal{proposalUS, proposalNL, proposalLT}, proposals)
+}
+
+func TestProposalsEndpointListIncludesLocalScores(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant?providerId=0x1", nil)
+	assert.Nil(t, err)
+
+	scores := &providerScoresFake{
+		scores: map[string]quality.Score{
+			"0x1": {Value: 0.75, Attempts: 4, Failures: 1, Sessions: 3, AvgConnectTime: 1500 * time.Millisecond, AvgThroughput: 2048},
+		},
+	}
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(&mockProposalProvider{proposals: []market.ServiceProposal{proposalNL}}, &mysteriumMorqaFake{}, scores, nil).List
+	handlerFunc(resp, req, nil)
+
+	var result struct {
+		Proposals []proposalRes `json:"proposals"`
+	}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Len(t, result.Proposals, 1)
+	assert.Equal(
+		t,
+		&localScoreRes{Value: 0.75, Attempts: 4, Failures: 1, Sessions: 3, AvgConnectTime: 1.5, AvgThroughput: 2048},
+		result.Proposals[0].LocalScore,
+	)
+}
+
+type providerScoresFake struct {
+	scores map[string]quality.Score
+}
+
+func (fake *providerScoresFake) Scores() (map[string]quality.Score, error) {
+	return fake.scores, nil
+}
+
+func TestProposalsQueryPaginates(t *testing.T) {
+	query := proposalsQuery{SortBy: sortByCountry, Page: 2, Limit: 2}
+	proposals, total := query.apply(queryProposals, nil, nil)
+	assert.Equal(t, []market.ServiceProposal{proposalUS}, proposals)
+	assert.Equal(t, 3, total)
+
+	query = proposalsQuery{Page: 3, Limit: 2}
+	proposals, total = query.apply(queryProposals, nil, nil)
+	assert.Len(t, proposals, 0)
+	assert.Equal(t, 3, total)
+
+	query = proposalsQuery{Page: int(^uint(0) >> 1), Limit: maxQueryLimit}
+	proposals, total = query.apply(queryProposals, nil, nil)
+	assert.Len(t, proposals, 0)
+	assert.Equal(t, 3, total)
+}
+
+func TestProposalsEndpointListReturnsValidationError(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant?sort=name", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(&mockProposalProvider{}, &mysteriumMorqaFake{}, nil, nil).List
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestProposalsEndpointListPaginates(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant?sort=price&limit=1", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(&mockProposalProvider{proposals: queryProposals}, &mysteriumMorqaFake{}, nil, nil).List
+	handlerFunc(resp, req, nil)
+
+	assert.JSONEq(
+		t,
+		`{
+			"proposals": [
+				{
+					"id": 0,
+					"providerId": "0x2",
+					"serviceType": "openvpn",
+					"serviceDefinition": {
+						"locationOriginate": {
+							"asn": "AS2",
+							"country": "LT",
+							"city": "Vilnius"
+						}
+					},
+					"paymentMethod": {
+						"type": "PER_TIME",
+						"price": {"amount": 100, "currency": "MYST"}
+					}
+				}
+			],
+			"page": 1,
+			"limit": 1,
+			"total": 3
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func toJSON(t *testing.T, value interface{}) string {
+	out, err := json.Marshal(value)
+	assert.NoError(t, err)
+	return string(out)
+}
@@ -22,8 +22,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/market/proposals/repository"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -66,7 +68,7 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(mockProposalProvider, &mysteriumMorqaFake{}).List
+	handlerFunc := NewProposalsEndpoint(mockProposalProvider, &mysteriumMorqaFake{}, nil, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -105,7 +107,7 @@ func TestProposalsEndpointList(t *testing.T) {
 	assert.Nil(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(proposalProvider, &mysteriumMorqaFake{}).List
+	handlerFunc := NewProposalsEndpoint(proposalProvider, &mysteriumMorqaFake{}, nil, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -154,7 +156,7 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 	assert.Nil(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(proposalProvider, &mysteriumMorqaFake{}).List
+	handlerFunc := NewProposalsEndpoint(proposalProvider, &mysteriumMorqaFake{}, nil, nil).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -199,6 +201,47 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointListFromStaleCache(t *testing.T) {
+	proposalProvider := &mockProposalCache{
+		mockProposalProvider: mockProposalProvider{proposals: []market.ServiceProposal{serviceProposals[0]}},
+		status: repository.Status{
+			UpdatedAt: time.Date(2019, 6, 6, 11, 4, 43, 0, time.UTC),
+			Age:       90 * time.Second,
+			Stale:     true,
+		},
+	}
+	req, err := http.NewRequest(http.MethodGet, "/irrelevant", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(proposalProvider, &mysteriumMorqaFake{}, nil, nil).List
+	handlerFunc(resp, req, nil)
+
+	assert.JSONEq(
+		t,
+		`{
+			"proposals": [
+				{
+					"id": 1,
+					"providerId": "0xProviderId",
+					"serviceType": "testprotocol",
+					"serviceDefinition": {
+						"locationOriginate": {
+							"asn": "LT",
+							"country": "Lithuania",
+							"city": "Vilnius"
+						}
+					}
+				}
+			],
+			"updatedAt": "2019-06-06T11:04:43Z",
+			"age": 90,
+			"stale": true
+		}`,
+		resp.Body.String(),
+	)
+}
+
 type mysteriumMorqaFake struct{}
 
 // ProposalsMetrics returns a list of proposals connection metrics
@@ -232,3 +275,12 @@ func (mpp *mockProposalProvider) FindProposals(providerID string, serviceType st
 }
 
 var _ ProposalProvider = &mockProposalProvider{}
+
+type mockProposalCache struct {
+	mockProposalProvider
+	status repository.Status
+}
+
+func (mpc *mockProposalCache) Status() repository.Status {
+	return mpc.status
+}
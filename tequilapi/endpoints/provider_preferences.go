@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/preferences"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+// ProviderFilter tells which providers consumer prefers or wants to avoid
+type ProviderFilter interface {
+	IsFavorite(providerID string) (bool, error)
+	IsBlocked(providerID string) (bool, error)
+}
+
+// ProviderPreferences manages favorite and blocked providers of consumer
+type ProviderPreferences interface {
+	ProviderFilter
+	Add(list preferences.List, providerID string) error
+	Remove(list preferences.List, providerID string) error
+	Entries(list preferences.List) ([]preferences.Entry, error)
+}
+
+// swagger:model ProviderPreferenceDTO
+type providerPreferenceRes struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"providerId"`
+
+	// example: 2019-06-06T11:04:43Z
+	AddedAt string `json:"addedAt"`
+}
+
+// swagger:model ProviderPreferenceList
+type providerPreferenceList struct {
+	Providers []providerPreferenceRes `json:"providers"`
+}
+
+var errUnknownProviderList = errors.New("unknown provider list, must be one of: favorite, blocked")
+
+type providerPreferencesEndpoint struct {
+	preferences ProviderPreferences
+}
+
+// NewProviderPreferencesEndpoint creates and returns favorite and blocked providers endpoint
+func NewProviderPreferencesEndpoint(providerPreferences ProviderPreferences) *providerPreferencesEndpoint {
+	return &providerPreferencesEndpoint{preferences: providerPreferences}
+}
+
+// swagger:operation GET /preferences/providers/{list} Preferences listProviderPreferences
+// ---
+// summary: Returns favorite or blocked providers
+// description: Returns providers of the given list ordered by time they were added
+// parameters:
+// - in: path
+//   name: list
+//   description: provider list, one of favorite, blocked
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: List of providers
+//     schema:
+//       "$ref": "#/definitions/ProviderPreferenceList"
+//   404:
+//     description: Unknown provider list
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *providerPreferencesEndpoint) List(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	list, ok := toProviderList(params)
+	if !ok {
+		utils.SendError(resp, errUnknownProviderList, http.StatusNotFound)
+		return
+	}
+
+	entries, err := endpoint.preferences.Entries(list)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	result := providerPreferenceList{Providers: make([]providerPreferenceRes, len(entries))}
+	for i, entry := range entries {
+		result.Providers[i] = providerPreferenceRes{
+			ProviderID: entry.ProviderID,
+			AddedAt:    entry.Added.UTC().Format(time.RFC3339),
+		}
+	}
+	utils.WriteAsJSON(result, resp)
+}
+
+// swagger:operation PUT /preferences/providers/{list}/{id} Preferences addProviderPreference
+// ---
+// summary: Adds provider to favorite or blocked providers
+// description: Adds provider to the given list. Provider is removed from the other list, if it was there.
+// parameters:
+// - in: path
+//   name: list
+//   description: provider list, one of favorite, blocked
+//   type: string
+//   required: true
+// - in: path
+//   name: id
+//   description: provider identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Provider added
+//   400:
+//     description: Invalid provider identity
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Unknown provider list
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *providerPreferencesEndpoint) Add(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	list, ok := toProviderList(params)
+	if !ok {
+		utils.SendError(resp, errUnknownProviderList, http.StatusNotFound)
+		return
+	}
+
+	providerID := params.ByName("id")
+	if !common.IsHexAddress(providerID) {
+		utils.SendErrorMessage(resp, "Invalid provider identity", http.StatusBadRequest)
+		return
+	}
+
+	if err := endpoint.preferences.Add(list, providerID); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// swagger:operation DELETE /preferences/providers/{list}/{id} Preferences removeProviderPreference
+// ---
+// summary: Removes provider from favorite or blocked providers
+// description: Removes provider from the given list
+// parameters:
+// - in: path
+//   name: list
+//   description: provider list, one of favorite, blocked
+//   type: string
+//   required: true
+// - in: path
+//   name: id
+//   description: provider identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Provider removed
+//   404:
+//     description: Unknown provider list or provider is not in the list
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *providerPreferencesEndpoint) Remove(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	list, ok := toProviderList(params)
+	if !ok {
+		utils.SendError(resp, errUnknownProviderList, http.StatusNotFound)
+		return
+	}
+
+	err := endpoint.preferences.Remove(list, params.ByName("id"))
+	switch err {
+	case nil:
+		resp.WriteHeader(http.StatusAccepted)
+	case preferences.ErrNotInList:
+		utils.SendError(resp, err, http.StatusNotFound)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+func toProviderList(params httprouter.Params) (preferences.List, bool) {
+	list := preferences.List(params.ByName("list"))
+	return list, list == preferences.Favorite || list == preferences.Blocked
+}
+
+// AddRoutesForProviderPreferences attaches favorite and blocked providers endpoints to router
+func AddRoutesForProviderPreferences(router *httprouter.Router, providerPreferences ProviderPreferences) {
+	endpoint := NewProviderPreferencesEndpoint(providerPreferences)
+	router.GET("/preferences/providers/:list", endpoint.List)
+	router.PUT("/preferences/providers/:list/:id", endpoint.Add)
+	router.DELETE("/preferences/providers/:list/:id", endpoint.Remove)
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/preferences"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderPreferencesList(t *testing.T) {
+	added := time.Date(2019, 6, 6, 11, 4, 43, 0, time.UTC)
+	fake := &providerPreferencesFake{
+		entries: []preferences.Entry{
+			{ProviderID: "0x1", List: preferences.Favorite, Added: added},
+			{ProviderID: "0x2", List: preferences.Blocked, Added: added},
+		},
+	}
+	router := httprouter.New()
+	AddRoutesForProviderPreferences(router, fake)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/preferences/providers/blocked", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{"providers": [{"providerId": "0x2", "addedAt": "2019-06-06T11:04:43Z"}]}`,
+		resp.Body.String(),
+	)
+}
+
+func TestProviderPreferencesAddAndRemove(t *testing.T) {
+	const providerID = "0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68"
+	fake := &providerPreferencesFake{}
+	router := httprouter.New()
+	AddRoutesForProviderPreferences(router, fake)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/preferences/providers/favorite/"+providerID, nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.True(t, fake.inList(preferences.Favorite, providerID))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/preferences/providers/favorite/"+providerID, nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.False(t, fake.inList(preferences.Favorite, providerID))
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/preferences/providers/favorite/"+providerID, nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.JSONEq(t, `{"message": "provider is not in the list"}`, resp.Body.String())
+}
+
+func TestProviderPreferencesAddRejectsInvalidIdentity(t *testing.T) {
+	fake := &providerPreferencesFake{}
+	router := httprouter.New()
+	AddRoutesForProviderPreferences(router, fake)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/preferences/providers/blocked/not-an-address", nil))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.JSONEq(t, `{"message": "Invalid provider identity"}`, resp.Body.String())
+	assert.Len(t, fake.entries, 0)
+}
+
+func TestProviderPreferencesRejectsUnknownList(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForProviderPreferences(router, &providerPreferencesFake{})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/preferences/providers/liked/0x1", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestProposalsEndpointListSkipsBlockedProviders(t *testing.T) {
+	filter := &providerFilterFake{
+		favorite: map[string]bool{"0x1": true},
+		blocked:  map[string]bool{"0x2": true},
+	}
+	endpoint := NewProposalsEndpoint(&mockProposalProvider{proposals: queryProposals}, &mysteriumMorqaFake{}, nil, filter)
+
+	resp := httptest.NewRecorder()
+	endpoint.List(resp, httptest.NewRequest(http.MethodGet, "/irrelevant", nil), nil)
+	assert.Equal(t, []string{"0x1", "0x3"}, listedProviders(t, resp))
+	assert.Contains(t, resp.Body.String(), `"favorite":true`)
+
+	resp = httptest.NewRecorder()
+	endpoint.List(resp, httptest.NewRequest(http.MethodGet, "/irrelevant?favorite=true", nil), nil)
+	assert.Equal(t, []string{"0x1"}, listedProviders(t, resp))
+}
+
+func TestProposalsEndpointListFailsWhenPreferencesCannotBeLoaded(t *testing.T) {
+	filter := &providerFilterFake{err: errors.New("db closed")}
+	endpoint := NewProposalsEndpoint(&mockProposalProvider{proposals: queryProposals}, &mysteriumMorqaFake{}, nil, filter)
+
+	resp := httptest.NewRecorder()
+	endpoint.List(resp, httptest.NewRequest(http.MethodGet, "/irrelevant", nil), nil)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"message": "db closed"}`, resp.Body.String())
+}
+
+func listedProviders(t *testing.T, resp *httptest.ResponseRecorder) []string {
+	var result proposalsRes
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+
+	providers := make([]string, len(result.Proposals))
+	for i, proposal := range result.Proposals {
+		providers[i] = proposal.ProviderID
+	}
+	return providers
+}
+
+type providerFilterFake struct {
+	favorite map[string]bool
+	blocked  map[string]bool
+	err      error
+}
+
+func (fake *providerFilterFake) IsFavorite(providerID string) (bool, error) {
+	return fake.favorite[providerID], fake.err
+}
+
+func (fake *providerFilterFake) IsBlocked(providerID string) (bool, error) {
+	return fake.blocked[providerID], fake.err
+}
+
+type providerPreferencesFake struct {
+	entries []preferences.Entry
+}
+
+func (fake *providerPreferencesFake) Add(list preferences.List, providerID string) error {
+	fake.entries = append(fake.entries, preferences.Entry{ProviderID: providerID, List: list})
+	return nil
+}
+
+func (fake *providerPreferencesFake) Remove(list preferences.List, providerID string) error {
+	for i, entry := range fake.entries {
+		if entry.ProviderID == providerID && entry.List == list {
+			fake.entries = append(fake.entries[:i], fake.entries[i+1:]...)
+			return nil
+		}
+	}
+	return preferences.ErrNotInList
+}
+
+func (fake *providerPreferencesFake) Entries(list preferences.List) ([]preferences.Entry, error) {
+	var entries []preferences.Entry
+	for _, entry := range fake.entries {
+		if entry.List == list {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (fake *providerPreferencesFake) IsFavorite(providerID string) (bool, error) {
+	return fake.inList(preferences.Favorite, providerID), nil
+}
+
+func (fake *providerPreferencesFake) IsBlocked(providerID string) (bool, error) {
+	return fake.inList(preferences.Blocked, providerID), nil
+}
+
+func (fake *providerPreferencesFake) inList(list preferences.List, providerID string) bool {
+	for _, entry := range fake.entries {
+		if entry.ProviderID == providerID && entry.List == list {
+			return true
+		}
+	}
+	return false
+}
+
+var _ ProviderPreferences = &providerPreferencesFake{}
@@ -0,0 +1,285 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// ServiceProposalPublisher publishes proposals of running services
+type ServiceProposalPublisher interface {
+	Proposals(serviceType string) ([]market.ServiceProposal, error)
+	PublishProposal(serviceType string, proposal market.ServiceProposal) (int, error)
+	UpdateProposal(serviceType string, proposal market.ServiceProposal) error
+	UnpublishProposal(serviceType string, proposalID int) error
+}
+
+// swagger:model ServiceProposalIDDTO
+type serviceProposalIDRes struct {
+	// example: 2
+	ID int `json:"id"`
+}
+
+type serviceProposalsEndpoint struct {
+	publisher ServiceProposalPublisher
+}
+
+// NewServiceProposalsEndpoint creates and returns endpoint which manages proposals of running services
+func NewServiceProposalsEndpoint(publisher ServiceProposalPublisher) *serviceProposalsEndpoint {
+	return &serviceProposalsEndpoint{publisher: publisher}
+}
+
+// swagger:operation GET /services/{type}/proposals Service listServiceProposals
+// ---
+// summary: Returns proposals published by the service
+// description: Returns all variants of the proposal currently published by the running service
+// parameters:
+// - in: path
+//   name: type
+//   description: service type
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: List of published proposals
+//     schema:
+//       "$ref": "#/definitions/ProposalsList"
+//   404:
+//     description: Unknown service type
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *serviceProposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	proposals, err := endpoint.publisher.Proposals(params.ByName("type"))
+	if err != nil {
+		sendServiceProposalError(resp, err)
+		return
+	}
+
+	result := proposalsRes{Proposals: make([]proposalRes, len(proposals))}
+	for i, proposal := range proposals {
+		result.Proposals[i] = proposalToRes(proposal)
+	}
+	utils.WriteAsJSON(result, resp)
+}
+
+// swagger:operation POST /services/{type}/proposals Service publishServiceProposal
+// ---
+// summary: Publishes new variant of the service proposal
+// description: Announces given proposal of the running service under newly generated ID
+// parameters:
+// - in: path
+//   name: type
+//   description: service type
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Proposal in the format it is announced to discovery, provider is filled in by the node
+//   schema:
+//     type: object
+// responses:
+//   201:
+//     description: Proposal published
+//     schema:
+//       "$ref": "#/definitions/ServiceProposalIDDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Unknown service type
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   409:
+//     description: Service is not started
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (endpoint *serviceProposalsEndpoint) Publish(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	serviceType := params.ByName("type")
+	proposal, ok := parseServiceProposal(resp, req, serviceType)
+	if !ok {
+		return
+	}
+
+	id, err := endpoint.publisher.PublishProposal(serviceType, proposal)
+	if err != nil {
+		sendServiceProposalError(resp, err)
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json; charset=utf-8")
+	resp.WriteHeader(http.StatusCreated)
+	utils.WriteAsJSON(serviceProposalIDRes{ID: id}, resp)
+}
+
+// swagger:operation PUT /services/{type}/proposals/{id} Service updateServiceProposal
+// ---
+// summary: Updates published proposal of the service
+// description: Re-publishes modified proposal under the same ID, existing sessions are kept
+// parameters:
+// - in: path
+//   name: type
+//   description: service type
+//   type: string
+//   required: true
+// - in: path
+//   name: id
+//   description: proposal ID
+//   type: integer
+//   required: true
+// - in: body
+//   name: body
+//   description: Proposal in the format it is announced to discovery, provider is filled in by the node
+//   schema:
+//     type: object
+// responses:
+//   202:
+//     description: Proposal updated
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Unknown service type or proposal
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *serviceProposalsEndpoint) Update(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	proposalID, ok := parseServiceProposalID(resp, params)
+	if !ok {
+		return
+	}
+	serviceType := params.ByName("type")
+	proposal, ok := parseServiceProposal(resp, req, serviceType)
+	if !ok {
+		return
+	}
+
+	proposal.ID = proposalID
+	if err := endpoint.publisher.UpdateProposal(serviceType, proposal); err != nil {
+		sendServiceProposalError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// swagger:operation DELETE /services/{type}/proposals/{id} Service unpublishServiceProposal
+// ---
+// summary: Stops announcing proposal of the service
+// description: Unregisters proposal from discovery, new sessions for it are not accepted anymore
+// parameters:
+// - in: path
+//   name: type
+//   description: service type
+//   type: string
+//   required: true
+// - in: path
+//   name: id
+//   description: proposal ID
+//   type: integer
+//   required: true
+// responses:
+//   202:
+//     description: Proposal unpublished
+//   404:
+//     description: Unknown service type or proposal
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *serviceProposalsEndpoint) Unpublish(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	proposalID, ok := parseServiceProposalID(resp, params)
+	if !ok {
+		return
+	}
+
+	if err := endpoint.publisher.UnpublishProposal(params.ByName("type"), proposalID); err != nil {
+		sendServiceProposalError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+func parseServiceProposalID(resp http.ResponseWriter, params httprouter.Params) (int, bool) {
+	proposalID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		utils.SendError(resp, service.ErrProposalNotFound, http.StatusNotFound)
+		return 0, false
+	}
+	return proposalID, true
+}
+
+// parseServiceProposal decodes proposal of the given service type, service definition and payment method must be supported
+func parseServiceProposal(resp http.ResponseWriter, req *http.Request, serviceType string) (proposal market.ServiceProposal, ok bool) {
+	if err := json.NewDecoder(req.Body).Decode(&proposal); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return proposal, false
+	}
+
+	errorMap := validation.NewErrorMap()
+	if proposal.ServiceType != serviceType {
+		errorMap.ForField("service_type").AddError("invalid", "Must match service type "+serviceType)
+	}
+	if _, unsupported := proposal.ServiceDefinition.(market.UnsupportedServiceDefinition); unsupported {
+		errorMap.ForField("service_definition").AddError("unsupported", "Service definition is not supported")
+	}
+	if _, unsupported := proposal.PaymentMethod.(market.UnsupportedPaymentMethod); unsupported {
+		errorMap.ForField("payment_method").AddError("unsupported", "Payment method is not supported")
+	}
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return proposal, false
+	}
+	return proposal, true
+}
+
+func sendServiceProposalError(resp http.ResponseWriter, err error) {
+	switch err {
+	case service.ErrUnsupportedServiceType, service.ErrProposalNotFound:
+		utils.SendError(resp, err, http.StatusNotFound)
+	case service.ErrServiceNotStarted:
+		utils.SendError(resp, err, http.StatusConflict)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+// AddRoutesForServiceProposals attaches endpoints which manage proposals of running services to router
+func AddRoutesForServiceProposals(router *httprouter.Router, publisher ServiceProposalPublisher) {
+	endpoint := NewServiceProposalsEndpoint(publisher)
+	router.GET("/services/:type/proposals", endpoint.List)
+	router.POST("/services/:type/proposals", endpoint.Publish)
+	router.PUT("/services/:type/proposals/:id", endpoint.Update)
+	router.DELETE("/services/:type/proposals/:id", endpoint.Unpublish)
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	market.RegisterServiceDefinitionUnserializer("testprotocol", func(*json.RawMessage) (market.ServiceDefinition, error) {
+		return TestServiceDefinition{}, nil
+	})
+	market.RegisterPaymentMethodUnserializer("test_payment", func(*json.RawMessage) (market.PaymentMethod, error) {
+		return testPaymentMethod{amount: 10}, nil
+	})
+}
+
+const testServiceProposalJSON = `{
+	"service_type": "testprotocol",
+	"service_definition": {},
+	"payment_method_type": "test_payment",
+	"payment_method": {}
+}`
+
+func TestServiceProposalsEndpoint_List(t *testing.T) {
+	publisher := &serviceProposalPublisherFake{proposals: []market.ServiceProposal{serviceProposals[0]}}
+
+	resp := serveServiceProposals(publisher, http.MethodGet, "/services/testprotocol/proposals", "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "testprotocol", publisher.lastServiceType)
+	assert.JSONEq(
+		t,
+		`{
+			"proposals": [{
+				"id": 1,
+				"providerId": "0xProviderId",
+				"serviceType": "testprotocol",
+				"serviceDefinition": {
+					"locationOriginate": {"asn": "LT", "country": "Lithuania", "city": "Vilnius"}
+				}
+			}]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestServiceProposalsEndpoint_Publish(t *testing.T) {
+	publisher := &serviceProposalPublisherFake{publishedID: 2}
+
+	resp := serveServiceProposals(publisher, http.MethodPost, "/services/testprotocol/proposals", testServiceProposalJSON)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id": 2}`, resp.Body.String())
+	assert.Equal(t, TestServiceDefinition{}, publisher.lastProposal.ServiceDefinition)
+	assert.Equal(t, testPaymentMethod{amount: 10}, publisher.lastProposal.PaymentMethod)
+}
+
+func TestServiceProposalsEndpoint_PublishValidatesProposal(t *testing.T) {
+	publisher := &serviceProposalPublisherFake{}
+
+	resp := serveServiceProposals(publisher, http.MethodPost, "/services/openvpn/proposals", `{
+		"service_type": "testprotocol",
+		"service_definition": {},
+		"payment_method_type": "unknown_payment",
+		"payment_method": {}
+	}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"service_type": [{"code": "invalid", "message": "Must match service type openvpn"}],
+				"payment_method": [{"code": "unsupported", "message": "Payment method is not supported"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+	assert.Empty(t, publisher.lastServiceType)
+}
+
+func TestServiceProposalsEndpoint_PublishBeforeServiceIsStarted(t *testing.T) {
+	publisher := &serviceProposalPublisherFake{err: service.ErrServiceNotStarted}
+
+	resp := serveServiceProposals(publisher, http.MethodPost, "/services/testprotocol/proposals", testServiceProposalJSON)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"message": "service is not started"}`, resp.Body.String())
+}
+
+func TestServiceProposalsEndpoint_Update(t *testing.T) {
+	publisher := &serviceProposalPublisherFake{}
+
+	resp := serveServiceProposals(publisher, http.MethodPut, "/services/testprotocol/proposals/3", testServiceProposalJSON)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, 3, publisher.lastProposal.ID)
+	assert.Equal(t, "testprotocol", publisher.lastServiceType)
+}
+
+func TestServiceProposalsEndpoint_UpdateUnknownProposal(t *testing.T) {
+	publisher := &serviceProposalPublisherFake{}
+
+	resp := serveServiceProposals(publisher, http.MethodPut, "/services/testprotocol/proposals/first", testServiceProposalJSON)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.JSONEq(t, `{"message": "proposal not found"}`, resp.Body.String())
+}
+
+func TestServiceProposalsEndpoint_Unpublish(t *testing.T) {
+	publisher := &serviceProposalPublisherFake{}
+
+	resp := serveServiceProposals(publisher, http.MethodDelete, "/services/testprotocol/proposals/3", "")
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, 3, publisher.lastProposalID)
+}
+
+func TestServiceProposalsEndpoint_UnknownServiceType(t *testing.T) {
+	publisher := &serviceProposalPublisherFake{err: service.ErrUnsupportedServiceType}
+
+	resp := serveServiceProposals(publisher, http.MethodDelete, "/services/unknown/proposals/3", "")
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.JSONEq(t, `{"message": "unsupported service type"}`, resp.Body.String())
+}
+
+func serveServiceProposals(publisher ServiceProposalPublisher, method, path, body string) *httptest.ResponseRecorder {
+	router := httprouter.New()
+	AddRoutesForServiceProposals(router, publisher)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+type serviceProposalPublisherFake struct {
+	proposals   []market.ServiceProposal
+	publishedID int
+	err         error
+
+	lastServiceType string
+	lastProposal    market.ServiceProposal
+	lastProposalID  int
+}
+
+func (publisher *serviceProposalPublisherFake) Proposals(serviceType string) ([]market.ServiceProposal, error) {
+	publisher.lastServiceType = serviceType
+	return publisher.proposals, publisher.err
+}
+
+func (publisher *serviceProposalPublisherFake) PublishProposal(serviceType string, proposal market.ServiceProposal) (int, error) {
+	publisher.lastServiceType = serviceType
+	publisher.lastProposal = proposal
+	return publisher.publishedID, publisher.err
+}
+
+func (publisher *serviceProposalPublisherFake) UpdateProposal(serviceType string, proposal market.ServiceProposal) error {
+	publisher.lastServiceType = serviceType
+	publisher.lastProposal = proposal
+	return publisher.err
+}
+
+func (publisher *serviceProposalPublisherFake) UnpublishProposal(serviceType string, proposalID int) error {
+	publisher.lastServiceType = serviceType
+	publisher.lastProposalID = proposalID
+	return publisher.err
+}
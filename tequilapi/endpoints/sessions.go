@@ -30,6 +30,42 @@ import (
 // swagger:model SessionsDTO
 type SessionsDTO struct {
 	Sessions []SessionDTO `json:"sessions"`
+
+	// current page number, set when limit is given
+	// example: 1
+	Page int `json:"page,omitempty"`
+
+	// sessions per page, set when limit is given
+	// example: 20
+	Limit int `json:"limit,omitempty"`
+
+	// count of all sessions matching the query
+	// example: 150
+	Total int `json:"total"`
+
+	// totals of all sessions matching the query
+	Stats SessionStatsDTO `json:"stats"`
+}
+
+// SessionStatsDTO represents aggregated totals of sessions
+// swagger:model SessionStatsDTO
+type SessionStatsDTO struct {
+	// example: 150
+	Count int `json:"count"`
+
+	// example: 1024
+	BytesSent uint64 `json:"bytesSent"`
+
+	// example: 1024
+	BytesReceived uint64 `json:"bytesReceived"`
+
+	// duration of completed sessions in seconds
+	// example: 120
+	Duration uint64 `json:"duration"`
+
+	// count of sessions per provider country
+	// example: {"NL": 100, "LT": 50}
+	CountryCounts map[string]int `json:"countryCounts"`
 }
 
 // SessionDTO represents the session object
@@ -69,7 +105,7 @@ type sessionsEndpoint struct {
 }
 
 type sessionStorageGet interface {
-	GetAll() ([]session.History, error)
+	Query(query session.Query) (session.QueryResult, error)
 }
 
 // NewSessionsEndpoint creates and returns sessions endpoint
@@ -82,23 +118,74 @@ func NewSessionsEndpoint(sessionStorage sessionStorageGet) *sessionsEndpoint {
 // swagger:operation GET /sessions Session listSessions
 // ---
 // summary: Returns sessions history
-// description: Returns list of sessions history
+// description: Returns list of sessions history filtered, sorted by start date and paginated by given parameters
+// parameters:
+//   - in: query
+//     name: from
+//     description: RFC3339 date, returns sessions started at or after it
+//     type: string
+//   - in: query
+//     name: to
+//     description: RFC3339 date, returns sessions started at or before it
+//     type: string
+//   - in: query
+//     name: providerId
+//     description: id of sessions provider
+//     type: string
+//   - in: query
+//     name: serviceType
+//     description: the service type of sessions
+//     type: string
+//   - in: query
+//     name: status
+//     description: the status of sessions, one of New or Completed
+//     type: string
+//   - in: query
+//     name: order
+//     description: sorting order by start date, one of asc or desc (default)
+//     type: string
+//   - in: query
+//     name: page
+//     description: page number, starting from 1
+//     type: integer
+//   - in: query
+//     name: limit
+//     description: count of sessions per page (at most 1000), all sessions are returned if not given
+//     type: integer
 // responses:
 //   200:
 //     description: List of sessions
 //     schema:
 //       "$ref": "#/definitions/SessionsDTO"
+//   400:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
 //       "$ref": "#/definitions/ErrorMessageDTO"
 func (endpoint *sessionsEndpoint) List(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	sessions, err := endpoint.sessionStorage.GetAll()
+	query, errorMap := parseSessionsQuery(request.URL.Query())
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	result, err := endpoint.sessionStorage.Query(query)
 	if err != nil {
 		utils.SendError(resp, err, http.StatusInternalServerError)
 		return
 	}
-	sessionsSerializable := SessionsDTO{Sessions: mapSessions(sessions, toHistoryView)}
+	sessionsSerializable := SessionsDTO{
+		Sessions: mapSessions(result.Sessions, toHistoryView),
+		Total:    result.Total,
+		Stats:    toStatsView(result.Stats),
+	}
+	if query.Limit > 0 {
+		sessionsSerializable.Page = query.Page
+		sessionsSerializable.Limit = query.Limit
+	}
 	utils.WriteAsJSON(sessionsSerializable, resp)
 }
 
@@ -122,6 +209,16 @@ func toHistoryView(se session.History) SessionDTO {
 	}
 }
 
+func toStatsView(stats session.Stats) SessionStatsDTO {
+	return SessionStatsDTO{
+		Count:         stats.Count,
+		BytesSent:     stats.BytesSent,
+		BytesReceived: stats.BytesReceived,
+		Duration:      stats.Duration,
+		CountryCounts: stats.CountryCounts,
+	}
+}
+
 func mapSessions(sessions []session.History, f func(session.History) SessionDTO) []SessionDTO {
 	dtoArray := make([]SessionDTO, len(sessions))
 	for i, se := range sessions {
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+const (
+	orderAscending  = "asc"
+	orderDescending = "desc"
+
+	// maxQueryLimit is the largest page size accepted by listing endpoints
+	maxQueryLimit = 1000
+)
+
+func parseSessionsQuery(values url.Values) (session.Query, *validation.FieldErrorMap) {
+	errors := validation.NewErrorMap()
+	query := session.Query{
+		ProviderID:  values.Get("providerId"),
+		ServiceType: values.Get("serviceType"),
+		Status:      values.Get("status"),
+		Page:        1,
+	}
+
+	if value := values.Get("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errors.ForField("from").AddError("invalid", "Must be a RFC3339 date")
+		}
+		query.From = from
+	}
+	if value := values.Get("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errors.ForField("to").AddError("invalid", "Must be a RFC3339 date")
+		}
+		query.To = to
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.To.Before(query.From) {
+		errors.ForField("to").AddError("invalid", "Must not be before 'from'")
+	}
+	switch values.Get("order") {
+	case "", orderDescending:
+	case orderAscending:
+		query.Ascending = true
+	default:
+		errors.ForField("order").AddError("invalid", "Must be one of: asc, desc")
+	}
+	if value := values.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			errors.ForField("page").AddError("invalid", "Must be a positive integer")
+		}
+		query.Page = page
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxQueryLimit {
+			errors.ForField("limit").AddError("invalid", fmt.Sprintf("Must be an integer between 1 and %d", maxQueryLimit))
+		}
+		query.Limit = limit
+	}
+
+	return query, errors
+}
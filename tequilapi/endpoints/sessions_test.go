@@ -96,6 +96,88 @@ func TestListEndpoint(t *testing.T) {
 	err = json.Unmarshal(resp.Body.Bytes(), parsedResponse)
 	assert.Nil(t, err)
 	assert.EqualValues(t, toHistoryView(SessionMock), parsedResponse.Sessions[0])
+	assert.Equal(t, 1, parsedResponse.Total)
+	assert.Equal(t, session.Query{Page: 1}, ssm.lastQuery)
+}
+
+func TestListEndpointPassesQuery(t *testing.T) {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/irrelevant?from=2019-06-01T00:00:00Z&serviceType=openvpn&status=Completed&order=asc&page=2&limit=10",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	ssm := &sessionStorageMock{
+		sessionsToReturn: []session.History{SessionMock},
+		statsToReturn: session.Stats{
+			Count:         15,
+			BytesSent:     1,
+			BytesReceived: 2,
+			Duration:      3,
+			CountryCounts: map[string]int{"NL": 15},
+		},
+		totalToReturn: 15,
+	}
+
+	resp := httptest.NewRecorder()
+	NewSessionsEndpoint(ssm).List(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t,
+		session.Query{
+			From:        time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+			ServiceType: "openvpn",
+			Status:      "Completed",
+			Ascending:   true,
+			Page:        2,
+			Limit:       10,
+		},
+		ssm.lastQuery,
+	)
+
+	parsedResponse := &SessionsDTO{}
+	err = json.Unmarshal(resp.Body.Bytes(), parsedResponse)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, parsedResponse.Page)
+	assert.Equal(t, 10, parsedResponse.Limit)
+	assert.Equal(t, 15, parsedResponse.Total)
+	assert.Equal(t,
+		SessionStatsDTO{
+			Count:         15,
+			BytesSent:     1,
+			BytesReceived: 2,
+			Duration:      3,
+			CountryCounts: map[string]int{"NL": 15},
+		},
+		parsedResponse.Stats,
+	)
+}
+
+func TestListEndpointValidatesQuery(t *testing.T) {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/irrelevant?from=yesterday&order=random&limit=0",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	NewSessionsEndpoint(&sessionStorageMock{}).List(resp, req, nil)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"from": [{"code": "invalid", "message": "Must be a RFC3339 date"}],
+				"order": [{"code": "invalid", "message": "Must be one of: asc, desc"}],
+				"limit": [{"code": "invalid", "message": "Must be an integer between 1 and 1000"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
 }
 
 func TestListEndpointBubblesError(t *testing.T) {
@@ -125,9 +207,21 @@ func TestListEndpointBubblesError(t *testing.T) {
 
 type sessionStorageMock struct {
 	sessionsToReturn []session.History
+	statsToReturn    session.Stats
+	totalToReturn    int
 	errToReturn      error
+	lastQuery        session.Query
 }
 
-func (ssm *sessionStorageMock) GetAll() ([]session.History, error) {
-	return ssm.sessionsToReturn, ssm.errToReturn
+func (ssm *sessionStorageMock) Query(query session.Query) (session.QueryResult, error) {
+	ssm.lastQuery = query
+	total := ssm.totalToReturn
+	if total == 0 {
+		total = len(ssm.sessionsToReturn)
+	}
+	return session.QueryResult{
+		Sessions: ssm.sessionsToReturn,
+		Total:    total,
+		Stats:    ssm.statsToReturn,
+	}, ssm.errToReturn
 }
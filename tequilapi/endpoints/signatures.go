@@ -0,0 +1,217 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// swagger:model SignatureRequestDTO
+type signatureRequest struct {
+	// identity to sign message with, it has to be unlocked
+	// required: true
+	// example: 0x0000000000000000000000000000000000000001
+	Identity string `json:"identity"`
+
+	// message to sign
+	// required: true
+	// example: link to account 42
+	Message *string `json:"message"`
+}
+
+// swagger:model SignatureDTO
+type signatureResponse struct {
+	// signature of the prefixed message in base64 format
+	// example: V6ifmvLuAT+hbtLBX/0xm3C0afywxTIdw1HqLmA4onpwmibHbxVhl50Gr3aRUZMqw1WxkfSIVdhpbCluHGBKsgE=
+	Signature string `json:"signature"`
+}
+
+// swagger:model SignatureVerificationRequestDTO
+type signatureVerificationRequest struct {
+	// signed message
+	// required: true
+	// example: link to account 42
+	Message *string `json:"message"`
+
+	// signature in base64 format
+	// required: true
+	// example: V6ifmvLuAT+hbtLBX/0xm3C0afywxTIdw1HqLmA4onpwmibHbxVhl50Gr3aRUZMqw1WxkfSIVdhpbCluHGBKsgE=
+	Signature string `json:"signature"`
+}
+
+type signaturesAPI struct {
+	idm           identity.Manager
+	signerFactory identity.SignerFactory
+	extractor     identity.Extractor
+}
+
+// NewSignaturesEndpoint creates signatures api controller used by tequilapi service
+func NewSignaturesEndpoint(idm identity.Manager, signerFactory identity.SignerFactory, extractor identity.Extractor) *signaturesAPI {
+	return &signaturesAPI{
+		idm:           idm,
+		signerFactory: signerFactory,
+		extractor:     extractor,
+	}
+}
+
+// swagger:operation POST /signatures Signature signMessage
+// ---
+// summary: Signs message
+// description: Signs arbitrary message with unlocked identity. Message is prefixed before signing, so the signature can not be used as a signature of protocol messages
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (identity, message) required for signing
+//     schema:
+//       $ref: "#/definitions/SignatureRequestDTO"
+// responses:
+//   200:
+//     description: Message signed
+//     schema:
+//       "$ref": "#/definitions/SignatureDTO"
+//   400:
+//     description: Bad Request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   403:
+//     description: Identity is locked
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *signaturesAPI) Sign(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	signReq := signatureRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&signReq); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	errorMap := validation.NewErrorMap()
+	if signReq.Identity == "" {
+		errorMap.ForField("identity").AddError("required", "Field is required")
+	}
+	if signReq.Message == nil {
+		errorMap.ForField("message").AddError("required", "Field is required")
+	}
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	id, err := endpoint.idm.GetIdentity(signReq.Identity)
+	if err != nil {
+		utils.SendErrorMessage(resp, "Identity not found", http.StatusNotFound)
+		return
+	}
+
+	signature, err := identity.SignMessage(endpoint.signerFactory(id), []byte(*signReq.Message))
+	if err == identity.ErrIdentityLocked {
+		utils.SendError(resp, err, http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(signatureResponse{Signature: signature.Base64()}, resp)
+}
+
+// swagger:operation POST /signatures/verify Signature verifySignature
+// ---
+// summary: Verifies signature
+// description: Recovers identity which signed the message with POST /signatures
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (message, signature) required for verification
+//     schema:
+//       $ref: "#/definitions/SignatureVerificationRequestDTO"
+// responses:
+//   200:
+//     description: Identity which signed the message
+//     schema:
+//       "$ref": "#/definitions/IdentityDTO"
+//   400:
+//     description: Bad Request
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+//   422:
+//     description: Parameters validation error or invalid signature
+//     schema:
+//       "$ref": "#/definitions/ValidationErrorDTO"
+func (endpoint *signaturesAPI) Verify(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	verifyReq := signatureVerificationRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&verifyReq); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	errorMap := validation.NewErrorMap()
+	if verifyReq.Message == nil {
+		errorMap.ForField("message").AddError("required", "Field is required")
+	}
+	if verifyReq.Signature == "" {
+		errorMap.ForField("signature").AddError("required", "Field is required")
+	}
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	signer, err := identity.ExtractMessageSigner(
+		endpoint.extractor,
+		[]byte(*verifyReq.Message),
+		identity.SignatureBase64(verifyReq.Signature),
+	)
+	if err != nil {
+		errorMap.ForField("signature").AddError("invalid", err.Error())
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	utils.WriteAsJSON(idToDto(signer), resp)
+}
+
+// AddRoutesForSignatures creates /signatures endpoints on tequilapi service
+func AddRoutesForSignatures(
+	router *httprouter.Router,
+	idm identity.Manager,
+	signerFactory identity.SignerFactory,
+	extractor identity.Extractor,
+) {
+	endpoint := NewSignaturesEndpoint(idm, signerFactory, extractor)
+	router.POST("/signatures", endpoint.Sign)
+	router.POST("/signatures/verify", endpoint.Verify)
+}
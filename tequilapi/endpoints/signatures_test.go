@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeExtractor struct {
+	message   []byte
+	signature identity.Signature
+	err       error
+}
+
+func (extractor *fakeExtractor) Extract(message []byte, signature identity.Signature) (identity.Identity, error) {
+	extractor.message = message
+	extractor.signature = signature
+	return existingIdentities[0], extractor.err
+}
+
+func TestSignMessage(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	signerFactory := func(id identity.Identity) identity.Signer { return &identity.SignerFake{} }
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/signatures",
+		bytes.NewBufferString(`{"identity": "0x000000000000000000000000000000000000000a", "message": "hi"}`),
+	)
+	resp := httptest.NewRecorder()
+
+	NewSignaturesEndpoint(mockIdm, signerFactory, nil).Sign(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	expected := identity.SignatureBytes(append([]byte("signed"), identity.PrefixMessage([]byte("hi"))...))
+	assert.JSONEq(t, `{"signature": "`+expected.Base64()+`"}`, resp.Body.String())
+}
+
+func TestSignMessageWithLockedIdentity(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{ErrorMock: identity.ErrIdentityLocked}
+	}
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/signatures",
+		bytes.NewBufferString(`{"identity": "0x000000000000000000000000000000000000000a", "message": "hi"}`),
+	)
+	resp := httptest.NewRecorder()
+
+	NewSignaturesEndpoint(mockIdm, signerFactory, nil).Sign(resp, req, nil)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestSignMessageWithUnknownIdentity(t *testing.T) {
+	mockIdm := identity.NewIdentityManagerFake(existingIdentities, newIdentity)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/signatures",
+		bytes.NewBufferString(`{"identity": "0x0000000000000000000000000000000000000009", "message": "hi"}`),
+	)
+	resp := httptest.NewRecorder()
+
+	NewSignaturesEndpoint(mockIdm, fakeSignerFactory, nil).Sign(resp, req, nil)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestVerifySignature(t *testing.T) {
+	extractor := &fakeExtractor{}
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/signatures/verify",
+		bytes.NewBufferString(`{"message": "hi", "signature": "c2lnbmVk"}`),
+	)
+	resp := httptest.NewRecorder()
+
+	NewSignaturesEndpoint(nil, nil, extractor).Verify(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"id": "0x000000000000000000000000000000000000000a"}`, resp.Body.String())
+	assert.Equal(t, identity.PrefixMessage([]byte("hi")), extractor.message)
+	assert.Equal(t, identity.SignatureBase64("c2lnbmVk"), extractor.signature)
+}
+
+func TestVerifyInvalidSignature(t *testing.T) {
+	extractor := &fakeExtractor{err: errors.New("invalid signature length")}
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/signatures/verify",
+		bytes.NewBufferString(`{"message": "hi", "signature": "c2lnbmVk"}`),
+	)
+	resp := httptest.NewRecorder()
+
+	NewSignaturesEndpoint(nil, nil, extractor).Verify(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"signature": [{"code": "invalid", "message": "invalid signature length"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+const storageLogPrefix = "[Storage] "
+
+// StorageBackuper writes a consistent snapshot of node database
+type StorageBackuper interface {
+	Backup(w io.Writer) (int64, error)
+}
+
+type storageEndpoint struct {
+	storage StorageBackuper
+	timeNow func() time.Time
+}
+
+// NewStorageEndpoint creates and returns storage endpoint
+func NewStorageEndpoint(storage StorageBackuper) *storageEndpoint {
+	return &storageEndpoint{
+		storage: storage,
+		timeNow: time.Now,
+	}
+}
+
+// swagger:operation GET /storage/backup Storage storageBackup
+// ---
+// summary: Returns database backup
+// description: Returns a consistent snapshot of node database, which can be restored with 'myst storage restore' command
+// produces:
+// - application/octet-stream
+// responses:
+//   200:
+//     description: Database snapshot
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorMessageDTO"
+func (endpoint *storageEndpoint) Backup(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	fileName := fmt.Sprintf("myst-%s.db", endpoint.timeNow().UTC().Format("20060102-150405"))
+	writer := &backupWriter{resp: resp, fileName: fileName}
+
+	if _, err := endpoint.storage.Backup(writer); err != nil {
+		if !writer.started {
+			utils.SendError(resp, err, http.StatusInternalServerError)
+			return
+		}
+		log.Error(storageLogPrefix, "backup interrupted: ", err)
+	}
+}
+
+// backupWriter sends response headers with the first chunk of backup, so errors before it can still be responded
+type backupWriter struct {
+	resp     http.ResponseWriter
+	fileName string
+	started  bool
+}
+
+func (writer *backupWriter) Write(data []byte) (int, error) {
+	if !writer.started {
+		writer.started = true
+		writer.resp.Header().Set("Content-Type", "application/octet-stream")
+		writer.resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", writer.fileName))
+		writer.resp.WriteHeader(http.StatusOK)
+	}
+	return writer.resp.Write(data)
+}
+
+// AddRoutesForStorage attaches storage endpoints to router
+func AddRoutesForStorage(router *httprouter.Router, storage StorageBackuper) {
+	endpoint := NewStorageEndpoint(storage)
+	router.GET("/storage/backup", endpoint.Backup)
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+type storageBackuperFake struct {
+	data []byte
+	err  error
+}
+
+func (storage *storageBackuperFake) Backup(w io.Writer) (int64, error) {
+	if storage.err != nil {
+		return 0, storage.err
+	}
+	n, err := w.Write(storage.data)
+	return int64(n), err
+}
+
+func TestStorageBackupReturnsSnapshot(t *testing.T) {
+	endpoint := NewStorageEndpoint(&storageBackuperFake{data: []byte("snapshot")})
+	endpoint.timeNow = func() time.Time {
+		return time.Date(2019, 6, 20, 10, 30, 0, 0, time.UTC)
+	}
+	router := httprouter.New()
+	router.GET("/storage/backup", endpoint.Backup)
+
+	req, err := http.NewRequest(http.MethodGet, "/storage/backup", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/octet-stream", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="myst-20190620-103000.db"`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(t, "snapshot", resp.Body.String())
+}
+
+func TestStorageBackupReturnsError(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForStorage(router, &storageBackuperFake{err: errors.New("database closed")})
+
+	req, err := http.NewRequest(http.MethodGet, "/storage/backup", nil)
+	assert.Nil(t, err)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"message": "database closed"}`, resp.Body.String())
+}
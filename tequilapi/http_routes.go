@@ -26,11 +26,11 @@ import (
 )
 
 // NewAPIRouter returns new api router with status endpoints
-func NewAPIRouter() *httprouter.Router {
+func NewAPIRouter(brokerStatus endpoints.BrokerStatusProvider) *httprouter.Router {
 	router := httprouter.New()
 	router.HandleMethodNotAllowed = true
 
-	router.GET("/healthcheck", endpoints.HealthCheckEndpointFactory(time.Now, os.Getpid).HealthCheck)
+	router.GET("/healthcheck", endpoints.HealthCheckEndpointFactory(time.Now, os.Getpid, brokerStatus).HealthCheck)
 
 	return router
 }